@@ -0,0 +1,142 @@
+package asset
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/omustardo/gome/util/glutil"
+)
+
+// LoadCubemap loads six images from local assets into a cubemap. They're in the order of the glutil.CubemapPositiveX
+// through glutil.CubemapNegativeZ constants, which are often named posx, negx, posy, negy, posz, and negz.
+// Every image must be square and the same size. Handles jpg, png, and static gifs.
+func LoadCubemap(paths [6]string) (*glutil.Cubemap, error) {
+	var faces [6]image.Image
+	for i, path := range paths {
+		img, err := loadImage(path)
+		if err != nil {
+			return nil, err
+		}
+		faces[i] = img
+	}
+	return LoadCubemapImages(faces)
+}
+
+// LoadCubemapCross loads a single image from local assets that has every face of a cubemap laid out as an unfolded
+// cube. It can be either a horizontal cross, four faces wide and three tall:
+//        +Y
+//    -X  +Z  +X  -Z
+//        -Y
+// or a vertical cross, three faces wide and four tall, with -Z upside down:
+//        +Y
+//    -X  +Z  +X
+//        -Y
+//        -Z
+func LoadCubemapCross(path string) (*glutil.Cubemap, error) {
+	img, err := loadImage(path)
+	if err != nil {
+		return nil, err
+	}
+	faces, err := splitCross(img)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return LoadCubemapImages(faces)
+}
+
+// LoadCubemapImages loads the provided images onto the GPU as the faces of a cubemap. See LoadCubemap for their order.
+func LoadCubemapImages(faces [6]image.Image) (*glutil.Cubemap, error) {
+	var data [6][]uint8
+	size := -1
+	for i, face := range faces {
+		if face == nil {
+			return nil, fmt.Errorf("cubemap face %d is nil", i)
+		}
+		bounds := face.Bounds()
+		if bounds.Dx() != bounds.Dy() {
+			return nil, fmt.Errorf("cubemap faces must be square. face %d is %d by %d", i, bounds.Dx(), bounds.Dy())
+		}
+		if size == -1 {
+			size = bounds.Dx()
+		} else if bounds.Dx() != size {
+			return nil, fmt.Errorf("cubemap faces must be the same size. face %d is %d pixels across, want %d", i, bounds.Dx(), size)
+		}
+		// Cubemaps start from their top row, so unlike other textures the images aren't flipped.
+		nrgba := image.NewNRGBA(image.Rect(0, 0, size, size))
+		draw.Draw(nrgba, nrgba.Bounds(), face, bounds.Min, draw.Src)
+		data[i] = nrgba.Pix
+	}
+	return glutil.LoadCubemapData(size, data)
+}
+
+// crossFaces is where each face is in a horizontal and a vertical cross, in units of faces.
+var crossFaces = map[bool][6]image.Point{
+	true: {
+		glutil.CubemapPositiveX: {2, 1},
+		glutil.CubemapNegativeX: {0, 1},
+		glutil.CubemapPositiveY: {1, 0},
+		glutil.CubemapNegativeY: {1, 2},
+		glutil.CubemapPositiveZ: {1, 1},
+		glutil.CubemapNegativeZ: {3, 1},
+	},
+	false: {
+		glutil.CubemapPositiveX: {2, 1},
+		glutil.CubemapNegativeX: {0, 1},
+		glutil.CubemapPositiveY: {1, 0},
+		glutil.CubemapNegativeY: {1, 2},
+		glutil.CubemapPositiveZ: {1, 1},
+		glutil.CubemapNegativeZ: {1, 3},
+	},
+}
+
+// splitCross cuts the faces of a cubemap out of an unfolded cube. See LoadCubemapCross for the layouts.
+func splitCross(img image.Image) ([6]image.Image, error) {
+	var faces [6]image.Image
+	bounds := img.Bounds()
+	var size int
+	var horizontal bool
+	switch {
+	case bounds.Dx()*3 == bounds.Dy()*4 && bounds.Dx()%4 == 0:
+		size, horizontal = bounds.Dx()/4, true
+	case bounds.Dx()*4 == bounds.Dy()*3 && bounds.Dx()%3 == 0:
+		size, horizontal = bounds.Dx()/3, false
+	default:
+		return faces, fmt.Errorf("cubemap cross must be 4 by 3 or 3 by 4 square faces. got %d by %d pixels", bounds.Dx(), bounds.Dy())
+	}
+
+	for i, cell := range crossFaces[horizontal] {
+		face := image.NewNRGBA(image.Rect(0, 0, size, size))
+		origin := bounds.Min.Add(cell.Mul(size))
+		draw.Draw(face, face.Bounds(), img, origin, draw.Src)
+		if !horizontal && i == glutil.CubemapNegativeZ {
+			rotate180(face)
+		}
+		faces[i] = face
+	}
+	return faces, nil
+}
+
+// rotate180 turns an image upside down, in place.
+func rotate180(img *image.NRGBA) {
+	pix := img.Pix
+	for i, j := 0, len(pix)-4; i < j; i, j = i+4, j-4 {
+		for k := 0; k < 4; k++ {
+			pix[i+k], pix[j+k] = pix[j+k], pix[i+k]
+		}
+	}
+}
+
+// loadImage loads and decodes an image from local assets.
+func loadImage(path string) (image.Image, error) {
+	fileData, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewBuffer(fileData))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return img, nil
+}
@@ -0,0 +1,72 @@
+package asset
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/omustardo/gome/util/glutil"
+)
+
+// crossImage makes an unfolded cube where each face is filled with its index, and the top left pixel of each face
+// is marked so the face's orientation can be checked.
+func crossImage(size int, cells [6]image.Point) *image.NRGBA {
+	var width, height int
+	for _, c := range cells {
+		if (c.X+1)*size > width {
+			width = (c.X + 1) * size
+		}
+		if (c.Y+1)*size > height {
+			height = (c.Y + 1) * size
+		}
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for face, c := range cells {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				img.SetNRGBA(c.X*size+x, c.Y*size+y, color.NRGBA{uint8(face), 0, 0, 255})
+			}
+		}
+		img.SetNRGBA(c.X*size, c.Y*size, color.NRGBA{uint8(face), 255, 0, 255})
+	}
+	return img
+}
+
+func TestSplitCross(t *testing.T) {
+	const size = 4
+	for _, horizontal := range []bool{true, false} {
+		faces, err := splitCross(crossImage(size, crossFaces[horizontal]))
+		if err != nil {
+			t.Fatalf("horizontal=%v: %v", horizontal, err)
+		}
+		for i, face := range faces {
+			if got := face.Bounds(); got != image.Rect(0, 0, size, size) {
+				t.Fatalf("horizontal=%v: got face %d with bounds %v, want %d by %d", horizontal, i, got, size, size)
+			}
+			marked := image.Pt(0, 0)
+			if !horizontal && i == glutil.CubemapNegativeZ {
+				// It's upside down in a vertical cross, so the mark ends up in the opposite corner.
+				marked = image.Pt(size-1, size-1)
+			}
+			r, g, _, _ := face.At(marked.X, marked.Y).RGBA()
+			if int(r>>8) != i || g>>8 != 255 {
+				t.Errorf("horizontal=%v: got face %d with %v at %v, want its marked corner", horizontal, i, face.At(marked.X, marked.Y), marked)
+			}
+			if r, g, _, _ := face.At(1, 1).RGBA(); int(r>>8) != i || g != 0 {
+				t.Errorf("horizontal=%v: got face %d filled with %v, want face %d", horizontal, i, face.At(1, 1), i)
+			}
+		}
+	}
+}
+
+func TestSplitCrossRejectsBadSizes(t *testing.T) {
+	for _, r := range []image.Rectangle{
+		image.Rect(0, 0, 16, 16),
+		image.Rect(0, 0, 40, 20),
+		image.Rect(0, 0, 10, 7),
+	} {
+		if _, err := splitCross(image.NewNRGBA(r)); err == nil {
+			t.Errorf("got no error for a %v cross", r.Size())
+		}
+	}
+}
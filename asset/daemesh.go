@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/GlenKelley/go-collada"
+	"github.com/go-gl/mathgl/mgl32"
 	"github.com/goxjs/gl"
 	"github.com/omustardo/bytecoder"
 	"github.com/omustardo/gome/model/mesh"
@@ -99,5 +100,13 @@ func loadDAEData(data []byte) (mesh.Mesh, error) {
 		return mesh.Mesh{}, fmt.Errorf("gl.GetError: %v", glError)
 	}
 
-	return mesh.NewMesh(vertexVBO, gl.Buffer{}, normalVBO, gl.TRIANGLES, 3*m_TriangleCount, nil, gl.Texture{}, gl.Buffer{}), nil
+	m := mesh.NewMesh(vertexVBO, gl.Buffer{}, normalVBO, gl.TRIANGLES, 3*m_TriangleCount, nil, gl.Texture{}, gl.Buffer{})
+	// Keep the vertices in memory too, so the mesh's size is known. See mesh.Mesh.Radius.
+	meshData := &mesh.Data{}
+	for i := 0; i+2 < len(vertices); i += 3 {
+		meshData.Vertices = append(meshData.Vertices, mgl32.Vec3{vertices[i], vertices[i+1], vertices[i+2]})
+		meshData.Normals = append(meshData.Normals, mgl32.Vec3{normals[i], normals[i+1], normals[i+2]})
+	}
+	m.SetData(meshData)
+	return m, nil
 }
@@ -0,0 +1,147 @@
+package asset
+
+import (
+	"fmt"
+	"image/color"
+	"path"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/model/material"
+)
+
+// LoadMTL loads the materials in an MTL file, which usually comes along with an OBJ file. The materials are mapped
+// from their names. Textures used by the materials are loaded relative to the MTL file.
+//
+// Supported tags are:
+// Kd = Diffuse color.
+// Ks = Specular color.
+// Ke = Emissive color.
+// Ns = Specular exponent, which is the material's shininess.
+// d = Dissolve factor, which is the material's opacity. Tr is also supported, which is 1-d.
+// illum = 0 for unlit materials, 1 for no specular highlights, and 2 or more for full lighting.
+// map_Kd = Diffuse color texture map.
+// map_Ks = Specular color texture map. Its alpha is used as a gloss map.
+// map_Bump, bump, or norm = Normal map. Note that some tools use map_Bump for height maps, which aren't supported.
+// Other tags, like ambient colors and other texture maps, are ignored.
+func LoadMTL(filePath string) (map[string]*material.Material, error) {
+	data, err := LoadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(filePath)
+	materials, err := loadMTLData(data, func(texturePath string) (gl.Texture, error) {
+		return LoadTexture(path.Join(dir, texturePath))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error loading %s: %v", filePath, err)
+	}
+	return materials, nil
+}
+
+// loadMTLData parses MTL data. loadTexture is called for every texture referenced by the materials.
+func loadMTLData(data []byte, loadTexture func(path string) (gl.Texture, error)) (map[string]*material.Material, error) {
+	materials := make(map[string]*material.Material)
+	var curr *material.Material
+	for lineNum, line := range strings.Split(string(data), "\n") {
+		lineNum++ // numbering is for debug printing, and humans think of files as starting with line 1.
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		lineType, args := fields[0], fields[1:]
+		if lineType == "newmtl" {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("at line #%d, material has no name", lineNum)
+			}
+			curr = material.New()
+			materials[strings.Join(args, " ")] = curr
+			continue
+		}
+		if curr == nil {
+			return nil, fmt.Errorf("at line #%d, got %q before any material was declared with newmtl", lineNum, lineType)
+		}
+
+		switch lineType {
+		case "Kd", "Ks", "Ke":
+			c, err := parseMTLColor(args)
+			if err != nil {
+				return nil, fmt.Errorf("at line #%d, error reading %s: %v", lineNum, lineType, err)
+			}
+			switch lineType {
+			case "Kd":
+				curr.Color = c
+			case "Ks":
+				curr.Specular = nonBlack(c)
+			case "Ke":
+				curr.Emissive = nonBlack(c)
+			}
+		case "Ns", "d", "Tr":
+			var v float32
+			if _, err := fmt.Sscanf(strings.Join(args, " "), "%f", &v); err != nil {
+				return nil, fmt.Errorf("at line #%d, error reading %s: %v", lineNum, lineType, err)
+			}
+			switch lineType {
+			case "Ns":
+				curr.Shininess = v
+			case "d":
+				curr.Opacity = mgl32.Clamp(v, 0, 1)
+			case "Tr":
+				curr.Opacity = 1 - mgl32.Clamp(v, 0, 1)
+			}
+		case "illum":
+			var illum int
+			if _, err := fmt.Sscanf(strings.Join(args, " "), "%d", &illum); err != nil {
+				return nil, fmt.Errorf("at line #%d, error reading illum: %v", lineNum, err)
+			}
+			curr.Unlit = illum == 0
+			if illum == 1 {
+				curr.Shininess = 0
+			}
+		case "map_Kd", "map_Ks", "map_Bump", "bump", "norm":
+			if len(args) == 0 {
+				return nil, fmt.Errorf("at line #%d, %s has no file", lineNum, lineType)
+			}
+			// Options like "-s 1 1 1" can come before the file name, which is always last.
+			texture, err := loadTexture(args[len(args)-1])
+			if err != nil {
+				return nil, fmt.Errorf("at line #%d, error loading texture: %v", lineNum, err)
+			}
+			switch lineType {
+			case "map_Kd":
+				curr.Texture = texture
+			case "map_Ks":
+				curr.SpecularMap = texture
+			default:
+				curr.NormalMap = texture
+			}
+		default:
+			// Do nothing - ignore unsupported fields.
+		}
+	}
+	return materials, nil
+}
+
+// parseMTLColor reads an RGB color with components from 0 to 1.
+func parseMTLColor(args []string) (*color.NRGBA, error) {
+	var rgb mgl32.Vec3
+	count, err := fmt.Sscanf(strings.Join(args, " "), "%f %f %f", &rgb[0], &rgb[1], &rgb[2])
+	if err != nil {
+		return nil, err
+	}
+	if count != 3 {
+		return nil, fmt.Errorf("got %d values for a color. Expected 3", count)
+	}
+	toByte := func(v float32) uint8 { return uint8(mgl32.Clamp(v, 0, 1)*255 + 0.5) }
+	return &color.NRGBA{toByte(rgb[0]), toByte(rgb[1]), toByte(rgb[2]), 255}, nil
+}
+
+// nonBlack returns nil for black, since black specular and emissive colors are the same as none at all.
+func nonBlack(c *color.NRGBA) *color.NRGBA {
+	if c.R == 0 && c.G == 0 && c.B == 0 {
+		return nil
+	}
+	return c
+}
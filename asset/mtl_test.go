@@ -0,0 +1,95 @@
+package asset
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/goxjs/gl"
+)
+
+func TestLoadMTLData(t *testing.T) {
+	data := []byte(`
+# Two materials.
+newmtl shiny red
+Ka 0.1 0.1 0.1
+Kd 1.0 0.0 0.0
+Ks 0.5 0.5 0.5
+Ns 96
+d 0.75
+illum 2
+map_Kd -s 1 1 1 textures/red.png
+map_Ks gloss.png
+map_Bump -bm 1 normal.png
+
+newmtl glow
+Kd 0 0 0
+Ks 0 0 0
+Ke 0 1 0
+Tr 0.1
+illum 0
+`)
+	var texturePaths []string
+	materials, err := loadMTLData(data, func(path string) (gl.Texture, error) {
+		texturePaths = append(texturePaths, path)
+		return gl.Texture{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(materials) != 2 {
+		t.Fatalf("got %d materials, want 2", len(materials))
+	}
+
+	red := materials["shiny red"]
+	if red == nil {
+		t.Fatal(`material "shiny red" not found`)
+	}
+	if *red.Color != (color.NRGBA{255, 0, 0, 255}) || red.Specular == nil || *red.Specular != (color.NRGBA{128, 128, 128, 255}) {
+		t.Errorf("got color %v and specular %v, want red with gray highlights", red.Color, red.Specular)
+	}
+	if red.Shininess != 96 || red.Opacity != 0.75 || red.Unlit {
+		t.Errorf("got shininess=%v opacity=%v unlit=%v, want 96, 0.75, false", red.Shininess, red.Opacity, red.Unlit)
+	}
+	if want := []string{"textures/red.png", "gloss.png", "normal.png"}; len(texturePaths) != 3 || texturePaths[0] != want[0] || texturePaths[1] != want[1] || texturePaths[2] != want[2] {
+		t.Errorf("got textures %v, want %v", texturePaths, want)
+	}
+
+	glow := materials["glow"]
+	if glow == nil {
+		t.Fatal(`material "glow" not found`)
+	}
+	if glow.Specular != nil || glow.Emissive == nil || *glow.Emissive != (color.NRGBA{0, 255, 0, 255}) {
+		t.Errorf("got specular %v and emissive %v, want no highlights and a green glow", glow.Specular, glow.Emissive)
+	}
+	if !glow.Unlit || glow.Opacity != 0.9 {
+		t.Errorf("got unlit=%v opacity=%v, want true and 0.9", glow.Unlit, glow.Opacity)
+	}
+}
+
+func TestLoadMTLDataErrors(t *testing.T) {
+	tests := []string{
+		"Kd 1 1 1",              // No material declared.
+		"newmtl\n",              // No name.
+		"newmtl a\nKd 1 1",      // Missing a component.
+		"newmtl a\nNs shiny",    // Not a number.
+		"newmtl a\nillum maybe", // Not an integer.
+	}
+	for _, data := range tests {
+		if _, err := loadMTLData([]byte(data), nil); err == nil {
+			t.Errorf("got no error loading %q", data)
+		}
+	}
+}
+
+func TestOBJMaterial(t *testing.T) {
+	data := []byte(`
+mtllib ship parts.mtl
+v 0 0 0
+usemtl hull
+f 1 1 1
+usemtl windows
+`)
+	if lib, name := objMaterial(data); lib != "ship parts.mtl" || name != "hull" {
+		t.Errorf("got library %q and material %q, want the first of each", lib, name)
+	}
+}
@@ -3,6 +3,7 @@ package asset
 import (
 	"fmt"
 	"math"
+	pathpkg "path"
 	"strings"
 
 	"github.com/go-gl/mathgl/mgl32"
@@ -23,7 +24,7 @@ import (
 //https://github.com/sf1/go3dm
 //https://github.com/peterudkmaya11/lux/blob/master/utils/objloader.go
 //
-// TODO: Consider supporting materials (mtl tag). Info on specific MTL tags: http://nendowingsmirai.yuku.com/forum/viewtopic/id/1723#.WHmLqhsrIuU
+// Materials (mtl tag) are partially supported. See LoadMTL. Info on specific MTL tags: http://nendowingsmirai.yuku.com/forum/viewtopic/id/1723#.WHmLqhsrIuU
 // Ns = Phong specular component.
 // Kd = Diffuse color weighted by the diffuse coefficient.
 // Ka = Ambient color weighted by the ambient coefficient.
@@ -54,6 +55,11 @@ type OBJOpts struct {
 	// For example, if your OBJ file contains a unit cube with one corner at the origin and you
 	// specify Center: mgl32.Vec3{0.5, 0.5, 0.5} then the loaded mesh will be a unit cube centered at the origin.
 	Center *mgl32.Vec3
+
+	// Material loads the MTL file referenced by the OBJ file's mtllib tag, and sets the mesh's material to the first
+	// material used by its usemtl tags. Meshes only have a single material, so any others are ignored.
+	// The MTL file is loaded relative to the OBJ file. It's an error if it can't be loaded.
+	Material bool
 }
 
 // LoadOBJ creates a mesh from an obj file.
@@ -95,7 +101,43 @@ func LoadOBJ(path string, opts OBJOpts) (mesh.Mesh, error) {
 			verts[i] = verts[i].Mul(1 / maxLength)
 		}
 	}
-	return mesh.NewMeshFromArrays(verts, normals, textureCoords)
+	m, err := mesh.NewMeshFromArrays(verts, normals, textureCoords)
+	if err != nil || !opts.Material {
+		return m, err
+	}
+
+	lib, name := objMaterial(fileData)
+	if lib == "" {
+		return mesh.Mesh{}, fmt.Errorf("Error loading %s: no material library (mtllib) found", path)
+	}
+	materials, err := LoadMTL(pathpkg.Join(pathpkg.Dir(path), lib))
+	if err != nil {
+		return mesh.Mesh{}, err
+	}
+	if mat, ok := materials[name]; ok {
+		m.Material = mat
+	} else if name != "" {
+		return mesh.Mesh{}, fmt.Errorf("Error loading %s: material %q not found in %s", path, name, lib)
+	}
+	return m, nil
+}
+
+// objMaterial returns the first material library and material name referenced by OBJ data, if any.
+func objMaterial(data []byte) (lib, name string) {
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		value := strings.Join(fields[1:], " ")
+		switch {
+		case fields[0] == "mtllib" && lib == "":
+			lib = value
+		case fields[0] == "usemtl" && name == "":
+			name = value
+		}
+	}
+	return lib, name
 }
 
 func loadOBJData(data []byte) (verts, normals []mgl32.Vec3, textureCoords []mgl32.Vec2, err error) {
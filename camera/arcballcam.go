@@ -0,0 +1,248 @@
+package camera
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/input/mouse"
+	"github.com/omustardo/gome/view"
+)
+
+var _ CameraI = (*ArcballCamera)(nil)
+
+// ArcballMode determines how dragging with the left mouse button rotates an ArcballCamera.
+type ArcballMode int
+
+const (
+	// ArcballFree rotates as if dragging a ball that surrounds the pivot. Any orientation is reachable, including
+	// rolling by dragging around the edge of the screen, but it's easy to end up with the scene tilted.
+	ArcballFree ArcballMode = iota
+	// ArcballTurntable turns around the world's Up axis when dragging sideways, and tilts up and down when dragging
+	// vertically. The horizon always stays level, and the camera can't flip over the top or bottom.
+	ArcballTurntable
+)
+
+// ArcballInput is the mouse state that an ArcballCamera reacts to. Positions are window coordinates,
+// with (0,0) at the top left and Y increasing downward, like mouse.Handler.Position().
+type ArcballInput struct {
+	Position, PreviousPosition mgl32.Vec2
+	// Left, Middle, and Right are whether each button is held down. WasLeft is whether the left button was down
+	// in the previous update, to detect clicks.
+	Left, Middle, Right, WasLeft bool
+	// Scroll is the amount scrolled since the previous update. Positive values dolly toward the pivot.
+	Scroll float32
+}
+
+// ArcballCamera is a mouse controlled camera for inspecting models. It always looks at a pivot point:
+//   * Dragging with the left mouse button rotates around the pivot. See ArcballMode.
+//   * Dragging with the middle or right mouse button pans, moving the pivot parallel to the screen.
+//   * Scrolling dollies toward or away from the pivot. If ZoomToCursor is set, the point under the cursor stays put.
+//   * Double clicking moves the pivot to the clicked point.
+//
+// It assumes a perspective projection, since that's needed for dollying to have any visible effect.
+// Create one using NewArcballCamera unless you know what you're doing.
+type ArcballCamera struct {
+	Camera
+
+	// Pivot is the point the camera looks at and rotates around.
+	Pivot mgl32.Vec3
+	// Distance is how far the camera is from the pivot. It's limited to the range [MinDistance, MaxDistance].
+	Distance, MinDistance, MaxDistance float32
+
+	// Mode determines how the camera rotates.
+	Mode ArcballMode
+	// TurntableSpeed is how many radians the camera turns per pixel dragged in ArcballTurntable mode.
+	TurntableSpeed float32
+	// DollySpeed is the fraction of the distance to the pivot that is covered per scroll tick.
+	DollySpeed float32
+	// ZoomToCursor moves the pivot toward the cursor while dollying, so the point under the cursor stays under it.
+	ZoomToCursor bool
+	// DoubleClickTime is the longest time between two left clicks for them to count as a double click.
+	DoubleClickTime time.Duration
+
+	// GetInput returns the current mouse state. NewArcballCamera sets it to read from mouse.Handler.
+	GetInput func() ArcballInput
+	// GetWindowSize returns the size of the window, in the same units as the mouse positions.
+	// NewArcballCamera sets it to use view.Window.
+	GetWindowSize func() (width, height float32)
+	// PickPoint is optional. On a double click, it's given a ray from the camera through the cursor and should return
+	// the first point where the ray hits the scene. If it's nil or returns false, the point under the cursor at the
+	// same depth as the current pivot is used instead.
+	PickPoint func(origin, direction mgl32.Vec3) (mgl32.Vec3, bool)
+
+	// elapsed is the game time that's passed, for detecting double clicks.
+	elapsed time.Duration
+	// lastClick is when and where the left button was last pressed.
+	lastClickTime     time.Duration
+	lastClickPosition mgl32.Vec2
+	hasClicked        bool
+}
+
+// NewArcballCamera creates an ArcballCamera that starts distance units in front of the pivot, looking down
+// the negative Z axis.
+func NewArcballCamera(pivot mgl32.Vec3, distance float32) *ArcballCamera {
+	c := &ArcballCamera{
+		Camera:          *NewCamera(),
+		Pivot:           pivot,
+		Distance:        distance,
+		MinDistance:     0.01,
+		MaxDistance:     5000,
+		Mode:            ArcballTurntable,
+		TurntableSpeed:  0.01,
+		DollySpeed:      0.1,
+		ZoomToCursor:    true,
+		DoubleClickTime: 300 * time.Millisecond,
+		GetInput: func() ArcballInput {
+			return ArcballInput{
+				Position:         mouse.Handler.Position(),
+				PreviousPosition: mouse.Handler.PreviousPosition(),
+				Left:             mouse.Handler.LeftPressed(),
+				Middle:           mouse.Handler.MiddlePressed(),
+				Right:            mouse.Handler.RightPressed(),
+				WasLeft:          mouse.Handler.WasLeftPressed(),
+				Scroll:           mouse.Handler.Scroll().Y(),
+			}
+		},
+		GetWindowSize: func() (float32, float32) {
+			w, h := view.Window.GetSize()
+			return float32(w), float32(h)
+		},
+	}
+	c.updatePosition()
+	return c
+}
+
+func (c *ArcballCamera) Update(delta time.Duration) {
+	c.Camera.Update(delta)
+	c.elapsed += delta
+	in := c.GetInput()
+	width, height := c.GetWindowSize()
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	if in.Left && !in.WasLeft {
+		c.click(in.Position, width, height)
+	}
+	if drag := in.Position.Sub(in.PreviousPosition); drag != (mgl32.Vec2{}) {
+		switch {
+		case in.Left:
+			c.rotate(in.PreviousPosition, in.Position, width, height)
+		case in.Middle || in.Right:
+			c.pan(drag, height)
+		}
+	}
+	if in.Scroll != 0 {
+		c.dolly(in.Scroll, in.Position, width, height)
+	}
+	c.updatePosition()
+}
+
+// click handles a left mouse button press, re-centering the camera on a double click.
+func (c *ArcballCamera) click(pos mgl32.Vec2, width, height float32) {
+	// Allow the cursor to move a few pixels between clicks.
+	const slop = 5
+	if c.hasClicked && c.elapsed-c.lastClickTime <= c.DoubleClickTime && pos.Sub(c.lastClickPosition).Len() <= slop {
+		c.hasClicked = false
+		c.Pivot = c.pick(pos, width, height)
+		return
+	}
+	c.hasClicked = true
+	c.lastClickTime = c.elapsed
+	c.lastClickPosition = pos
+}
+
+// pick returns the point in the scene under the provided window position.
+func (c *ArcballCamera) pick(pos mgl32.Vec2, width, height float32) mgl32.Vec3 {
+	onPivotPlane := c.pointOnPivotPlane(pos, width, height)
+	if c.PickPoint != nil {
+		if p, ok := c.PickPoint(c.Position, onPivotPlane.Sub(c.Position).Normalize()); ok {
+			return p
+		}
+	}
+	return onPivotPlane
+}
+
+// rotate turns the camera around the pivot based on the cursor moving from prev to curr.
+func (c *ArcballCamera) rotate(prev, curr mgl32.Vec2, width, height float32) {
+	switch c.Mode {
+	case ArcballTurntable:
+		drag := curr.Sub(prev).Mul(c.TurntableSpeed)
+		// Dragging right should turn the scene right, which means moving the camera left around it.
+		c.ModifyRotationGlobalQ(mgl32.QuatRotate(-drag.X(), entity.Up))
+		// Dragging down should tilt the top of the scene toward the viewer, so the camera moves up and looks down.
+		// Don't allow tilting past straight up or down, or the controls would be reversed.
+		const maxTilt = math.Pi/2 - 0.01
+		tilt := float32(math.Asin(float64(mgl32.Clamp(-c.Forward().Dot(entity.Up), -1, 1))))
+		change := mgl32.Clamp(tilt+drag.Y(), -maxTilt, maxTilt) - tilt
+		c.ModifyRotationLocalQ(mgl32.QuatRotate(-change, entity.Right))
+	default:
+		a, b := arcballVector(prev, width, height), arcballVector(curr, width, height)
+		axis := a.Cross(b)
+		if axis.Len() < 1e-6 {
+			return
+		}
+		angle := float32(math.Acos(float64(mgl32.Clamp(a.Dot(b), -1, 1))))
+		// The scene should rotate with the cursor, so the camera rotates the other way. The axis is in camera space.
+		worldAxis := c.Rotation.Rotate(axis.Normalize())
+		c.ModifyRotationGlobalQ(mgl32.QuatRotate(-angle, worldAxis))
+	}
+}
+
+// arcballVector maps a window position onto a unit sphere that fills the smaller dimension of the window,
+// in camera space. Positions outside of the sphere are moved to its edge.
+func arcballVector(pos mgl32.Vec2, width, height float32) mgl32.Vec3 {
+	size := width
+	if height < size {
+		size = height
+	}
+	p := mgl32.Vec3{(2*pos.X() - width) / size, (height - 2*pos.Y()) / size, 0}
+	if lenSq := p.X()*p.X() + p.Y()*p.Y(); lenSq <= 1 {
+		p[2] = float32(math.Sqrt(float64(1 - lenSq)))
+		return p
+	}
+	return p.Normalize()
+}
+
+// pan moves the pivot parallel to the screen so the scene follows the cursor.
+func (c *ArcballCamera) pan(drag mgl32.Vec2, height float32) {
+	perPixel := c.viewHeight() / height
+	// Screen Y is down, but world movement should follow the cursor.
+	move := c.Right().Mul(-drag.X() * perPixel).Add(c.Up().Mul(drag.Y() * perPixel))
+	c.Pivot = c.Pivot.Add(move)
+}
+
+// dolly moves toward or away from the pivot.
+func (c *ArcballCamera) dolly(scroll float32, pos mgl32.Vec2, width, height float32) {
+	target := c.Distance * float32(math.Pow(float64(1-c.DollySpeed), float64(scroll)))
+	target = mgl32.Clamp(target, c.MinDistance, c.MaxDistance)
+	if c.ZoomToCursor {
+		// Moving the pivot toward the point under the cursor by the same fraction as the distance shrinks keeps that
+		// point in the same place on screen.
+		cursor := c.pointOnPivotPlane(pos, width, height)
+		c.Pivot = c.Pivot.Add(cursor.Sub(c.Pivot).Mul(1 - target/c.Distance))
+	}
+	c.Distance = target
+}
+
+// viewHeight returns the height of the visible area at the pivot's depth, in world units.
+func (c *ArcballCamera) viewHeight() float32 {
+	return 2 * c.Distance * float32(math.Tan(float64(c.FOV/2)))
+}
+
+// pointOnPivotPlane returns the point under a window position, on the plane through the pivot that faces the camera.
+func (c *ArcballCamera) pointOnPivotPlane(pos mgl32.Vec2, width, height float32) mgl32.Vec3 {
+	viewHeight := c.viewHeight()
+	viewWidth := viewHeight * width / height
+	x := (pos.X()/width - 0.5) * viewWidth
+	y := (0.5 - pos.Y()/height) * viewHeight
+	return c.Pivot.Add(c.Right().Mul(x)).Add(c.Up().Mul(y))
+}
+
+func (c *ArcballCamera) updatePosition() {
+	c.Distance = mgl32.Clamp(c.Distance, c.MinDistance, c.MaxDistance)
+	c.Rotation = c.Rotation.Normalize()
+	c.Position = c.Pivot.Add(c.Rotation.Rotate(mgl32.Vec3{0, 0, c.Distance}))
+}
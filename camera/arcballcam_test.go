@@ -0,0 +1,172 @@
+package camera
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+)
+
+// fakeMouse provides input to an ArcballCamera. Each call to move or click is one frame.
+type fakeMouse struct {
+	c     *ArcballCamera
+	input ArcballInput
+}
+
+func newTestArcballCamera() (*ArcballCamera, *fakeMouse) {
+	c := NewArcballCamera(mgl32.Vec3{}, 100)
+	center := mgl32.Vec2{400, 300}
+	m := &fakeMouse{c: c, input: ArcballInput{Position: center, PreviousPosition: center}}
+	c.GetInput = func() ArcballInput { return m.input }
+	c.GetWindowSize = func() (float32, float32) { return 800, 600 }
+	return c, m
+}
+
+func (m *fakeMouse) frame(delta time.Duration) {
+	m.c.Update(delta)
+	m.input.PreviousPosition = m.input.Position
+	m.input.WasLeft = m.input.Left
+	m.input.Scroll = 0
+}
+
+// drag moves the cursor by offset while holding the left button, or the right button if pan is true.
+func (m *fakeMouse) drag(offset mgl32.Vec2, pan bool) {
+	m.input.Left, m.input.Right = !pan, pan
+	m.frame(time.Second / 60)
+	m.input.Position = m.input.Position.Add(offset)
+	m.frame(time.Second / 60)
+	m.input.Left, m.input.Right = false, false
+	m.frame(time.Second / 60)
+}
+
+func (m *fakeMouse) click(after time.Duration) {
+	m.input.Left = true
+	m.frame(after)
+	m.input.Left = false
+	m.frame(time.Millisecond)
+}
+
+// checkLooksAtPivot verifies that the camera is facing the pivot from the expected distance.
+func checkLooksAtPivot(t *testing.T, c *ArcballCamera) {
+	t.Helper()
+	toPivot := c.Pivot.Sub(c.Position)
+	if dist := toPivot.Len(); dist < c.Distance-1e-3 || dist > c.Distance+1e-3 {
+		t.Errorf("camera is %v from the pivot, want %v", dist, c.Distance)
+	}
+	if got, want := c.Forward(), toPivot.Normalize(); got.Sub(want).Len() > 1e-4 {
+		t.Errorf("got forward %v, want %v toward the pivot", got, want)
+	}
+}
+
+func TestArcballCameraRotate(t *testing.T) {
+	for _, mode := range []ArcballMode{ArcballTurntable, ArcballFree} {
+		c, m := newTestArcballCamera()
+		c.Mode = mode
+
+		// Dragging right turns the scene right, so the camera swings around to the left.
+		m.drag(mgl32.Vec2{100, 0}, false)
+		if c.Position.X() >= -1 {
+			t.Errorf("mode %v: got position %v after dragging right, want the camera to move toward -X", mode, c.Position)
+		}
+		if c.Position.Y() > 1e-3 || c.Position.Y() < -1e-3 {
+			t.Errorf("mode %v: got position %v after dragging horizontally, want no vertical movement", mode, c.Position)
+		}
+		checkLooksAtPivot(t, c)
+
+		// Dragging down shows the top of the scene.
+		m.drag(mgl32.Vec2{0, 100}, false)
+		if c.Position.Y() <= 1 {
+			t.Errorf("mode %v: got position %v after dragging down, want the camera to move up", mode, c.Position)
+		}
+		checkLooksAtPivot(t, c)
+	}
+}
+
+func TestArcballCameraTurntableDoesNotFlip(t *testing.T) {
+	c, m := newTestArcballCamera()
+	for i := 0; i < 10; i++ {
+		m.drag(mgl32.Vec2{0, 100}, false)
+	}
+	if up := c.Up(); up.Dot(entity.Up) < 0 {
+		t.Errorf("got up %v, want the camera to stay upright", up)
+	}
+	if c.Up().Dot(entity.Up) > 0.2 {
+		t.Errorf("got up %v, want the camera to be looking almost straight down", c.Up())
+	}
+	checkLooksAtPivot(t, c)
+}
+
+func TestArcballCameraPan(t *testing.T) {
+	c, m := newTestArcballCamera()
+	start, end := mgl32.Vec2{200, 150}, mgl32.Vec2{350, 100}
+	m.input.Position = start
+	m.frame(time.Second / 60)
+	grabbed := c.pointOnPivotPlane(start, 800, 600)
+
+	m.drag(end.Sub(start), true)
+	// The point that was under the cursor should still be under it.
+	if got := c.pointOnPivotPlane(end, 800, 600); got.Sub(grabbed).Len() > 1e-3 {
+		t.Errorf("got %v under the cursor after panning, want %v", got, grabbed)
+	}
+	if c.Pivot.Z() != 0 {
+		t.Errorf("got pivot %v, want it to stay in the plane facing the camera", c.Pivot)
+	}
+	checkLooksAtPivot(t, c)
+}
+
+func TestArcballCameraDolly(t *testing.T) {
+	c, m := newTestArcballCamera()
+	cursor := mgl32.Vec2{600, 200}
+	m.input.Position = cursor
+	m.frame(time.Second / 60)
+	under := c.pointOnPivotPlane(cursor, 800, 600)
+
+	m.input.Scroll = 2
+	m.frame(time.Second / 60)
+	if got, want := c.Distance, float32(100*0.9*0.9); got < want-1e-3 || got > want+1e-3 {
+		t.Errorf("got distance %v, want %v", got, want)
+	}
+	if got := c.pointOnPivotPlane(cursor, 800, 600); got.Sub(under).Len() > 1e-3 {
+		t.Errorf("got %v under the cursor after dollying, want %v", got, under)
+	}
+	checkLooksAtPivot(t, c)
+
+	// Distance is limited.
+	m.input.Scroll = -100
+	m.frame(time.Second / 60)
+	if c.Distance != c.MaxDistance {
+		t.Errorf("got distance %v, want it limited to %v", c.Distance, c.MaxDistance)
+	}
+}
+
+func TestArcballCameraDoubleClick(t *testing.T) {
+	c, m := newTestArcballCamera()
+	m.input.Position = mgl32.Vec2{600, 150}
+	m.frame(time.Second / 60)
+	want := c.pointOnPivotPlane(m.input.Position, 800, 600)
+
+	// Clicks that are too far apart don't count.
+	m.click(time.Second / 60)
+	m.click(time.Second)
+	if c.Pivot != (mgl32.Vec3{}) {
+		t.Errorf("got pivot %v after slow clicks, want it unchanged", c.Pivot)
+	}
+
+	m.click(100 * time.Millisecond)
+	if got := c.Pivot; got.Sub(want).Len() > 1e-3 {
+		t.Errorf("got pivot %v after double clicking, want %v", got, want)
+	}
+	checkLooksAtPivot(t, c)
+
+	// PickPoint takes priority when it finds something.
+	picked := mgl32.Vec3{1, 2, 3}
+	c.PickPoint = func(origin, direction mgl32.Vec3) (mgl32.Vec3, bool) {
+		return picked, true
+	}
+	m.click(time.Second)
+	m.click(100 * time.Millisecond)
+	if c.Pivot != picked {
+		t.Errorf("got pivot %v, want the picked point %v", c.Pivot, picked)
+	}
+}
@@ -0,0 +1,293 @@
+package camera
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/view"
+)
+
+var _ CameraI = (*BoundedCamera)(nil)
+
+// Bounds is an area of the XY plane that a BoundedCamera's view is kept within.
+type Bounds interface {
+	// Constrain returns the smallest translation that moves a view completely within the bounds. The view is the
+	// visible part of the XY plane, given as the corners of a convex polygon. If the view is too large to fit,
+	// it's centered in the bounds instead.
+	Constrain(view []mgl32.Vec2) mgl32.Vec2
+}
+
+// RectBounds is an axis aligned rectangle from Min to Max.
+// Each axis is handled separately, so a view that's wider than the rectangle is centered horizontally but can still
+// move up and down if it isn't taller than the rectangle.
+type RectBounds struct {
+	Min, Max mgl32.Vec2
+}
+
+func (b RectBounds) Constrain(view []mgl32.Vec2) mgl32.Vec2 {
+	if len(view) == 0 {
+		return mgl32.Vec2{}
+	}
+	min, max := boundingBox(view)
+	return mgl32.Vec2{
+		constrainAxis(min.X(), max.X(), b.Min.X(), b.Max.X()),
+		constrainAxis(min.Y(), max.Y(), b.Min.Y(), b.Max.Y()),
+	}
+}
+
+// constrainAxis returns how far to move the range [min, max] so it's within [boundsMin, boundsMax],
+// or so it's centered on it if the range is too large to fit.
+func constrainAxis(min, max, boundsMin, boundsMax float32) float32 {
+	switch {
+	case max-min >= boundsMax-boundsMin:
+		return (boundsMin+boundsMax)/2 - (min+max)/2
+	case min < boundsMin:
+		return boundsMin - min
+	case max > boundsMax:
+		return boundsMax - max
+	}
+	return 0
+}
+
+// PolygonBounds is a convex polygon, with vertices listed in either clockwise or counterclockwise order.
+// Concave polygons aren't supported.
+// If the view is too large to fit, it's treated as if it were shrunk around its center until it fits, which centers it
+// in the narrowest part of the polygon.
+type PolygonBounds []mgl32.Vec2
+
+func (b PolygonBounds) Constrain(view []mgl32.Vec2) mgl32.Vec2 {
+	if len(b) < 3 || len(view) == 0 {
+		return mgl32.Vec2{}
+	}
+	if move, ok := b.fit(view); ok {
+		return move
+	}
+	// The view doesn't fit. A single point always fits, so search for the largest scale of the view that still does.
+	viewMin, viewMax := boundingBox(view)
+	center := viewMin.Add(viewMax).Mul(0.5)
+	scaled := make([]mgl32.Vec2, len(view))
+	var best mgl32.Vec2
+	low, high := float32(0), float32(1)
+	for i := 0; i < 20; i++ {
+		s := (low + high) / 2
+		for j, p := range view {
+			scaled[j] = center.Add(p.Sub(center).Mul(s))
+		}
+		if move, ok := b.fit(scaled); ok {
+			best, low = move, s
+		} else {
+			high = s
+		}
+	}
+	if low == 0 {
+		// Even a tiny view doesn't fit, so just keep its center within the polygon.
+		best, _ = b.fit([]mgl32.Vec2{center})
+	}
+	return best
+}
+
+// fit returns the smallest translation that moves the view within the polygon, or false if there isn't one.
+func (b PolygonBounds) fit(view []mgl32.Vec2) (mgl32.Vec2, bool) {
+	// Flip edge normals for clockwise polygons so they always point inward.
+	var area float32
+	for i, p := range b {
+		q := b[(i+1)%len(b)]
+		area += p.X()*q.Y() - q.X()*p.Y()
+	}
+	if area == 0 {
+		return mgl32.Vec2{}, false
+	}
+	sign := float32(1)
+	if area < 0 {
+		sign = -1
+	}
+
+	// Each edge limits the translation d to a half plane. Every point q in the view must be on the inner side of the edge,
+	// so normal·(q+d-a) >= 0 for a point a on the edge, which is normal·d >= normal·a - min(normal·q).
+	normals := make([]mgl32.Vec2, 0, len(b))
+	limits := make([]float32, 0, len(b))
+	for i, a := range b {
+		edge := b[(i+1)%len(b)].Sub(a)
+		if edge.Len() == 0 {
+			continue
+		}
+		normal := mgl32.Vec2{-edge.Y(), edge.X()}.Normalize().Mul(sign)
+		nearest := float32(math.Inf(1))
+		for _, q := range view {
+			if d := normal.Dot(q); d < nearest {
+				nearest = d
+			}
+		}
+		normals = append(normals, normal)
+		limits = append(limits, normal.Dot(a)-nearest)
+	}
+	return closestInHalfPlanes(normals, limits)
+}
+
+// closestInHalfPlanes returns the point closest to the origin where normals[i]·p >= limits[i] for every i.
+// The normals must be unit vectors. It returns false if no point satisfies all of the limits.
+func closestInHalfPlanes(normals []mgl32.Vec2, limits []float32) (mgl32.Vec2, bool) {
+	tolerance := float32(1e-4)
+	for _, l := range limits {
+		tolerance = float32(math.Max(float64(tolerance), 1e-4*math.Abs(float64(l))))
+	}
+	inside := func(p mgl32.Vec2) bool {
+		for i, n := range normals {
+			if n.Dot(p) < limits[i]-tolerance {
+				return false
+			}
+		}
+		return true
+	}
+	if inside(mgl32.Vec2{}) {
+		return mgl32.Vec2{}, true
+	}
+
+	// The closest point is either the closest point on one of the edges of the region, or one of its corners.
+	var best mgl32.Vec2
+	found := false
+	consider := func(p mgl32.Vec2) {
+		if inside(p) && (!found || p.Len() < best.Len()) {
+			best, found = p, true
+		}
+	}
+	for i, n := range normals {
+		consider(n.Mul(limits[i]))
+	}
+	for i, a := range normals {
+		for j := i + 1; j < len(normals); j++ {
+			b := normals[j]
+			det := a.X()*b.Y() - a.Y()*b.X()
+			if math.Abs(float64(det)) < 1e-6 {
+				continue
+			}
+			consider(mgl32.Vec2{
+				(limits[i]*b.Y() - limits[j]*a.Y()) / det,
+				(a.X()*limits[j] - b.X()*limits[i]) / det,
+			})
+		}
+	}
+	return best, found
+}
+
+// boundingBox returns the smallest axis aligned rectangle that contains all of the points.
+func boundingBox(points []mgl32.Vec2) (min, max mgl32.Vec2) {
+	min, max = points[0], points[0]
+	for _, p := range points[1:] {
+		min = mgl32.Vec2{float32(math.Min(float64(min.X()), float64(p.X()))), float32(math.Min(float64(min.Y()), float64(p.Y())))}
+		max = mgl32.Vec2{float32(math.Max(float64(max.X()), float64(p.X()))), float32(math.Max(float64(max.Y()), float64(p.Y())))}
+	}
+	return min, max
+}
+
+// BoundedCamera wraps another camera and moves it just enough to keep its view within Bounds. This stops a camera
+// that follows a player from showing the empty space past the edges of a level. The view is measured where it meets
+// the XY plane at height Z, using the wrapped camera's own projection, so it accounts for the size of the window
+// and for zoom.
+//
+// The wrapped camera isn't modified, so it can be any camera, including a TargetCamera or TrailingCamera. When it
+// moves back within the bounds, the view smoothly follows it again. To shake a bounded camera without the shake
+// being clamped, wrap the BoundedCamera in an effect.Camera rather than the other way around.
+//
+// The view must face the plane, as it does in a typical 2D game. If part of the view never meets the plane, like when
+// a tilted perspective camera sees the horizon, the camera isn't constrained.
+// Create one using NewBoundedCamera unless you know what you're doing.
+type BoundedCamera struct {
+	// Base is the wrapped camera. It's updated along with the BoundedCamera.
+	Base CameraI
+	// Bounds is the area the view is kept within. If it's nil, the view isn't constrained.
+	Bounds Bounds
+	// Projection is the projection used for rendering, which determines how much of the plane is visible.
+	Projection ProjectionType
+	// Z is the height of the XY plane that Bounds is on.
+	Z float32
+	// GetWindowSize returns the width and height that are passed to the projection functions when rendering.
+	// NewBoundedCamera sets it to use view.Window. When rendering to a viewport, it should return the viewport's size.
+	GetWindowSize func() (width, height float32)
+
+	// offset is how far the camera was moved to stay within bounds during the last Update.
+	offset mgl32.Vec3
+}
+
+// NewBoundedCamera wraps a camera so its view stays within bounds on the Z=0 plane.
+func NewBoundedCamera(base CameraI, bounds Bounds, projection ProjectionType) *BoundedCamera {
+	return &BoundedCamera{
+		Base:       base,
+		Bounds:     bounds,
+		Projection: projection,
+		GetWindowSize: func() (float32, float32) {
+			w, h := view.Window.GetSize()
+			return float32(w), float32(h)
+		},
+	}
+}
+
+// Update updates the wrapped camera, and then moves the view back within bounds.
+func (c *BoundedCamera) Update(delta time.Duration) {
+	c.Base.Update(delta)
+	c.offset = c.constrain()
+}
+
+// Offset returns how far the view was moved from the wrapped camera's position to keep it within bounds.
+func (c *BoundedCamera) Offset() mgl32.Vec3 {
+	return c.offset
+}
+
+// ModelView returns the wrapped camera's ModelView matrix, moved to keep the view within bounds.
+func (c *BoundedCamera) ModelView() mgl32.Mat4 {
+	// Moving the camera by the offset is the same as moving everything else by the opposite amount.
+	return c.Base.ModelView().Mul4(mgl32.Translate3D(c.offset.Mul(-1).Elem()))
+}
+
+func (c *BoundedCamera) ProjectionOrthographic(width, height float32) mgl32.Mat4 {
+	return c.Base.ProjectionOrthographic(width, height)
+}
+
+func (c *BoundedCamera) ProjectionPerspective(width, height float32) mgl32.Mat4 {
+	return c.Base.ProjectionPerspective(width, height)
+}
+
+// GetPosition returns the wrapped camera's position, moved to keep the view within bounds.
+func (c *BoundedCamera) GetPosition() mgl32.Vec3 {
+	return c.Base.GetPosition().Add(c.offset)
+}
+
+// constrain returns how far the wrapped camera needs to move to keep its view within bounds.
+func (c *BoundedCamera) constrain() mgl32.Vec3 {
+	if c.Bounds == nil {
+		return mgl32.Vec3{}
+	}
+	width, height := c.GetWindowSize()
+	if width <= 0 || height <= 0 {
+		return mgl32.Vec3{}
+	}
+	mvp := Projection(c.Base, c.Projection, width, height).Mul4(c.Base.ModelView())
+	if mvp.Det() == 0 {
+		return mgl32.Vec3{}
+	}
+	inv := mvp.Inv()
+	unproject := func(x, y, z float32) mgl32.Vec3 {
+		p := inv.Mul4x1(mgl32.Vec4{x, y, z, 1})
+		return p.Vec3().Mul(1 / p.W())
+	}
+
+	// Find where the rays through the corners of the screen meet the plane.
+	view := make([]mgl32.Vec2, 0, 4)
+	for _, corner := range []mgl32.Vec2{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+		near := unproject(corner.X(), corner.Y(), -1)
+		dir := unproject(corner.X(), corner.Y(), 1).Sub(near)
+		if math.Abs(float64(dir.Z())) < 1e-6 {
+			return mgl32.Vec3{}
+		}
+		t := (c.Z - near.Z()) / dir.Z()
+		if t < 0 {
+			return mgl32.Vec3{}
+		}
+		p := near.Add(dir.Mul(t))
+		view = append(view, mgl32.Vec2{p.X(), p.Y()})
+	}
+	// Moving the camera along the plane moves its view by the same amount, so the translation can be used directly.
+	move := c.Bounds.Constrain(view)
+	return mgl32.Vec3{move.X(), move.Y(), 0}
+}
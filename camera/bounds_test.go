@@ -0,0 +1,129 @@
+package camera
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+)
+
+// square returns the corners of a square view centered at (x, y).
+func square(x, y, halfSize float32) []mgl32.Vec2 {
+	return []mgl32.Vec2{
+		{x - halfSize, y - halfSize},
+		{x + halfSize, y - halfSize},
+		{x + halfSize, y + halfSize},
+		{x - halfSize, y + halfSize},
+	}
+}
+
+func TestRectBounds(t *testing.T) {
+	bounds := RectBounds{Min: mgl32.Vec2{-100, -50}, Max: mgl32.Vec2{100, 50}}
+	tests := []struct {
+		name string
+		view []mgl32.Vec2
+		want mgl32.Vec2
+	}{
+		{"inside", square(0, 0, 10), mgl32.Vec2{}},
+		{"past the right edge", square(95, 0, 10), mgl32.Vec2{-5, 0}},
+		{"past the bottom left corner", square(-100, -50, 10), mgl32.Vec2{10, 10}},
+		// The view is too tall to fit, so it's centered vertically but still kept in bounds horizontally.
+		{"taller than the bounds", square(-95, 20, 60), mgl32.Vec2{55, -20}},
+	}
+	for _, tt := range tests {
+		if got := bounds.Constrain(tt.view); got.Sub(tt.want).Len() > 1e-4 {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPolygonBounds(t *testing.T) {
+	diamond := PolygonBounds{{10, 0}, {0, 10}, {-10, 0}, {0, -10}}
+	reversed := PolygonBounds{{0, -10}, {-10, 0}, {0, 10}, {10, 0}}
+	tests := []struct {
+		name string
+		view []mgl32.Vec2
+		want mgl32.Vec2
+	}{
+		{"inside", square(0, 0, 1), mgl32.Vec2{}},
+		// The right corners of the view are past the diamond's edges, so it needs to move left until they touch them.
+		{"past the right corner", square(9, 0, 1), mgl32.Vec2{-1, 0}},
+		// Only the top right corner of the view is outside, so it moves straight toward the edge it crossed.
+		{"past the top right edge", square(4, 6, 1), mgl32.Vec2{-1, -1}},
+		// The view is larger than the diamond, so it's centered in it.
+		{"larger than the bounds", square(3, 0, 20), mgl32.Vec2{-3, 0}},
+	}
+	for _, tt := range tests {
+		for _, bounds := range []PolygonBounds{diamond, reversed} {
+			if got := bounds.Constrain(tt.view); got.Sub(tt.want).Len() > 1e-3 {
+				t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestBoundedCameraOrthographic(t *testing.T) {
+	target := entity.Default()
+	base := NewTargetCamera(&target, mgl32.Vec3{0, 0, 500})
+	// At 200% zoom, the 200x100 window shows 100x50 units of the world.
+	base.Zoomer = fixedZoom(2)
+	c := NewBoundedCamera(base, RectBounds{Min: mgl32.Vec2{-500, -300}, Max: mgl32.Vec2{500, 300}}, Orthographic)
+	c.GetWindowSize = func() (float32, float32) { return 200, 100 }
+	size := WindowSize{Width: 200, Height: 100}
+
+	target.Position = mgl32.Vec3{480, 0, 0}
+	c.Update(time.Second)
+	// Zooming also moves the target camera closer, which doesn't affect what's visible in an orthographic projection.
+	if want := (mgl32.Vec3{450, 0, 250}); c.GetPosition().Sub(want).Len() > 1e-3 {
+		t.Errorf("got position %v, want %v", c.GetPosition(), want)
+	}
+	// The edge of the level is exactly at the edge of the window.
+	if got := Project(c, Orthographic, mgl32.Vec3{500, 0, 0}, size).Position; got.Sub(mgl32.Vec2{200, 50}).Len() > 1e-2 {
+		t.Errorf("got the edge of the level at %v, want it at the right edge of the window", got)
+	}
+	if base.GetPosition().X() != 480 {
+		t.Errorf("the wrapped camera moved to %v, want it left alone", base.GetPosition())
+	}
+
+	// A level that's narrower than the view is centered.
+	c.Bounds = RectBounds{Min: mgl32.Vec2{-20, -300}, Max: mgl32.Vec2{20, 300}}
+	c.Update(time.Second)
+	if want := (mgl32.Vec3{0, 0, 250}); c.GetPosition().Sub(want).Len() > 1e-3 {
+		t.Errorf("got position %v in a narrow level, want %v", c.GetPosition(), want)
+	}
+}
+
+func TestBoundedCameraPerspective(t *testing.T) {
+	base := NewCamera()
+	base.Position = mgl32.Vec3{90, 0, 100}
+	c := NewBoundedCamera(base, RectBounds{Min: mgl32.Vec2{-100, -100}, Max: mgl32.Vec2{100, 100}}, Perspective)
+	c.GetWindowSize = func() (float32, float32) { return 100, 100 }
+	c.Update(time.Second)
+
+	// With a 45 degree field of view, the camera sees 100*tan(22.5°) units to each side of it on the plane.
+	halfWidth := 100 * float32(math.Tan(math.Pi/8))
+	if got, want := c.GetPosition().X(), 100-halfWidth; math.Abs(float64(got-want)) > 1e-2 {
+		t.Errorf("got x=%v, want %v", got, want)
+	}
+}
+
+func TestBoundedCameraWithTrailingCamera(t *testing.T) {
+	target := entity.Default()
+	base := NewTrailingCamera(&target, mgl32.Vec3{0, 0, 500}, 100*time.Millisecond)
+	c := NewBoundedCamera(base, RectBounds{Min: mgl32.Vec2{-500, -500}, Max: mgl32.Vec2{500, 500}}, Orthographic)
+	c.GetWindowSize = func() (float32, float32) { return 200, 200 }
+
+	// However far the trailing camera lags behind or catches up, the view stays within bounds.
+	target.Position = mgl32.Vec3{1000, 0, 0}
+	for i := 0; i < 60; i++ {
+		c.Update(time.Second / 60)
+		if x := c.GetPosition().X(); x > 400+1e-3 {
+			t.Fatalf("frame %d: got x=%v, want at most 400", i, x)
+		}
+	}
+	if got := c.GetPosition().X(); math.Abs(float64(got-400)) > 1e-3 {
+		t.Errorf("got x=%v after catching up, want 400", got)
+	}
+}
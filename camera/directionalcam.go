@@ -0,0 +1,132 @@
+package camera
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/camera/zoom"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/util"
+)
+
+var _ CameraI = (*DirectionalCamera)(nil)
+
+// DirectionalMode determines how a DirectionalCamera orients itself based on its target.
+type DirectionalMode int
+
+const (
+	// Directional2D keeps the camera looking straight down the negative Z axis, and rolls it so the direction the
+	// target faces is always up on the screen. This is meant for top down games on the XY plane.
+	Directional2D DirectionalMode = iota
+	// Directional3D matches the target's rotation, so the camera faces the same way as the target and the target's
+	// Up is up on the screen. This is a typical chase camera.
+	Directional3D
+)
+
+// DirectionalCamera is a camera that follows both the position and the orientation of its target.
+// Rather than snapping to the target's orientation, it smoothly turns toward it at a rate set by RotationRate.
+// Create one using NewDirectionalCamera unless you know what you're doing.
+type DirectionalCamera struct {
+	Camera
+
+	// Target is the entity that the camera follows. Unlike TargetCamera, its rotation matters so it must be a full Entity.
+	Target *entity.Entity
+	// Mode determines which of the target's directions the camera aligns with.
+	Mode DirectionalMode
+	// Offset is the camera position relative to the target, in the camera's local space. Since the camera looks down
+	// its own negative Z axis, {0, 0, 500} is 500 units directly behind the target, and {0, 100, 500} is also a bit above.
+	Offset mgl32.Vec3
+	// Zoomer handles camera zoom. Zooming changes the length of Offset.
+	Zoomer zoom.Zoom
+
+	// RotationRate determines how quickly the camera turns to match the target, per second. Each second, the camera
+	// turns about 63% of the way (1-1/e) toward the target's orientation for every 1.0 of RotationRate.
+	// Use 0 to match the target's orientation immediately.
+	RotationRate float32
+}
+
+// NewDirectionalCamera creates a camera that starts aligned with the target.
+func NewDirectionalCamera(target *entity.Entity, offset mgl32.Vec3, mode DirectionalMode) *DirectionalCamera {
+	c := &DirectionalCamera{
+		Camera:       *NewCamera(),
+		Target:       target,
+		Mode:         mode,
+		Offset:       offset,
+		RotationRate: 5,
+	}
+	c.Snap()
+	return c
+}
+
+// Snap immediately aligns the camera with its target, skipping any smoothing.
+func (c *DirectionalCamera) Snap() {
+	c.Rotation = c.desiredRotation()
+	c.updatePosition()
+}
+
+// ProjectionOrthographic returns a matrix used to transform from camera space to screen space.
+func (c *DirectionalCamera) ProjectionOrthographic(width, height float32) mgl32.Mat4 {
+	// Distance doesn't affect size in an orthographic projection, so simulate zoom by changing how wide the view is.
+	zoomPercent := c.GetCurrentZoomPercent()
+	return c.Camera.ProjectionOrthographic(width/zoomPercent, height/zoomPercent)
+}
+
+func (c *DirectionalCamera) Update(delta time.Duration) {
+	c.Camera.Update(delta)
+	if c.Zoomer != nil {
+		c.Zoomer.Update()
+	}
+	desired := c.desiredRotation()
+	if c.RotationRate <= 0 {
+		c.Rotation = desired
+	} else {
+		// Turning a fixed fraction of the remaining amount per frame would depend on the frame rate.
+		// An exponential decay based on time passed doesn't.
+		amount := 1 - float32(math.Exp(-float64(c.RotationRate)*delta.Seconds()))
+		c.Rotation = util.QuatSlerpShortest(c.Rotation, desired, amount)
+	}
+	c.updatePosition()
+}
+
+func (c *DirectionalCamera) GetCurrentZoomPercent() float32 {
+	return currentZoomPercent(c.Zoomer)
+}
+
+func (c *DirectionalCamera) updatePosition() {
+	offset := c.Rotation.Rotate(c.Offset.Mul(1.0 / c.GetCurrentZoomPercent()))
+	c.Position = c.Target.Position.Add(offset)
+}
+
+// desiredRotation returns the rotation the camera would have if it were perfectly aligned with its target.
+func (c *DirectionalCamera) desiredRotation() mgl32.Quat {
+	if c.Mode == Directional3D {
+		return c.Target.Rotation.Normalize()
+	}
+	// Flatten the target's Forward onto the XY plane to use as the top of the screen. If the target is facing
+	// directly toward or away from the screen, its Up is the best remaining choice.
+	for _, dir := range []mgl32.Vec3{c.Target.Forward(), c.Target.Up()} {
+		if up := (mgl32.Vec3{dir.X(), dir.Y(), 0}); up.Len() > 1e-4 {
+			return lookRotation(entity.Forward, up)
+		}
+	}
+	return c.Rotation
+}
+
+// lookRotation returns the rotation that turns an entity to face forward, with its Up as close to up as possible.
+// Unlike mgl32.QuatLookAtV, which is meant for building view matrices, the result can be used as an Entity's Rotation.
+func lookRotation(forward, up mgl32.Vec3) mgl32.Quat {
+	forward = forward.Normalize()
+	right := forward.Cross(up)
+	if right.Len() < 1e-6 {
+		// Looking straight along up, so any right vector is as good as another.
+		right = forward.Cross(entity.Forward)
+		if right.Len() < 1e-6 {
+			right = entity.Right
+		}
+	}
+	right = right.Normalize()
+	up = right.Cross(forward)
+	// The columns are where each axis ends up. Entities face down the negative Z axis, so positive Z goes backward.
+	return mgl32.Mat4ToQuat(mgl32.Mat3FromCols(right, up, forward.Mul(-1)).Mat4()).Normalize()
+}
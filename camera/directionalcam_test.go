@@ -0,0 +1,95 @@
+package camera
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+)
+
+// angleBetween returns the angle in radians between two unit vectors.
+func angleBetween(a, b mgl32.Vec3) float32 {
+	return float32(math.Acos(float64(mgl32.Clamp(a.Dot(b), -1, 1))))
+}
+
+func TestDirectionalCamera2D(t *testing.T) {
+	target := entity.Default()
+	// Face along +Y, like the ship in the asteroids demo.
+	target.Rotation = mgl32.AnglesToQuat(mgl32.DegToRad(90), 0, 0, mgl32.XYZ)
+	target.Position = mgl32.Vec3{10, 20, 0}
+	c := NewDirectionalCamera(&target, mgl32.Vec3{0, 0, 500}, Directional2D)
+
+	if got, want := c.Forward(), entity.Forward; !got.ApproxEqualThreshold(want, 1e-4) {
+		t.Errorf("got forward %v, want %v", got, want)
+	}
+	if got, want := c.Up(), (mgl32.Vec3{0, 1, 0}); !got.ApproxEqualThreshold(want, 1e-4) {
+		t.Errorf("got up %v, want %v", got, want)
+	}
+	if got, want := c.Position, (mgl32.Vec3{10, 20, 500}); !got.ApproxEqualThreshold(want, 1e-3) {
+		t.Errorf("got position %v, want %v", got, want)
+	}
+
+	// Turn the target to face -X. The camera shouldn't snap, but should eventually catch up.
+	target.ModifyRotationGlobal(mgl32.Vec3{0, 0, mgl32.DegToRad(90)})
+	want := mgl32.Vec3{-1, 0, 0}
+	c.Update(time.Second / 60)
+	if c.Up().ApproxEqualThreshold(want, 1e-2) {
+		t.Errorf("camera snapped to the new direction %v after one frame", c.Up())
+	}
+	if got := c.Up(); got.X() >= 0 || got.Y() <= 0 {
+		t.Errorf("got up %v after one frame, want it turning toward %v", got, want)
+	}
+	for i := 0; i < 600; i++ {
+		c.Update(time.Second / 60)
+	}
+	if got := c.Up(); !got.ApproxEqualThreshold(want, 1e-3) {
+		t.Errorf("got up %v, want %v", got, want)
+	}
+	if got := c.Forward(); !got.ApproxEqualThreshold(entity.Forward, 1e-3) {
+		t.Errorf("got forward %v, want the camera to keep looking down %v", got, entity.Forward)
+	}
+	if got, want := c.Position, (mgl32.Vec3{10, 20, 500}); !got.ApproxEqualThreshold(want, 1e-2) {
+		t.Errorf("got position %v, want %v", got, want)
+	}
+}
+
+func TestDirectionalCamera3D(t *testing.T) {
+	target := entity.Default()
+	c := NewDirectionalCamera(&target, mgl32.Vec3{0, 0, 10}, Directional3D)
+	c.RotationRate = 0
+
+	// Pitch the target up by 90 degrees. The camera should end up below it, looking up, with its Up along the
+	// target's Up.
+	target.ModifyRotationLocal(mgl32.Vec3{mgl32.DegToRad(90), 0, 0})
+	c.Update(time.Second / 60)
+	if got, want := c.Forward(), target.Forward(); got.Sub(want).Len() > 1e-4 {
+		t.Errorf("got forward %v, want %v", got, want)
+	}
+	if got, want := c.Up(), target.Up(); got.Sub(want).Len() > 1e-4 {
+		t.Errorf("got up %v, want %v", got, want)
+	}
+	if got, want := c.Position, (mgl32.Vec3{0, -10, 0}); got.Sub(want).Len() > 1e-3 {
+		t.Errorf("got position %v, want %v", got, want)
+	}
+}
+
+func TestDirectionalCameraFrameRateIndependent(t *testing.T) {
+	var want mgl32.Vec3
+	for i, frame := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 50 * time.Millisecond} {
+		target := entity.Default()
+		c := NewDirectionalCamera(&target, mgl32.Vec3{0, 0, 500}, Directional2D)
+		target.ModifyRotationGlobal(mgl32.Vec3{0, 0, mgl32.DegToRad(120)})
+		for elapsed := time.Duration(0); elapsed < 300*time.Millisecond; elapsed += frame {
+			c.Update(frame)
+		}
+		if i == 0 {
+			want = c.Up()
+			continue
+		}
+		if got := c.Up(); angleBetween(got, want) > 1e-3 {
+			t.Errorf("frame length %v: got up %v, want %v", frame, got, want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package camera
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/util"
+	"github.com/omustardo/gome/util/ease"
+)
+
+var _ CameraI = (*Director)(nil)
+
+// Director holds the active camera and handles transitions between cameras. It can cut directly to another camera,
+// or blend to it over time. While blending, the position and rotation of the view move smoothly from one camera to
+// the other, and so does the projection, even between orthographic and perspective projections.
+//
+// Since the director is a camera itself, it can be rendered like any other camera. Each camera it's given comes with
+// the projection to render it with, so ProjectionOrthographic and ProjectionPerspective both return the same
+// projection: the one for the active camera, or a blend while transitioning.
+//
+// The director updates the cameras it's showing, so they shouldn't also be updated elsewhere. Cameras should be
+// pointers, since they're compared to make sure each one is only updated once per frame.
+//
+// Sample usage:
+//   director := camera.NewDirector(gameplayCam, camera.Perspective)
+//   for { // game loop
+//     if cutsceneStarted {
+//       railCam.Play()
+//       director.Blend(railCam, camera.Perspective, 2*time.Second, ease.InOutSine)
+//     }
+//     director.Update(fps.Handler.DeltaTime())
+//     shader.Model.SetMVPMatrix(director.Projection(w, h), director.ModelView())
+//   }
+// Create one using NewDirector unless you know what you're doing.
+type Director struct {
+	camera     CameraI
+	projection ProjectionType
+
+	// from is the camera being blended away from. It's nil when not blending.
+	from           CameraI
+	fromProjection ProjectionType
+	elapsed        time.Duration
+	duration       time.Duration
+	ease           ease.Func
+}
+
+// NewDirector creates a director that shows the provided camera.
+func NewDirector(cam CameraI, projection ProjectionType) *Director {
+	return &Director{
+		camera:     cam,
+		projection: projection,
+	}
+}
+
+// Cut immediately switches to the provided camera, ending any blend in progress.
+func (d *Director) Cut(cam CameraI, projection ProjectionType) {
+	d.camera, d.projection = cam, projection
+	d.from = nil
+}
+
+// Blend transitions from the current view to the provided camera over the duration. Ease controls the pace of the
+// transition. Leave it nil to blend at a constant speed. If a blend is already in progress, the new one starts from
+// wherever the old one is, which keeps moving along in the background, so there's never a jump.
+func (d *Director) Blend(cam CameraI, projection ProjectionType, duration time.Duration, ease ease.Func) {
+	if duration <= 0 {
+		d.Cut(cam, projection)
+		return
+	}
+	if d.from == nil {
+		d.from, d.fromProjection = d.camera, d.projection
+	} else {
+		// Nested directors choose their own projection, so fromProjection isn't used for them.
+		previous := *d
+		d.from, d.fromProjection = &previous, d.projection
+	}
+	d.camera, d.projection = cam, projection
+	d.elapsed, d.duration, d.ease = 0, duration, ease
+}
+
+// Active returns the camera that's being shown, or being blended to, and the projection used for it.
+func (d *Director) Active() (CameraI, ProjectionType) {
+	return d.camera, d.projection
+}
+
+// Blending returns whether a transition is in progress.
+func (d *Director) Blending() bool {
+	return d.from != nil
+}
+
+// Progress returns how far through the current blend the view is, from 0 to 1, after easing.
+// It's 1 when not blending.
+func (d *Director) Progress() float32 {
+	if d.from == nil {
+		return 1
+	}
+	return ease.Clamped(d.ease, float32(d.elapsed.Seconds()/d.duration.Seconds()))
+}
+
+// Update updates every camera that's visible, and moves any blend in progress along.
+func (d *Director) Update(delta time.Duration) {
+	d.update(delta, make(map[CameraI]bool))
+}
+
+// update does the work of Update, skipping any cameras that were already updated this frame. Cameras can appear more
+// than once when a blend is interrupted, such as by blending back to the camera that was just blended away from.
+func (d *Director) update(delta time.Duration, updated map[CameraI]bool) {
+	for _, cam := range []CameraI{d.from, d.camera} {
+		if cam == nil || updated[cam] {
+			continue
+		}
+		updated[cam] = true
+		if inner, ok := cam.(*Director); ok {
+			inner.update(delta, updated)
+		} else {
+			cam.Update(delta)
+		}
+	}
+	if d.from != nil {
+		d.elapsed += delta
+		if d.elapsed >= d.duration {
+			d.from = nil
+		}
+	}
+}
+
+// ModelView returns the active camera's ModelView matrix, or a blend between cameras while transitioning.
+func (d *Director) ModelView() mgl32.Mat4 {
+	if d.from == nil {
+		return d.camera.ModelView()
+	}
+	return blendModelView(d.from.ModelView(), d.camera.ModelView(), d.Progress())
+}
+
+// Projection returns the active camera's projection matrix, or a blend between cameras while transitioning.
+func (d *Director) Projection(width, height float32) mgl32.Mat4 {
+	to := projectionOf(d.camera, d.projection, width, height)
+	if d.from == nil {
+		return to
+	}
+	from := projectionOf(d.from, d.fromProjection, width, height)
+	return blendProjection(from, to, d.Progress())
+}
+
+// ProjectionOrthographic returns the same matrix as Projection. See the Director documentation for why.
+func (d *Director) ProjectionOrthographic(width, height float32) mgl32.Mat4 {
+	return d.Projection(width, height)
+}
+
+// ProjectionPerspective returns the same matrix as Projection. See the Director documentation for why.
+func (d *Director) ProjectionPerspective(width, height float32) mgl32.Mat4 {
+	return d.Projection(width, height)
+}
+
+// GetPosition returns the position of the view.
+func (d *Director) GetPosition() mgl32.Vec3 {
+	return d.ModelView().Inv().Col(3).Vec3()
+}
+
+// projectionOf returns the projection for a camera. Nested directors choose their own projection.
+func projectionOf(cam CameraI, projection ProjectionType, width, height float32) mgl32.Mat4 {
+	if inner, ok := cam.(*Director); ok {
+		return inner.Projection(width, height)
+	}
+	return Projection(cam, projection, width, height)
+}
+
+// blendModelView returns a view that's amount of the way from one view to another. The camera's position moves in a
+// straight line, and its rotation turns the shortest way around.
+func blendModelView(from, to mgl32.Mat4, amount float32) mgl32.Mat4 {
+	// A ModelView matrix is the inverse of the camera's transform, which holds its position and rotation.
+	fromTransform, toTransform := from.Inv(), to.Inv()
+	position := fromTransform.Col(3).Vec3().Add(toTransform.Col(3).Vec3().Sub(fromTransform.Col(3).Vec3()).Mul(amount))
+	rotation := util.QuatSlerpShortest(mgl32.Mat4ToQuat(fromTransform).Normalize(), mgl32.Mat4ToQuat(toTransform).Normalize(), amount)
+	return mgl32.Translate3D(position.Elem()).Mul4(rotation.Mat4()).Inv()
+}
+
+// blendProjection returns a projection that's amount of the way from one projection to another.
+// If both are standard perspective projections, their field of view and clipping planes are blended. Otherwise, the
+// matrices are blended directly. That works well between orthographic and perspective projections which show the
+// same area at some distance, like a TargetCamera's at its target: things at that distance stay the same size
+// throughout the blend, while the perspective on everything else gradually changes.
+func blendProjection(from, to mgl32.Mat4, amount float32) mgl32.Mat4 {
+	fromFOV, fromAspect, fromNear, fromFar, ok1 := perspectiveParameters(from)
+	toFOV, toAspect, toNear, toFar, ok2 := perspectiveParameters(to)
+	if ok1 && ok2 {
+		lerp := func(a, b float32) float32 { return a + (b-a)*amount }
+		return mgl32.Perspective(lerp(fromFOV, toFOV), lerp(fromAspect, toAspect), lerp(fromNear, toNear), lerp(fromFar, toFar))
+	}
+	var blended mgl32.Mat4
+	for i := range blended {
+		blended[i] = from[i] + (to[i]-from[i])*amount
+	}
+	return blended
+}
+
+// perspectiveParameters returns the values that were passed to mgl32.Perspective to create a matrix.
+// It returns false if the matrix isn't a standard perspective projection.
+func perspectiveParameters(p mgl32.Mat4) (fov, aspect, near, far float32, ok bool) {
+	// Everything outside of these elements is zero, and the bottom row is {0, 0, -1, 0}.
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			switch {
+			case row == col && row < 3, row == 2 && col == 3:
+				continue
+			case row == 3 && col == 2:
+				if p.At(row, col) != -1 {
+					return 0, 0, 0, 0, false
+				}
+			default:
+				if p.At(row, col) != 0 {
+					return 0, 0, 0, 0, false
+				}
+			}
+		}
+	}
+	f, a, b := p.At(1, 1), p.At(2, 2), p.At(2, 3)
+	if f == 0 || p.At(0, 0) == 0 || a == 1 || a == -1 {
+		return 0, 0, 0, 0, false
+	}
+	// The matrix holds f = 1/tan(fov/2), a = (far+near)/(near-far), and b = 2*far*near/(near-far).
+	fov = 2 * float32(math.Atan(float64(1/f)))
+	return fov, f / p.At(0, 0), b / (a - 1), b / (a + 1), true
+}
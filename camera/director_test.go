@@ -0,0 +1,153 @@
+package camera
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/util/ease"
+)
+
+// countingCamera is a Camera that records how many times it's updated.
+type countingCamera struct {
+	Camera
+	updates int
+}
+
+func (c *countingCamera) Update(delta time.Duration) {
+	c.updates++
+}
+
+func newCountingCamera(position mgl32.Vec3, yaw float32) *countingCamera {
+	c := &countingCamera{Camera: *NewCamera()}
+	c.Position = position
+	c.Rotation = mgl32.QuatRotate(yaw, entity.Up)
+	return c
+}
+
+func TestDirectorCut(t *testing.T) {
+	a, b := newCountingCamera(mgl32.Vec3{0, 0, 0}, 0), newCountingCamera(mgl32.Vec3{100, 0, 0}, 1)
+	d := NewDirector(a, Perspective)
+	d.Cut(b, Perspective)
+	if d.Blending() {
+		t.Error("director is blending after a cut")
+	}
+	if d.ModelView() != b.ModelView() {
+		t.Errorf("got ModelView %v after a cut, want %v", d.ModelView(), b.ModelView())
+	}
+}
+
+func TestDirectorBlend(t *testing.T) {
+	a, b := newCountingCamera(mgl32.Vec3{0, 0, 0}, 0), newCountingCamera(mgl32.Vec3{100, 0, 0}, math.Pi/2)
+	d := NewDirector(a, Perspective)
+	d.Blend(b, Perspective, time.Second, nil)
+
+	// Halfway through, the view is halfway between the cameras and has turned halfway.
+	d.Update(500 * time.Millisecond)
+	if got, want := d.GetPosition(), (mgl32.Vec3{50, 0, 0}); got.Sub(want).Len() > 1e-3 {
+		t.Errorf("got position %v halfway through, want %v", got, want)
+	}
+	forward := d.ModelView().Inv().Mat3().Mul3x1(entity.Forward)
+	if want := mgl32.QuatRotate(math.Pi/4, entity.Up).Rotate(entity.Forward); forward.Sub(want).Len() > 1e-3 {
+		t.Errorf("got forward %v halfway through, want %v", forward, want)
+	}
+
+	d.Update(500 * time.Millisecond)
+	if d.Blending() {
+		t.Error("director is still blending after the duration")
+	}
+	if got, want := d.GetPosition(), b.GetPosition(); got.Sub(want).Len() > 1e-3 {
+		t.Errorf("got position %v after blending, want %v", got, want)
+	}
+	if a.updates != 2 || b.updates != 2 {
+		t.Errorf("got %d and %d updates, want both cameras updated every frame of the blend", a.updates, b.updates)
+	}
+}
+
+func TestDirectorBlendEase(t *testing.T) {
+	a, b := newCountingCamera(mgl32.Vec3{0, 0, 0}, 0), newCountingCamera(mgl32.Vec3{100, 0, 0}, 0)
+	d := NewDirector(a, Perspective)
+	d.Blend(b, Perspective, time.Second, ease.InQuad)
+	d.Update(500 * time.Millisecond)
+	if got, want := d.GetPosition(), (mgl32.Vec3{25, 0, 0}); got.Sub(want).Len() > 1e-3 {
+		t.Errorf("got position %v halfway through an eased blend, want %v", got, want)
+	}
+}
+
+func TestDirectorBlendFOV(t *testing.T) {
+	a, b := newCountingCamera(mgl32.Vec3{}, 0), newCountingCamera(mgl32.Vec3{}, 0)
+	a.FOV, b.FOV = 0.5, 1
+	// Recovering the clipping planes from a matrix is more precise with a smaller range of depths.
+	a.Near, a.Far = 10, 1000
+	b.Near, b.Far = 20, 2000
+	d := NewDirector(a, Perspective)
+	d.Blend(b, Perspective, time.Second, nil)
+	d.Update(500 * time.Millisecond)
+
+	fov, aspect, near, far, ok := perspectiveParameters(d.Projection(800, 600))
+	if !ok {
+		t.Fatal("blended projection isn't a perspective projection")
+	}
+	if math.Abs(float64(fov-0.75)) > 1e-4 || math.Abs(float64(aspect-800.0/600)) > 1e-4 {
+		t.Errorf("got fov=%v aspect=%v, want 0.75 and %v", fov, aspect, 800.0/600)
+	}
+	if math.Abs(float64(near-15)) > 1e-2 || math.Abs(float64(far-1500)) > 1 {
+		t.Errorf("got near=%v far=%v, want 15 and 1500", near, far)
+	}
+}
+
+func TestDirectorBlendOrthographicToPerspective(t *testing.T) {
+	target := entity.Default()
+	cam := NewTargetCamera(&target, mgl32.Vec3{0, 0, 500})
+	cam.MatchPerspective = true
+	d := NewDirector(cam, Orthographic)
+	d.Update(0)
+	size := WindowSize{Width: 800, Height: 600}
+
+	// With MatchPerspective, a TargetCamera's projections show the same area at the target, so things there don't
+	// move during the blend.
+	atTarget := mgl32.Vec3{50, 80, 0}
+	start := Project(d, Orthographic, atTarget, size).Position
+	d.Blend(cam, Perspective, time.Second, nil)
+	for i := 0; i < 4; i++ {
+		d.Update(250 * time.Millisecond)
+		if got := Project(d, Perspective, atTarget, size).Position; got.Sub(start).Len() > 1e-2 {
+			t.Errorf("step %d: got %v at %v, want it to stay at %v", i, atTarget, got, start)
+		}
+	}
+	if want := cam.ProjectionPerspective(800, 600); d.Projection(800, 600) != want {
+		t.Errorf("got projection %v after blending, want %v", d.Projection(800, 600), want)
+	}
+}
+
+func TestDirectorInterruptedBlend(t *testing.T) {
+	a, b, c := newCountingCamera(mgl32.Vec3{0, 0, 0}, 0), newCountingCamera(mgl32.Vec3{100, 0, 0}, 0), newCountingCamera(mgl32.Vec3{0, 100, 0}, 0)
+	d := NewDirector(a, Perspective)
+	d.Blend(b, Perspective, time.Second, nil)
+	d.Update(500 * time.Millisecond)
+
+	// Starting a new blend doesn't jump.
+	before := d.GetPosition()
+	d.Blend(c, Perspective, time.Second, nil)
+	if got := d.GetPosition(); got.Sub(before).Len() > 1e-3 {
+		t.Errorf("got position %v when interrupting a blend, want it to stay at %v", got, before)
+	}
+
+	// Blending back to a camera that's still part of the earlier blend only updates it once per frame.
+	d.Blend(a, Perspective, time.Second, nil)
+	a.updates = 0
+	d.Update(100 * time.Millisecond)
+	if a.updates != 1 {
+		t.Errorf("got %d updates in one frame, want 1", a.updates)
+	}
+
+	d.Update(time.Second)
+	if d.Blending() {
+		t.Error("director is still blending after the last blend finished")
+	}
+	if got, want := d.GetPosition(), a.GetPosition(); got.Sub(want).Len() > 1e-3 {
+		t.Errorf("got position %v after blending back, want %v", got, want)
+	}
+}
@@ -0,0 +1,173 @@
+// effect provides temporary camera effects like screen shake, recoil, and FOV punches.
+// Effects are applied on top of any camera by wrapping it in an effect.Camera. They only change the matrices the
+// wrapper returns, never the wrapped camera, so they can't interfere with camera movement logic.
+//
+// Effects advance by the delta passed to Update, so they pause along with the rest of the game and are
+// deterministic: the same seed and the same sequence of Update calls always produce the same matrices.
+//
+// Sample usage:
+//   cam := effect.NewCamera(camera.NewTargetCamera(player, mgl32.Vec3{0, 0, 500}))
+//   shake := effect.NewShake(time.Now().UnixNano())
+//   cam.Add(shake)
+//   for { // game loop
+//     if playerWasHit {
+//       shake.AddTrauma(0.5)
+//       cam.Add(effect.NewKick(mgl32.Vec3{0, -10, 0}, 200*time.Millisecond))
+//     }
+//     cam.Update(fps.Handler.DeltaTime())
+//     shader.Model.SetMVPMatrix(cam.ProjectionPerspective(w, h), cam.ModelView())
+//   }
+package effect
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/camera"
+)
+
+var _ camera.CameraI = (*Camera)(nil)
+
+// Effect is a temporary change to a camera's view.
+type Effect interface {
+	// Update advances the effect by the provided amount of game time.
+	Update(delta time.Duration)
+	// Offset returns how the effect currently changes the camera.
+	Offset() Offset
+	// Done returns whether the effect is finished. Finished effects are removed from the Camera.
+	Done() bool
+}
+
+// Offset is a change to a camera relative to its own orientation. Offsets from multiple effects are added together.
+type Offset struct {
+	// Position is in the camera's local space: +X is right, +Y is up, and +Z is backward, away from what the camera sees.
+	Position mgl32.Vec3
+	// Rotation is pitch, yaw, and roll in radians, around the camera's local X, Y, and Z axes.
+	Rotation mgl32.Vec3
+	// FOV is added to the field of view, in radians. Positive values widen the view.
+	FOV float32
+}
+
+// Add returns the sum of two offsets.
+func (o Offset) Add(other Offset) Offset {
+	return Offset{
+		Position: o.Position.Add(other.Position),
+		Rotation: o.Rotation.Add(other.Rotation),
+		FOV:      o.FOV + other.FOV,
+	}
+}
+
+// Scale returns the offset multiplied by s.
+func (o Offset) Scale(s float32) Offset {
+	return Offset{
+		Position: o.Position.Mul(s),
+		Rotation: o.Rotation.Mul(s),
+		FOV:      o.FOV * s,
+	}
+}
+
+// OrthographicFOV is the field of view, in radians, that FOV offsets are considered relative to when using an
+// orthographic projection. Orthographic projections don't have a field of view, so a FOV offset instead scales
+// the view by as much as it would scale a perspective view with this field of view. It matches the default camera.
+const OrthographicFOV = math.Pi / 4
+
+// Camera wraps another camera and applies a stack of effects to it.
+// Create one using NewCamera unless you know what you're doing.
+type Camera struct {
+	// Base is the wrapped camera. It's updated along with the effects.
+	Base camera.CameraI
+
+	effects []Effect
+}
+
+// NewCamera creates a Camera with no effects.
+func NewCamera(base camera.CameraI) *Camera {
+	return &Camera{Base: base}
+}
+
+// Add adds an effect to the camera. It stays until its Done method returns true.
+func (c *Camera) Add(e Effect) {
+	c.effects = append(c.effects, e)
+}
+
+// Effects returns the effects that are currently applied.
+func (c *Camera) Effects() []Effect {
+	return c.effects
+}
+
+// Clear removes all effects.
+func (c *Camera) Clear() {
+	c.effects = nil
+}
+
+// Update updates the wrapped camera and all effects, and removes any effects that are done.
+func (c *Camera) Update(delta time.Duration) {
+	c.Base.Update(delta)
+	remaining := c.effects[:0]
+	for _, e := range c.effects {
+		e.Update(delta)
+		if !e.Done() {
+			remaining = append(remaining, e)
+		}
+	}
+	// Clear the unused tail so removed effects can be garbage collected.
+	for i := len(remaining); i < len(c.effects); i++ {
+		c.effects[i] = nil
+	}
+	c.effects = remaining
+}
+
+// Offset returns the sum of all effect offsets.
+func (c *Camera) Offset() Offset {
+	var total Offset
+	for _, e := range c.effects {
+		total = total.Add(e.Offset())
+	}
+	return total
+}
+
+// ModelView returns the wrapped camera's ModelView matrix, with the position and rotation offsets applied.
+func (c *Camera) ModelView() mgl32.Mat4 {
+	offset := c.Offset()
+	mv := c.Base.ModelView()
+	if offset.Position == (mgl32.Vec3{}) && offset.Rotation == (mgl32.Vec3{}) {
+		return mv
+	}
+	// local moves the camera relative to itself. The ModelView matrix is the inverse of the camera's transform,
+	// so the inverse of the local change is applied after it.
+	rotation := mgl32.AnglesToQuat(offset.Rotation.X(), offset.Rotation.Y(), offset.Rotation.Z(), mgl32.XYZ)
+	local := mgl32.Translate3D(offset.Position.Elem()).Mul4(rotation.Mat4())
+	return local.Inv().Mul4(mv)
+}
+
+// ProjectionOrthographic returns the wrapped camera's orthographic projection, scaled by the FOV offset.
+// See OrthographicFOV.
+func (c *Camera) ProjectionOrthographic(width, height float32) mgl32.Mat4 {
+	p := c.Base.ProjectionOrthographic(width, height)
+	return applyFOV(p, OrthographicFOV, c.Offset().FOV)
+}
+
+// ProjectionPerspective returns the wrapped camera's perspective projection, with the FOV offset applied.
+func (c *Camera) ProjectionPerspective(width, height float32) mgl32.Mat4 {
+	p := c.Base.ProjectionPerspective(width, height)
+	// A perspective matrix's [1][1] element is 1/tan(fov/2), so the base camera's FOV can be recovered from it.
+	fov := 2 * float32(math.Atan(float64(1/p.At(1, 1))))
+	return applyFOV(p, fov, c.Offset().FOV)
+}
+
+// GetPosition returns the camera position with the position offset applied.
+func (c *Camera) GetPosition() mgl32.Vec3 {
+	return c.ModelView().Inv().Col(3).Vec3()
+}
+
+// applyFOV scales the X and Y output of a projection as if its field of view changed from fov to fov+change.
+func applyFOV(p mgl32.Mat4, fov, change float32) mgl32.Mat4 {
+	if change == 0 {
+		return p
+	}
+	// Keep the field of view within a sensible range so large punches don't flip the view.
+	newFOV := mgl32.Clamp(fov+change, 0.01, math.Pi-0.01)
+	s := float32(math.Tan(float64(fov/2)) / math.Tan(float64(newFOV/2)))
+	return mgl32.Scale3D(s, s, 1).Mul4(p)
+}
@@ -0,0 +1,188 @@
+package effect
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/camera"
+)
+
+const frame = time.Second / 60
+
+func matricesEqual(a, b mgl32.Mat4) bool {
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > 1e-4 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNoise(t *testing.T) {
+	n := newNoise(1)
+	prev := n.at(0)
+	for x := float32(0); x < 20; x += 0.01 {
+		v := n.at(x)
+		if v < -1 || v > 1 {
+			t.Fatalf("noise at %v is %v, want within [-1, 1]", x, v)
+		}
+		if math.Abs(float64(v-prev)) > 0.05 {
+			t.Fatalf("noise jumped from %v to %v at %v, want it to be smooth", prev, v, x)
+		}
+		prev = v
+	}
+	for x := float32(-3); x <= 3; x++ {
+		if v := n.at(x); v != 0 {
+			t.Errorf("noise at %v is %v, want 0", x, v)
+		}
+	}
+	if a, b := newNoise(1).at(1.5), newNoise(1).at(1.5); a != b {
+		t.Errorf("noise with the same seed differs: %v and %v", a, b)
+	}
+}
+
+func newShakeCamera(seed int64) (*Camera, *camera.Camera, *Shake) {
+	base := camera.NewCamera()
+	base.Position = mgl32.Vec3{0, 0, 500}
+	c := NewCamera(base)
+	s := NewShake(seed)
+	c.Add(s)
+	return c, base, s
+}
+
+func TestShakeDeterministic(t *testing.T) {
+	c1, base, s1 := newShakeCamera(42)
+	c2, _, s2 := newShakeCamera(42)
+	c3, _, s3 := newShakeCamera(7)
+	s1.AddTrauma(0.8)
+	s2.AddTrauma(0.8)
+	s3.AddTrauma(0.8)
+	baseView := base.ModelView()
+
+	differs := false
+	for i := 0; i < 30; i++ {
+		c1.Update(frame)
+		c2.Update(frame)
+		c3.Update(frame)
+		if c1.ModelView() != c2.ModelView() {
+			t.Fatalf("frame %d: shakes with the same seed differ:\n%v\n%v", i, c1.ModelView(), c2.ModelView())
+		}
+		if !matricesEqual(c1.ModelView(), c3.ModelView()) {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("shakes with different seeds were identical")
+	}
+	if matricesEqual(c1.ModelView(), baseView) {
+		t.Error("camera with trauma isn't shaking")
+	}
+	if got, want := base.Position, (mgl32.Vec3{0, 0, 500}); got != want {
+		t.Errorf("shaking modified the base camera position to %v, want %v", got, want)
+	}
+
+	// Trauma decays by 1 per second by default, so it's gone after another second.
+	for i := 0; i < 60; i++ {
+		c1.Update(frame)
+	}
+	if s1.Trauma() != 0 {
+		t.Errorf("got trauma %v, want 0", s1.Trauma())
+	}
+	if got := c1.ModelView(); got != baseView {
+		t.Errorf("got ModelView %v after trauma decayed, want %v", got, baseView)
+	}
+	if len(c1.Effects()) != 1 {
+		t.Errorf("got %d effects, want the shake to remain", len(c1.Effects()))
+	}
+}
+
+func TestKick(t *testing.T) {
+	base := camera.NewCamera()
+	c := NewCamera(base)
+	c.Add(NewKick(mgl32.Vec3{0, -10, 0}, 200*time.Millisecond))
+
+	if got, want := c.GetPosition(), (mgl32.Vec3{0, -10, 0}); !got.ApproxEqualThreshold(want, 1e-4) {
+		t.Errorf("got position %v right after the kick, want %v", got, want)
+	}
+	// Halfway through, the quadratic ease out leaves a quarter of the offset.
+	c.Update(100 * time.Millisecond)
+	if got, want := c.GetPosition(), (mgl32.Vec3{0, -2.5, 0}); !got.ApproxEqualThreshold(want, 1e-4) {
+		t.Errorf("got position %v halfway through the kick, want %v", got, want)
+	}
+	c.Update(100 * time.Millisecond)
+	if len(c.Effects()) != 0 {
+		t.Errorf("got %d effects, want the finished kick to be removed", len(c.Effects()))
+	}
+	if got, want := c.ModelView(), base.ModelView(); got != want {
+		t.Errorf("got ModelView %v after the kick finished, want %v", got, want)
+	}
+}
+
+func TestFOVPunch(t *testing.T) {
+	base := camera.NewCamera()
+	c := NewCamera(base)
+	amount := mgl32.DegToRad(10)
+	c.Add(NewFOVPunch(amount, time.Second))
+
+	// The punch reaches its full amount at the end of the attack.
+	c.Update(100 * time.Millisecond)
+	got := c.ProjectionPerspective(800, 600)
+	want := mgl32.Perspective(base.FOV+amount, 800.0/600.0, base.Near, base.Far)
+	if !matricesEqual(got, want) {
+		t.Errorf("got perspective projection\n%v\nwant\n%v", got, want)
+	}
+
+	// Orthographic projections are scaled the same way as a perspective projection using OrthographicFOV.
+	scale := math.Tan(OrthographicFOV/2) / math.Tan(float64(OrthographicFOV+amount)/2)
+	gotOrtho := c.ProjectionOrthographic(800, 600)
+	wantOrtho := base.ProjectionOrthographic(float32(800/scale), float32(600/scale))
+	if !matricesEqual(gotOrtho, wantOrtho) {
+		t.Errorf("got orthographic projection\n%v\nwant\n%v", gotOrtho, wantOrtho)
+	}
+
+	c.Update(900 * time.Millisecond)
+	if got, want := c.ProjectionPerspective(800, 600), base.ProjectionPerspective(800, 600); got != want {
+		t.Errorf("got perspective projection %v after the punch finished, want %v", got, want)
+	}
+}
+
+func TestRollWobble(t *testing.T) {
+	base := camera.NewCamera()
+	c := NewCamera(base)
+	w := NewRollWobble(0.2)
+	c.Add(w)
+
+	// A quarter of the way through the first swing, the roll is at its peak.
+	c.Update(125 * time.Millisecond)
+	wantRoll := 0.2 * float32(math.Exp(-3*0.125))
+	if got := w.Offset().Rotation; !got.ApproxEqualThreshold(mgl32.Vec3{0, 0, wantRoll}, 1e-4) {
+		t.Errorf("got rotation %v, want a roll of %v", got, wantRoll)
+	}
+	// Rolling counterclockwise tilts the camera's view of up toward the left, so world up appears to the right.
+	if up := c.ModelView().Mul4x1(mgl32.Vec4{0, 1, 0, 0}); up.X() <= 0 {
+		t.Errorf("got world up at %v in view space, want it tilted right", up)
+	}
+
+	for i := 0; i < 10*60 && len(c.Effects()) > 0; i++ {
+		c.Update(frame)
+	}
+	if len(c.Effects()) != 0 {
+		t.Error("roll wobble never finished")
+	}
+}
+
+func TestEffectsAreAdditive(t *testing.T) {
+	c := NewCamera(camera.NewCamera())
+	c.Add(NewKick(mgl32.Vec3{1, 0, 0}, time.Second))
+	c.Add(NewKick(mgl32.Vec3{0, 2, 0}, time.Second))
+	punch := NewFOVPunch(0.1, time.Second)
+	punch.Attack = 0
+	c.Add(punch)
+
+	want := Offset{Position: mgl32.Vec3{1, 2, 0}, FOV: 0.1}
+	if got := c.Offset(); got != want {
+		t.Errorf("got offset %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package effect
+
+import (
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	_ Effect = (*Kick)(nil)
+	_ Effect = (*FOVPunch)(nil)
+)
+
+// Kick instantly moves and rotates the camera, then eases it back over Duration. It's meant for recoil and impacts.
+type Kick struct {
+	// Position and Rotation are the full offset applied at the moment of the kick. See Offset for units.
+	Position, Rotation mgl32.Vec3
+	// Duration is how long the camera takes to return to normal.
+	Duration time.Duration
+
+	elapsed time.Duration
+}
+
+// NewKick creates a Kick that moves the camera by position, in its local space, and recovers over duration.
+// For example, recoil from firing a gun in a 3D game might be NewKick(mgl32.Vec3{0, 0, 0.2}, 150*time.Millisecond)
+// with Rotation set to pitch the view up a bit.
+func NewKick(position mgl32.Vec3, duration time.Duration) *Kick {
+	return &Kick{
+		Position: position,
+		Duration: duration,
+	}
+}
+
+func (k *Kick) Update(delta time.Duration) {
+	k.elapsed += delta
+}
+
+func (k *Kick) Offset() Offset {
+	return Offset{Position: k.Position, Rotation: k.Rotation}.Scale(envelope(k.elapsed, 0, k.Duration))
+}
+
+func (k *Kick) Done() bool {
+	return k.elapsed >= k.Duration
+}
+
+// FOVPunch briefly changes the field of view, like the zoom out effect when a character starts sprinting.
+// It quickly ramps up over Attack, then eases back to normal by the end of Duration.
+type FOVPunch struct {
+	// Amount is the largest change to the field of view, in radians. Positive values widen the view.
+	Amount float32
+	// Attack is how long it takes to reach the full Amount.
+	Attack time.Duration
+	// Duration is the total length of the effect, including Attack.
+	Duration time.Duration
+
+	elapsed time.Duration
+}
+
+// NewFOVPunch creates a FOVPunch that spends the first tenth of its duration reaching the full amount.
+func NewFOVPunch(amount float32, duration time.Duration) *FOVPunch {
+	return &FOVPunch{
+		Amount:   amount,
+		Attack:   duration / 10,
+		Duration: duration,
+	}
+}
+
+func (p *FOVPunch) Update(delta time.Duration) {
+	p.elapsed += delta
+}
+
+func (p *FOVPunch) Offset() Offset {
+	return Offset{FOV: p.Amount * envelope(p.elapsed, p.Attack, p.Duration)}
+}
+
+func (p *FOVPunch) Done() bool {
+	return p.elapsed >= p.Duration
+}
+
+// envelope returns how strong an effect is after elapsed time, from 0 to 1. It rises linearly to 1 over attack,
+// then falls back to 0 at duration. The fall is quadratic, so it's fast at first and then settles gently.
+func envelope(elapsed, attack, duration time.Duration) float32 {
+	switch {
+	case elapsed >= duration:
+		return 0
+	case elapsed < attack:
+		return float32(elapsed) / float32(attack)
+	}
+	remaining := float32(duration-elapsed) / float32(duration-attack)
+	return remaining * remaining
+}
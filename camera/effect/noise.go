@@ -0,0 +1,43 @@
+package effect
+
+import (
+	"math"
+	"math/rand"
+)
+
+// noise is seeded one dimensional Perlin noise. It varies smoothly, which makes shaking look like a handheld camera
+// rather than random jitter. Values are in the range [-1, 1] and are always 0 at whole numbers.
+type noise struct {
+	perm     [512]uint8
+	gradient [256]float32
+}
+
+func newNoise(seed int64) *noise {
+	r := rand.New(rand.NewSource(seed))
+	n := &noise{}
+	for i, p := range r.Perm(256) {
+		n.perm[i] = uint8(p)
+		n.perm[i+256] = uint8(p)
+	}
+	for i := range n.gradient {
+		n.gradient[i] = r.Float32()*2 - 1
+	}
+	return n
+}
+
+// at returns the noise value at x.
+func (n *noise) at(x float32) float32 {
+	floor := float32(math.Floor(float64(x)))
+	i := int(floor) & 255
+	t := x - floor
+
+	g0 := n.gradient[n.perm[i]] * t
+	g1 := n.gradient[n.perm[i+1]] * (t - 1)
+	// The range of the sum of two gradient ramps is [-0.5, 0.5], so double it.
+	return 2 * (g0 + fade(t)*(g1-g0))
+}
+
+// fade is Perlin's smootherstep curve: 6t^5 - 15t^4 + 10t^3.
+func fade(t float32) float32 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
@@ -0,0 +1,90 @@
+package effect
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var _ Effect = (*Shake)(nil)
+
+// Shake is trauma based screen shake, as described in Squirrel Eiserloh's "Math for Game Programmers: Juicing Your
+// Cameras With Math" talk. Events like explosions add trauma, which decays over time. The amount of shaking is
+// trauma^Exponent, so small hits cause subtle shakes while large ones quickly become violent.
+// The shaking follows Perlin noise, so it's smooth and repeatable for a given seed.
+//
+// A Shake is never Done, so it can be added to a Camera once and reused for the whole game.
+type Shake struct {
+	// MaxOffset is the largest distance the camera moves along each of its local axes at full trauma.
+	MaxOffset mgl32.Vec3
+	// MaxAngle is the largest pitch, yaw, and roll at full trauma, in radians.
+	MaxAngle mgl32.Vec3
+	// Frequency is how fast the shake changes direction, in noise samples per second.
+	Frequency float32
+	// Decay is how much trauma is lost per second.
+	Decay float32
+	// Exponent determines how trauma is turned into shaking. 2 or 3 work well.
+	Exponent float32
+
+	trauma float32
+	// elapsed is the game time since the shake was created, used to sample the noise.
+	elapsed time.Duration
+	noise   *noise
+}
+
+// NewShake creates a Shake with reasonable defaults for a 2D game with a camera hundreds of units from the scene.
+// Shakes with the same seed move identically.
+func NewShake(seed int64) *Shake {
+	return &Shake{
+		MaxOffset: mgl32.Vec3{20, 20, 0},
+		MaxAngle:  mgl32.Vec3{0, 0, mgl32.DegToRad(5)},
+		Frequency: 15,
+		Decay:     1,
+		Exponent:  2,
+		noise:     newNoise(seed),
+	}
+}
+
+// AddTrauma increases the amount of shaking. Trauma is limited to the range [0, 1].
+func (s *Shake) AddTrauma(amount float32) {
+	s.trauma = mgl32.Clamp(s.trauma+amount, 0, 1)
+}
+
+// Trauma returns the current trauma, from 0 to 1.
+func (s *Shake) Trauma() float32 {
+	return s.trauma
+}
+
+func (s *Shake) Update(delta time.Duration) {
+	s.elapsed += delta
+	s.trauma = mgl32.Clamp(s.trauma-s.Decay*float32(delta.Seconds()), 0, 1)
+}
+
+func (s *Shake) Offset() Offset {
+	if s.trauma <= 0 {
+		return Offset{}
+	}
+	amount := float32(math.Pow(float64(s.trauma), float64(s.Exponent)))
+	t := float32(s.elapsed.Seconds()) * s.Frequency
+	// Sample the same noise far apart for each axis, so the axes move independently.
+	sample := func(axis int) float32 {
+		return s.noise.at(t + float32(axis)*37.5)
+	}
+	return Offset{
+		Position: mgl32.Vec3{
+			s.MaxOffset.X() * sample(0),
+			s.MaxOffset.Y() * sample(1),
+			s.MaxOffset.Z() * sample(2),
+		},
+		Rotation: mgl32.Vec3{
+			s.MaxAngle.X() * sample(3),
+			s.MaxAngle.Y() * sample(4),
+			s.MaxAngle.Z() * sample(5),
+		},
+	}.Scale(amount)
+}
+
+func (s *Shake) Done() bool {
+	return false
+}
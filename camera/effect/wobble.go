@@ -0,0 +1,52 @@
+package effect
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var _ Effect = (*RollWobble)(nil)
+
+// RollWobble rocks the camera side to side around its forward axis, with the motion dying out over time like a
+// plucked spring. It works well for heavy landings or a ship being rammed.
+type RollWobble struct {
+	// Amplitude is the largest roll angle, in radians.
+	Amplitude float32
+	// Frequency is the number of full back and forth swings per second.
+	Frequency float32
+	// Damping is how quickly the swings get smaller. Larger values die out faster.
+	Damping float32
+
+	elapsed time.Duration
+}
+
+// NewRollWobble creates a RollWobble that swings twice a second and fades out after about two seconds.
+func NewRollWobble(amplitude float32) *RollWobble {
+	return &RollWobble{
+		Amplitude: amplitude,
+		Frequency: 2,
+		Damping:   3,
+	}
+}
+
+func (w *RollWobble) Update(delta time.Duration) {
+	w.elapsed += delta
+}
+
+func (w *RollWobble) Offset() Offset {
+	t := w.elapsed.Seconds()
+	roll := float64(w.strength()) * math.Sin(2*math.Pi*float64(w.Frequency)*t)
+	return Offset{Rotation: mgl32.Vec3{0, 0, float32(roll)}}
+}
+
+// Done returns true once the swings are too small to notice.
+func (w *RollWobble) Done() bool {
+	return w.Damping > 0 && math.Abs(float64(w.strength())) < 1e-4
+}
+
+// strength is the current amplitude of the swings.
+func (w *RollWobble) strength() float32 {
+	return w.Amplitude * float32(math.Exp(-float64(w.Damping)*w.elapsed.Seconds()))
+}
@@ -0,0 +1,152 @@
+package camera
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Plane is the set of points p where Normal.Dot(p) + D == 0. Points on the side that Normal faces have a positive
+// distance from the plane.
+type Plane struct {
+	Normal mgl32.Vec3
+	D      float32
+}
+
+// Distance returns the signed distance from the plane to p.
+func (p Plane) Distance(point mgl32.Vec3) float32 {
+	return p.Normal.Dot(point) + p.D
+}
+
+// Indices of each plane in Frustum.Planes.
+const (
+	PlaneLeft = iota
+	PlaneRight
+	PlaneBottom
+	PlaneTop
+	PlaneNear
+	PlaneFar
+)
+
+// Containment is the result of testing whether a shape is within a Frustum.
+type Containment int
+
+const (
+	// Outside means the shape is entirely outside of the frustum and can't be seen.
+	Outside Containment = iota
+	// Intersecting means the shape is partially inside of the frustum. Note that tests are conservative, so
+	// shapes near the corners of the frustum may be reported as Intersecting even if they're just outside.
+	Intersecting
+	// Inside means the shape is entirely inside of the frustum.
+	Inside
+)
+
+// Frustum is the region of the world that a camera can see. For a perspective projection it's a pyramid with the
+// top cut off, and for an orthographic projection it's a box.
+// All plane normals face inward, so points inside of the frustum are a positive distance from every plane.
+type Frustum struct {
+	Planes [6]Plane
+}
+
+// NewFrustum extracts the view frustum from a combined projection and model view matrix, in world space.
+// For example: camera.NewFrustum(cam.ProjectionPerspective(w, h).Mul4(cam.ModelView()))
+//
+// This uses the method from Gribb and Hartmann's "Fast Extraction of Viewing Frustum Planes from the
+// World-View-Projection Matrix", which works for both orthographic and perspective projections.
+func NewFrustum(projectionModelView mgl32.Mat4) Frustum {
+	m := projectionModelView
+	r0, r1, r2, r3 := m.Row(0), m.Row(1), m.Row(2), m.Row(3)
+	// A point is visible if each of its clip space coordinates is between -w and w.
+	// For example, for the left plane: -w <= x, so 0 <= w + x = (r3 + r0).Dot(point).
+	rows := [6]mgl32.Vec4{
+		PlaneLeft:   r3.Add(r0),
+		PlaneRight:  r3.Sub(r0),
+		PlaneBottom: r3.Add(r1),
+		PlaneTop:    r3.Sub(r1),
+		PlaneNear:   r3.Add(r2),
+		PlaneFar:    r3.Sub(r2),
+	}
+	var f Frustum
+	for i, r := range rows {
+		normal := r.Vec3()
+		length := normal.Len()
+		if length == 0 {
+			// Degenerate plane, like from an invalid matrix. Keep everything rather than nothing.
+			f.Planes[i] = Plane{D: 1}
+			continue
+		}
+		f.Planes[i] = Plane{Normal: normal.Mul(1 / length), D: r.W() / length}
+	}
+	return f
+}
+
+// ContainsPoint returns whether the point is inside of the frustum.
+func (f *Frustum) ContainsPoint(p mgl32.Vec3) bool {
+	for _, plane := range f.Planes {
+		if plane.Distance(p) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SphereContainment returns whether a sphere is inside of the frustum.
+func (f *Frustum) SphereContainment(center mgl32.Vec3, radius float32) Containment {
+	result := Inside
+	for _, plane := range f.Planes {
+		dist := plane.Distance(center)
+		if dist < -radius {
+			return Outside
+		}
+		if dist < radius {
+			result = Intersecting
+		}
+	}
+	return result
+}
+
+// AABBContainment returns whether an axis aligned bounding box, defined by its minimum and maximum corners,
+// is inside of the frustum.
+func (f *Frustum) AABBContainment(min, max mgl32.Vec3) Containment {
+	result := Inside
+	for _, plane := range f.Planes {
+		// The corner furthest along the plane's normal is the most likely to be inside, and the opposite corner is the
+		// most likely to be outside. Checking just those two is enough to know which side the box is on.
+		var positive, negative mgl32.Vec3
+		for i := 0; i < 3; i++ {
+			if plane.Normal[i] >= 0 {
+				positive[i], negative[i] = max[i], min[i]
+			} else {
+				positive[i], negative[i] = min[i], max[i]
+			}
+		}
+		if plane.Distance(positive) < 0 {
+			return Outside
+		}
+		if plane.Distance(negative) < 0 {
+			result = Intersecting
+		}
+	}
+	return result
+}
+
+// Bounded is anything that fits within a sphere, like a *model.Model or *model.Instanced.
+type Bounded interface {
+	BoundingSphere() (center mgl32.Vec3, radius float32)
+}
+
+// Cull appends the items that might be visible to dst and returns the result, along with how many items were
+// outside of the frustum. Nil items are skipped and not counted. Passing the previous frame's result as dst[:0]
+// avoids allocating a new slice every frame.
+func (f *Frustum) Cull(dst, items []Bounded) (visible []Bounded, culled int) {
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		center, radius := item.BoundingSphere()
+		if f.SphereContainment(center, radius) == Outside {
+			culled++
+			continue
+		}
+		dst = append(dst, item)
+	}
+	return dst, culled
+}
@@ -0,0 +1,133 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func planesEqual(a, b Plane) bool {
+	return a.Normal.Sub(b.Normal).Len() < 1e-4 && math.Abs(float64(a.D-b.D)) < 1e-3
+}
+
+func checkPlanes(t *testing.T, name string, got Frustum, want [6]Plane) {
+	t.Helper()
+	names := [6]string{"left", "right", "bottom", "top", "near", "far"}
+	for i := range want {
+		if !planesEqual(got.Planes[i], want[i]) {
+			t.Errorf("%s: got %s plane %+v, want %+v", name, names[i], got.Planes[i], want[i])
+		}
+	}
+}
+
+func TestNewFrustumOrthographic(t *testing.T) {
+	f := NewFrustum(mgl32.Ortho(-10, 20, -5, 5, 1, 100))
+	checkPlanes(t, "ortho", f, [6]Plane{
+		PlaneLeft:   {Normal: mgl32.Vec3{1, 0, 0}, D: 10},
+		PlaneRight:  {Normal: mgl32.Vec3{-1, 0, 0}, D: 20},
+		PlaneBottom: {Normal: mgl32.Vec3{0, 1, 0}, D: 5},
+		PlaneTop:    {Normal: mgl32.Vec3{0, -1, 0}, D: 5},
+		// The camera looks down -Z, so the near plane is at z=-1 and the far plane is at z=-100.
+		PlaneNear: {Normal: mgl32.Vec3{0, 0, -1}, D: -1},
+		PlaneFar:  {Normal: mgl32.Vec3{0, 0, 1}, D: 100},
+	})
+}
+
+func TestNewFrustumPerspective(t *testing.T) {
+	// A 90 degree field of view with a square aspect ratio makes each side plane 45 degrees from the view direction.
+	s := float32(1 / math.Sqrt2)
+	p := mgl32.Perspective(mgl32.DegToRad(90), 1, 1, 100)
+	checkPlanes(t, "perspective", NewFrustum(p), [6]Plane{
+		PlaneLeft:   {Normal: mgl32.Vec3{s, 0, -s}},
+		PlaneRight:  {Normal: mgl32.Vec3{-s, 0, -s}},
+		PlaneBottom: {Normal: mgl32.Vec3{0, s, -s}},
+		PlaneTop:    {Normal: mgl32.Vec3{0, -s, -s}},
+		PlaneNear:   {Normal: mgl32.Vec3{0, 0, -1}, D: -1},
+		PlaneFar:    {Normal: mgl32.Vec3{0, 0, 1}, D: 100},
+	})
+
+	// Moving the camera to (50,0,0) and looking down +X rotates and moves the planes along with it.
+	// The camera's right is now +Z.
+	mv := mgl32.LookAtV(mgl32.Vec3{50, 0, 0}, mgl32.Vec3{51, 0, 0}, mgl32.Vec3{0, 1, 0})
+	checkPlanes(t, "moved perspective", NewFrustum(p.Mul4(mv)), [6]Plane{
+		PlaneLeft:   {Normal: mgl32.Vec3{s, 0, s}, D: -50 * s},
+		PlaneRight:  {Normal: mgl32.Vec3{s, 0, -s}, D: -50 * s},
+		PlaneBottom: {Normal: mgl32.Vec3{s, s, 0}, D: -50 * s},
+		PlaneTop:    {Normal: mgl32.Vec3{s, -s, 0}, D: -50 * s},
+		PlaneNear:   {Normal: mgl32.Vec3{1, 0, 0}, D: -51},
+		PlaneFar:    {Normal: mgl32.Vec3{-1, 0, 0}, D: 150},
+	})
+}
+
+func TestFrustumContainment(t *testing.T) {
+	// The camera is at the origin looking down -Z.
+	f := NewFrustum(mgl32.Perspective(mgl32.DegToRad(90), 1, 1, 100))
+
+	if !f.ContainsPoint(mgl32.Vec3{0, 0, -50}) {
+		t.Error("point in the center of the view isn't contained")
+	}
+	if f.ContainsPoint(mgl32.Vec3{0, 0, 50}) {
+		t.Error("point behind the camera is contained")
+	}
+
+	spheres := []struct {
+		center mgl32.Vec3
+		radius float32
+		want   Containment
+	}{
+		{mgl32.Vec3{0, 0, -50}, 1, Inside},
+		{mgl32.Vec3{0, 0, -50}, 40, Intersecting},
+		{mgl32.Vec3{0, 0, -100}, 1, Intersecting}, // Crossing the far plane.
+		{mgl32.Vec3{0, 0, 10}, 5, Outside},        // Behind the camera.
+		{mgl32.Vec3{60, 0, -50}, 5, Outside},      // Off to the right.
+		{mgl32.Vec3{52, 0, -50}, 5, Intersecting}, // Poking in from the right.
+	}
+	for _, tt := range spheres {
+		if got := f.SphereContainment(tt.center, tt.radius); got != tt.want {
+			t.Errorf("sphere at %v with radius %v: got %v, want %v", tt.center, tt.radius, got, tt.want)
+		}
+	}
+
+	boxes := []struct {
+		min, max mgl32.Vec3
+		want     Containment
+	}{
+		{mgl32.Vec3{-1, -1, -51}, mgl32.Vec3{1, 1, -49}, Inside},
+		{mgl32.Vec3{-1, -1, -200}, mgl32.Vec3{1, 1, -49}, Intersecting},
+		{mgl32.Vec3{-1, -1, 1}, mgl32.Vec3{1, 1, 3}, Outside},
+		{mgl32.Vec3{0, 60, -51}, mgl32.Vec3{1, 70, -49}, Outside},
+		// A huge box surrounding the whole frustum.
+		{mgl32.Vec3{-1000, -1000, -1000}, mgl32.Vec3{1000, 1000, 1000}, Intersecting},
+	}
+	for _, tt := range boxes {
+		if got := f.AABBContainment(tt.min, tt.max); got != tt.want {
+			t.Errorf("box from %v to %v: got %v, want %v", tt.min, tt.max, got, tt.want)
+		}
+	}
+}
+
+// testSphere is a Bounded sphere.
+type testSphere struct {
+	center mgl32.Vec3
+	radius float32
+}
+
+func (s *testSphere) BoundingSphere() (mgl32.Vec3, float32) {
+	return s.center, s.radius
+}
+
+func TestFrustumCull(t *testing.T) {
+	f := NewFrustum(mgl32.Ortho(-100, 100, -100, 100, 0.1, 1000).Mul4(mgl32.Translate3D(0, 0, -500)))
+	onScreen := &testSphere{mgl32.Vec3{0, 0, 0}, 10}
+	overlappingEdge := &testSphere{mgl32.Vec3{105, 0, 0}, 10}
+	offScreen := &testSphere{mgl32.Vec3{200, 0, 0}, 10}
+
+	visible, culled := f.Cull(nil, []Bounded{onScreen, overlappingEdge, offScreen, nil})
+	if len(visible) != 2 || visible[0] != onScreen || visible[1] != overlappingEdge {
+		t.Errorf("got %d visible items, want the on screen sphere and the one overlapping the edge", len(visible))
+	}
+	if culled != 1 {
+		t.Errorf("got %d culled items, want 1", culled)
+	}
+}
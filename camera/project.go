@@ -0,0 +1,171 @@
+package camera
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/input/mouse"
+	"github.com/omustardo/gome/view"
+)
+
+// ProjectionType is which of a camera's projection matrices is used for rendering.
+type ProjectionType int
+
+const (
+	Orthographic ProjectionType = iota
+	Perspective
+)
+
+// Projection returns the camera's projection matrix of the provided type.
+func Projection(cam CameraI, projection ProjectionType, width, height float32) mgl32.Mat4 {
+	if projection == Perspective {
+		return cam.ProjectionPerspective(width, height)
+	}
+	return cam.ProjectionOrthographic(width, height)
+}
+
+// WindowSize is the size of the window in screen coordinates, which is what mouse positions and
+// view.Window.GetSize() use, along with the size of the framebuffer in pixels. They differ on HiDPI displays,
+// where one screen coordinate can cover multiple pixels.
+type WindowSize struct {
+	Width, Height                       float32
+	FramebufferWidth, FramebufferHeight float32
+}
+
+// CurrentWindowSize returns the size of view.Window.
+func CurrentWindowSize() WindowSize {
+	w, h := view.Window.GetSize()
+	fw, fh := view.Window.GetFramebufferSize()
+	return WindowSize{
+		Width:             float32(w),
+		Height:            float32(h),
+		FramebufferWidth:  float32(fw),
+		FramebufferHeight: float32(fh),
+	}
+}
+
+// ToFramebuffer converts window coordinates, with (0,0) at the top left, into framebuffer pixels with (0,0) at the
+// bottom left. This is what OpenGL functions like gl.ReadPixels and gl.Scissor expect.
+func (s WindowSize) ToFramebuffer(pos mgl32.Vec2) mgl32.Vec2 {
+	scaleX, scaleY := s.scale()
+	return mgl32.Vec2{pos.X() * scaleX, (s.Height - pos.Y()) * scaleY}
+}
+
+// FromFramebuffer converts framebuffer pixels, with (0,0) at the bottom left, into window coordinates with (0,0) at
+// the top left.
+func (s WindowSize) FromFramebuffer(pixel mgl32.Vec2) mgl32.Vec2 {
+	scaleX, scaleY := s.scale()
+	return mgl32.Vec2{pixel.X() / scaleX, s.Height - pixel.Y()/scaleY}
+}
+
+// Normalize converts window coordinates into normalized device coordinates, which go from (-1,-1) at the bottom left
+// of the window to (1,1) at the top right. It also returns whether the position is within the window.
+func (s WindowSize) Normalize(pos mgl32.Vec2) (mgl32.Vec2, bool) {
+	if s.Width <= 0 || s.Height <= 0 {
+		return mgl32.Vec2{}, false
+	}
+	ndc := mgl32.Vec2{pos.X()/s.Width*2 - 1, 1 - pos.Y()/s.Height*2}
+	return ndc, ndc.X() >= -1 && ndc.X() <= 1 && ndc.Y() >= -1 && ndc.Y() <= 1
+}
+
+// NormalizedCursor returns the mouse position in normalized device coordinates, and whether it's within view.Window.
+func NormalizedCursor() (mgl32.Vec2, bool) {
+	return CurrentWindowSize().Normalize(mouse.Handler.Position())
+}
+
+// scale returns the number of framebuffer pixels per screen coordinate. If the framebuffer size isn't set, it's
+// assumed to be the same as the window size.
+func (s WindowSize) scale() (x, y float32) {
+	x, y = 1, 1
+	if s.FramebufferWidth > 0 && s.Width > 0 {
+		x = s.FramebufferWidth / s.Width
+	}
+	if s.FramebufferHeight > 0 && s.Height > 0 {
+		y = s.FramebufferHeight / s.Height
+	}
+	return x, y
+}
+
+// ScreenPoint is the result of projecting a point in the world onto the window.
+type ScreenPoint struct {
+	// Position is in window coordinates, like mouse positions. (0,0) is the top left and Y increases downward.
+	Position mgl32.Vec2
+	// Depth is 0 at the near plane and 1 at the far plane, the same as values in the depth buffer.
+	Depth float32
+	// OnScreen is whether the point is within the window and between the near and far planes.
+	// Points behind a perspective camera are never on screen, and their Position isn't meaningful.
+	OnScreen bool
+}
+
+// Project converts a point in the world to where it appears in the window. The window size must be the same as what's
+// passed to the camera's projection functions when rendering, which is usually view.Window.GetSize().
+//
+// For example, to draw a health bar above an enemy:
+//   p := camera.Project(cam, camera.Perspective, enemy.Position.Add(mgl32.Vec3{0, 50, 0}), camera.CurrentWindowSize())
+//   if p.OnScreen {
+//     drawHealthBar(p.Position)
+//   }
+func Project(cam CameraI, projection ProjectionType, point mgl32.Vec3, size WindowSize) ScreenPoint {
+	mvp := Projection(cam, projection, size.Width, size.Height).Mul4(cam.ModelView())
+	clip := mvp.Mul4x1(point.Vec4(1))
+	if clip.W() <= 0 {
+		// Behind the camera.
+		return ScreenPoint{}
+	}
+	ndc := clip.Vec3().Mul(1 / clip.W())
+	result := ScreenPoint{
+		Position: mgl32.Vec2{
+			(ndc.X() + 1) / 2 * size.Width,
+			(1 - ndc.Y()) / 2 * size.Height,
+		},
+		Depth: (ndc.Z() + 1) / 2,
+	}
+	result.OnScreen = ndc.X() >= -1 && ndc.X() <= 1 && ndc.Y() >= -1 && ndc.Y() <= 1 && ndc.Z() >= -1 && ndc.Z() <= 1
+	return result
+}
+
+// Unproject converts a position in the window and a depth into a point in the world. It's the inverse of Project.
+// A depth of 0 is on the near plane and 1 is on the far plane.
+func Unproject(cam CameraI, projection ProjectionType, pos mgl32.Vec2, depth float32, size WindowSize) (mgl32.Vec3, error) {
+	mvp := Projection(cam, projection, size.Width, size.Height).Mul4(cam.ModelView())
+	if mvp.Det() == 0 {
+		return mgl32.Vec3{}, fmt.Errorf("unable to unproject %v: camera matrices aren't invertible", pos)
+	}
+	ndc := mgl32.Vec4{
+		pos.X()/size.Width*2 - 1,
+		1 - pos.Y()/size.Height*2,
+		depth*2 - 1,
+		1,
+	}
+	world := mvp.Inv().Mul4x1(ndc)
+	if world.W() == 0 {
+		return mgl32.Vec3{}, fmt.Errorf("unable to unproject %v: point is at infinity", pos)
+	}
+	return world.Vec3().Mul(1 / world.W()), nil
+}
+
+// UnprojectToPlane returns where the ray from the camera through a position in the window hits a plane.
+// This is useful for converting a mouse click into a position on the ground, like the Z=0 plane used in 2D games:
+//   ground := camera.Plane{Normal: mgl32.Vec3{0, 0, 1}}
+//   clicked, ok := camera.UnprojectToPlane(cam, camera.Orthographic, mouse.Handler.Position(), ground, camera.CurrentWindowSize())
+// It returns false if the ray is parallel to the plane or the plane is behind the camera.
+func UnprojectToPlane(cam CameraI, projection ProjectionType, pos mgl32.Vec2, plane Plane, size WindowSize) (mgl32.Vec3, bool) {
+	near, err := Unproject(cam, projection, pos, 0, size)
+	if err != nil {
+		return mgl32.Vec3{}, false
+	}
+	far, err := Unproject(cam, projection, pos, 1, size)
+	if err != nil {
+		return mgl32.Vec3{}, false
+	}
+	dir := far.Sub(near)
+	denom := plane.Normal.Dot(dir)
+	if denom == 0 {
+		return mgl32.Vec3{}, false
+	}
+	t := -plane.Distance(near) / denom
+	if t < 0 {
+		return mgl32.Vec3{}, false
+	}
+	return near.Add(dir.Mul(t)), true
+}
@@ -0,0 +1,133 @@
+package camera
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/camera/zoom"
+	"github.com/omustardo/gome/core/entity"
+)
+
+// fixedZoom is a zoom.Zoom that never changes.
+type fixedZoom float32
+
+func (z fixedZoom) GetCurrentPercent() float32 { return float32(z) }
+func (z fixedZoom) Range() (min, max float32) { return float32(z), float32(z) }
+func (z fixedZoom) Update()                    {}
+
+func TestProjectOrthographicZoom(t *testing.T) {
+	target := entity.Default()
+	cam := NewTargetCamera(&target, mgl32.Vec3{0, 0, 500})
+	cam.Zoomer = fixedZoom(2)
+	cam.Update(0)
+	size := WindowSize{Width: 800, Height: 600, FramebufferWidth: 1600, FramebufferHeight: 1200}
+
+	// At 200% zoom, each world unit covers two screen coordinates, with the target at the center of the window.
+	point := mgl32.Vec3{100, 50, 0}
+	p := Project(cam, Orthographic, point, size)
+	if want := (mgl32.Vec2{600, 200}); !p.Position.ApproxEqualThreshold(want, 1e-3) {
+		t.Errorf("got window position %v, want %v", p.Position, want)
+	}
+	if !p.OnScreen {
+		t.Error("point in view isn't on screen")
+	}
+	// The framebuffer is twice the size of the window, and its origin is at the bottom left.
+	if got, want := size.ToFramebuffer(p.Position), (mgl32.Vec2{1200, 800}); !got.ApproxEqualThreshold(want, 1e-3) {
+		t.Errorf("got framebuffer position %v, want %v", got, want)
+	}
+	if got := size.FromFramebuffer(size.ToFramebuffer(p.Position)); !got.ApproxEqualThreshold(p.Position, 1e-3) {
+		t.Errorf("got %v converting back from the framebuffer, want %v", got, p.Position)
+	}
+
+	got, err := Unproject(cam, Orthographic, p.Position, p.Depth, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sub(point).Len() > 1e-2 {
+		t.Errorf("got %v unprojecting %v, want %v", got, p.Position, point)
+	}
+
+	// Points beyond the edge of the zoomed in view are off screen.
+	if p := Project(cam, Orthographic, mgl32.Vec3{250, 0, 0}, size); p.OnScreen {
+		t.Errorf("point outside of the zoomed view is on screen at %v", p.Position)
+	}
+}
+
+func TestProjectPerspective(t *testing.T) {
+	target := entity.Default()
+	cam := NewTargetCamera(&target, mgl32.Vec3{0, 200, 500})
+	cam.Update(0)
+	size := WindowSize{Width: 1280, Height: 720}
+
+	// The target is always in the center of the view.
+	p := Project(cam, Perspective, target.Position, size)
+	if want := (mgl32.Vec2{640, 360}); !p.OnScreen || !p.Position.ApproxEqualThreshold(want, 1e-3) {
+		t.Errorf("got target at %v on screen=%v, want %v", p.Position, p.OnScreen, want)
+	}
+
+	points := []mgl32.Vec3{{30, -20, 10}, {-100, 40, -200}, {0, 0, 0}}
+	for _, point := range points {
+		p := Project(cam, Perspective, point, size)
+		got, err := Unproject(cam, Perspective, p.Position, p.Depth, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Sub(point).Len() > 0.5 {
+			t.Errorf("got %v unprojecting %+v, want %v", got, p, point)
+		}
+		ground := Plane{Normal: mgl32.Vec3{0, 0, 1}, D: -point.Z()}
+		onPlane, ok := UnprojectToPlane(cam, Perspective, p.Position, ground, size)
+		if !ok || onPlane.Sub(point).Len() > 0.5 {
+			t.Errorf("got %v, %v intersecting the plane through %v, want the point", onPlane, ok, point)
+		}
+	}
+
+	// Points behind the camera aren't on screen.
+	if p := Project(cam, Perspective, mgl32.Vec3{0, 400, 1000}, size); p.OnScreen {
+		t.Errorf("point behind the camera is on screen at %v", p.Position)
+	}
+}
+
+func TestTargetCameraZoomToCursor(t *testing.T) {
+	tests := []struct {
+		projection ProjectionType
+		mode       ZoomMode
+	}{
+		{Orthographic, ZoomDolly},
+		{Perspective, ZoomDolly},
+		{Perspective, ZoomFOV},
+	}
+	for _, tt := range tests {
+		projection := tt.projection
+		target := entity.Default()
+		scale := float32(1)
+		cam := NewTargetCamera(&target, mgl32.Vec3{0, 0, 500})
+		cam.Zoomer = zoom.NewPinchZoom(0.1, 10, func() float32 { return scale })
+		cam.ZoomMode = tt.mode
+		cam.ZoomToCursor = true
+		// Unprojecting is more precise with a smaller range of depths.
+		cam.Near, cam.Far = 10, 2000
+		cam.Update(0)
+
+		// The cursor is halfway from the center of the window to its top right corner.
+		size := WindowSize{Width: 800, Height: 600}
+		cursor := mgl32.Vec2{600, 150}
+		cam.GetCursor = func() (mgl32.Vec2, bool) { return size.Normalize(cursor) }
+		ground := Plane{Normal: mgl32.Vec3{0, 0, 1}}
+		before, _ := UnprojectToPlane(cam, projection, cursor, ground, size)
+
+		for _, s := range []float32{2, 1.5, 0.25} {
+			scale = s
+			cam.Update(0)
+			after, _ := UnprojectToPlane(cam, projection, cursor, ground, size)
+			if after.Sub(before).Len() > 1e-2 {
+				t.Errorf("projection %v mode %v: got %v under the cursor after zooming to %v, want it to stay at %v", projection, tt.mode, after, cam.GetCurrentZoomPercent(), before)
+			}
+		}
+
+		cam.ResetZoomPan()
+		if got := Project(cam, projection, target.Position, size).Position; got.Sub(mgl32.Vec2{400, 300}).Len() > 1e-2 {
+			t.Errorf("projection %v mode %v: got the target at %v after resetting, want it in the center", projection, tt.mode, got)
+		}
+	}
+}
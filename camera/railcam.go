@@ -0,0 +1,309 @@
+package camera
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/util/ease"
+)
+
+var _ CameraI = (*RailCamera)(nil)
+
+// SplineType is the kind of curve that a RailCamera follows between keyframes.
+type SplineType int
+
+const (
+	// CatmullRom passes smoothly through every keyframe without any extra control points.
+	CatmullRom SplineType = iota
+	// Bezier uses each keyframe's InHandle and OutHandle as control points, which gives full control over the shape
+	// of the path. Keyframes with no handles are connected by straight lines.
+	Bezier
+)
+
+// Keyframe is a point along a RailCamera's path.
+type Keyframe struct {
+	// Position is where the camera is.
+	Position mgl32.Vec3
+	// LookAt is the point the camera faces.
+	LookAt mgl32.Vec3
+	// FOV is the field of view in radians. Zero uses the default camera field of view.
+	FOV float32
+	// Roll is how far the camera is rotated around the direction it's facing, in radians.
+	// Positive values turn the camera counterclockwise, which makes the scene appear to turn clockwise.
+	Roll float32
+
+	// InHandle and OutHandle are Bezier control points, relative to Position. InHandle shapes the path arriving at
+	// the keyframe, and OutHandle shapes the path leaving it. They're ignored by CatmullRom splines.
+	InHandle, OutHandle mgl32.Vec3
+
+	// Duration is how long the camera takes to travel from this keyframe to the next one.
+	// It's unused for the last keyframe.
+	Duration time.Duration
+	// Ease controls how the camera speeds up and slows down while traveling to the next keyframe.
+	// Without easing, it moves at a constant speed. Leave it nil for no easing.
+	Ease ease.Func
+}
+
+// Marker is a named point in time along a RailCamera's path. When playback passes it, its name is reported by
+// RailCamera.PassedMarkers. This is useful for triggering events during a cutscene.
+type Marker struct {
+	Name string
+	Time time.Duration
+}
+
+// RailCamera moves along a path defined by keyframes, like a camera on a dolly track in a film.
+// Each keyframe sets a position, what to look at, the field of view, and the roll. Everything is smoothly
+// interpolated between keyframes. Within each segment the camera moves at a constant speed along the path,
+// adjusted by the segment's easing function.
+// Create one using NewRailCamera unless you know what you're doing.
+type RailCamera struct {
+	Camera
+
+	// Spline determines the shape of the path between keyframes. It can be changed at any time.
+	Spline SplineType
+	// Loop restarts playback from the beginning after reaching the end. For a seamless loop, make the last keyframe
+	// the same as the first.
+	Loop bool
+	// Markers are reported by PassedMarkers when playback passes them.
+	Markers []Marker
+
+	keyframes []Keyframe
+	// segments caches the arc length of each path segment. It's rebuilt when the keyframes or Spline change.
+	segments []arcLengthTable
+	// segmentsSpline is the Spline that segments was built for.
+	segmentsSpline SplineType
+
+	time    time.Duration
+	playing bool
+	// passed holds the names of markers passed during the last Update.
+	passed []string
+}
+
+// NewRailCamera creates a paused RailCamera positioned at the first keyframe.
+func NewRailCamera(keyframes ...Keyframe) *RailCamera {
+	c := &RailCamera{
+		Camera: *NewCamera(),
+	}
+	c.SetKeyframes(keyframes...)
+	return c
+}
+
+// SetKeyframes replaces the path. Playback time is kept, but limited to the new duration.
+func (c *RailCamera) SetKeyframes(keyframes ...Keyframe) {
+	c.keyframes = append([]Keyframe(nil), keyframes...)
+	c.buildSegments()
+	c.Seek(c.time)
+}
+
+// buildSegments measures each segment of the path, so the camera can move along it at a constant speed.
+func (c *RailCamera) buildSegments() {
+	c.segments = make([]arcLengthTable, 0, len(c.keyframes))
+	for i := 0; i+1 < len(c.keyframes); i++ {
+		i := i
+		c.segments = append(c.segments, newArcLengthTable(func(t float32) mgl32.Vec3 {
+			return c.segmentPosition(i, t)
+		}))
+	}
+	c.segmentsSpline = c.Spline
+}
+
+// Keyframes returns the keyframes that make up the path. Use SetKeyframes to change them.
+func (c *RailCamera) Keyframes() []Keyframe {
+	return c.keyframes
+}
+
+// KeyframeTime returns when the camera reaches keyframe i. It's useful for placing Markers.
+func (c *RailCamera) KeyframeTime(i int) time.Duration {
+	var t time.Duration
+	for j := 0; j < i && j+1 < len(c.keyframes); j++ {
+		t += c.keyframes[j].Duration
+	}
+	return t
+}
+
+// Duration returns the time it takes to travel the whole path.
+func (c *RailCamera) Duration() time.Duration {
+	return c.KeyframeTime(len(c.keyframes) - 1)
+}
+
+// Play starts or resumes playback. If playback finished, it restarts from the beginning.
+func (c *RailCamera) Play() {
+	if c.Finished() {
+		c.Seek(0)
+	}
+	c.playing = true
+}
+
+// Pause stops playback at the current time.
+func (c *RailCamera) Pause() {
+	c.playing = false
+}
+
+// Playing returns whether the camera is currently moving along the path.
+func (c *RailCamera) Playing() bool {
+	return c.playing
+}
+
+// Finished returns whether playback reached the end of a path that doesn't loop.
+func (c *RailCamera) Finished() bool {
+	return !c.Loop && c.time >= c.Duration()
+}
+
+// Time returns the current playback time.
+func (c *RailCamera) Time() time.Duration {
+	return c.time
+}
+
+// Seek jumps to a time along the path, without reporting any markers along the way.
+func (c *RailCamera) Seek(t time.Duration) {
+	if t < 0 {
+		t = 0
+	}
+	if d := c.Duration(); t > d {
+		t = d
+	}
+	c.time = t
+	c.apply()
+}
+
+// PassedMarkers returns the names of the markers that playback passed in the last Update, in order.
+func (c *RailCamera) PassedMarkers() []string {
+	return c.passed
+}
+
+func (c *RailCamera) Update(delta time.Duration) {
+	c.Camera.Update(delta)
+	c.passed = c.passed[:0]
+	duration := c.Duration()
+	if !c.playing || duration <= 0 {
+		c.apply()
+		return
+	}
+
+	from, to := c.time, c.time+delta
+	if c.Loop {
+		// Report markers for each trip around the loop, in case the delta is longer than the whole path.
+		for to >= duration {
+			c.passMarkers(from, duration, false)
+			to -= duration
+			from = 0
+		}
+		c.passMarkers(from, to, false)
+	} else {
+		finished := to >= duration
+		if finished {
+			to = duration
+			c.playing = false
+		}
+		c.passMarkers(from, to, finished)
+	}
+	c.time = to
+	c.apply()
+}
+
+// passMarkers records the markers in the time range [from, to), along with markers at exactly to if includeEnd is set.
+func (c *RailCamera) passMarkers(from, to time.Duration, includeEnd bool) {
+	markers := make([]Marker, 0, len(c.Markers))
+	for _, m := range c.Markers {
+		if m.Time >= from && (m.Time < to || (includeEnd && m.Time == to)) {
+			markers = append(markers, m)
+		}
+	}
+	sort.SliceStable(markers, func(i, j int) bool { return markers[i].Time < markers[j].Time })
+	for _, m := range markers {
+		c.passed = append(c.passed, m.Name)
+	}
+}
+
+// apply moves the camera to where it should be at the current time.
+func (c *RailCamera) apply() {
+	if len(c.keyframes) == 0 {
+		return
+	}
+	if c.segmentsSpline != c.Spline {
+		c.buildSegments()
+	}
+	segment, t := c.locate(c.time)
+	next := segment
+	if segment+1 < len(c.keyframes) {
+		next = segment + 1
+	}
+	u := t
+	if segment < len(c.segments) {
+		// Ease in time, then convert the fraction of distance traveled into a curve parameter.
+		u = c.segments[segment].parameter(ease.Clamped(c.keyframes[segment].Ease, t))
+	}
+
+	position := c.segmentPosition(segment, u)
+	lookAt := c.interpolate(segment, u, func(k Keyframe) mgl32.Vec3 { return k.LookAt })
+	fov := c.interpolateScalar(segment, u, func(k Keyframe) float32 { return keyframeFOV(k) })
+	roll := c.interpolateScalar(segment, u, func(k Keyframe) float32 { return k.Roll })
+	if segment == next {
+		lookAt, fov, roll = c.keyframes[segment].LookAt, keyframeFOV(c.keyframes[segment]), c.keyframes[segment].Roll
+	}
+
+	c.Position = position
+	forward := lookAt.Sub(position)
+	if forward.Len() < 1e-6 {
+		forward = c.Forward()
+	}
+	c.Rotation = lookRotation(forward, entity.Up).Mul(mgl32.QuatRotate(roll, mgl32.Vec3{0, 0, 1}))
+	c.FOV = fov
+}
+
+// locate returns the segment that contains time t, and how far through the segment's duration t is, from 0 to 1.
+func (c *RailCamera) locate(t time.Duration) (segment int, fraction float32) {
+	for i := 0; i+1 < len(c.keyframes); i++ {
+		d := c.keyframes[i].Duration
+		if t < d || i+2 == len(c.keyframes) {
+			if d <= 0 {
+				return i, 1
+			}
+			return i, mgl32.Clamp(float32(t)/float32(d), 0, 1)
+		}
+		t -= d
+	}
+	return 0, 0
+}
+
+// segmentPosition returns the position at parameter t along the segment starting at keyframe i.
+func (c *RailCamera) segmentPosition(i int, t float32) mgl32.Vec3 {
+	if i+1 >= len(c.keyframes) {
+		return c.keyframes[len(c.keyframes)-1].Position
+	}
+	k1, k2 := c.keyframes[i], c.keyframes[i+1]
+	if c.Spline == Bezier {
+		return bezier(k1.Position, k1.Position.Add(k1.OutHandle), k2.Position.Add(k2.InHandle), k2.Position, t)
+	}
+	return c.interpolate(i, t, func(k Keyframe) mgl32.Vec3 { return k.Position })
+}
+
+// interpolate returns a Catmull-Rom interpolation of a keyframe value along the segment starting at keyframe i.
+// The first and last keyframes are repeated to provide tangents at the ends of the path.
+func (c *RailCamera) interpolate(i int, t float32, value func(Keyframe) mgl32.Vec3) mgl32.Vec3 {
+	last := len(c.keyframes) - 1
+	at := func(j int) mgl32.Vec3 {
+		if j < 0 {
+			j = 0
+		}
+		if j > last {
+			j = last
+		}
+		return value(c.keyframes[j])
+	}
+	return catmullRom(at(i-1), at(i), at(i+1), at(i+2), t)
+}
+
+func (c *RailCamera) interpolateScalar(i int, t float32, value func(Keyframe) float32) float32 {
+	return c.interpolate(i, t, func(k Keyframe) mgl32.Vec3 { return mgl32.Vec3{value(k)} }).X()
+}
+
+func keyframeFOV(k Keyframe) float32 {
+	if k.FOV <= 0 {
+		return math.Pi / 4
+	}
+	return k.FOV
+}
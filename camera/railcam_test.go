@@ -0,0 +1,142 @@
+package camera
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/util/ease"
+)
+
+// newTestRail creates a rail that curves around the origin, spending one second on each segment.
+func newTestRail() *RailCamera {
+	return NewRailCamera(
+		Keyframe{Position: mgl32.Vec3{0, 0, 100}, FOV: 1, Duration: time.Second},
+		Keyframe{Position: mgl32.Vec3{100, 0, 0}, FOV: 0.5, Roll: 0.2, Duration: time.Second},
+		Keyframe{Position: mgl32.Vec3{0, 0, -100}, FOV: 1, Duration: time.Second},
+		Keyframe{Position: mgl32.Vec3{-100, 0, 0}, FOV: 1},
+	)
+}
+
+func TestRailCameraKeyframes(t *testing.T) {
+	c := newTestRail()
+	if got, want := c.Duration(), 3*time.Second; got != want {
+		t.Errorf("got duration %v, want %v", got, want)
+	}
+	for i, k := range c.Keyframes() {
+		c.Seek(c.KeyframeTime(i))
+		if c.Position.Sub(k.Position).Len() > 1e-3 {
+			t.Errorf("keyframe %d: got position %v, want %v", i, c.Position, k.Position)
+		}
+		if math.Abs(float64(c.FOV-k.FOV)) > 1e-4 {
+			t.Errorf("keyframe %d: got FOV %v, want %v", i, c.FOV, k.FOV)
+		}
+		// Every keyframe looks at the origin.
+		if got, want := c.Forward(), k.LookAt.Sub(k.Position).Normalize(); got.Sub(want).Len() > 1e-4 {
+			t.Errorf("keyframe %d: got forward %v, want %v", i, got, want)
+		}
+	}
+
+	// Roll turns the camera counterclockwise around the direction it faces, so its Up leans to the left.
+	// Looking down -X from the second keyframe, left is +Z.
+	c.Seek(c.KeyframeTime(1))
+	if got := c.Up(); got.Sub(mgl32.Vec3{0, float32(math.Cos(0.2)), float32(math.Sin(0.2))}).Len() > 1e-3 {
+		t.Errorf("got up %v at the rolled keyframe, want it tilted", got)
+	}
+}
+
+func TestRailCameraConstantSpeed(t *testing.T) {
+	c := newTestRail()
+	c.Play()
+	step := 50 * time.Millisecond
+	prev := c.Position
+	var speeds []float32
+	for i := 0; i < int(time.Second/step); i++ {
+		c.Update(step)
+		speeds = append(speeds, c.Position.Sub(prev).Len())
+		prev = c.Position
+	}
+	// The curve parameter changes speed through the segment, but the camera shouldn't.
+	for i, s := range speeds {
+		if math.Abs(float64(s-speeds[0])) > 0.02*float64(speeds[0]) {
+			t.Errorf("step %d: moved %v, want about %v like every other step", i, s, speeds[0])
+		}
+	}
+}
+
+func TestRailCameraEase(t *testing.T) {
+	c := NewRailCamera(
+		Keyframe{Position: mgl32.Vec3{0, 0, 0}, LookAt: mgl32.Vec3{0, 0, -1}, Duration: time.Second, Ease: ease.InOutQuad},
+		Keyframe{Position: mgl32.Vec3{100, 0, 0}, LookAt: mgl32.Vec3{100, 0, -1}},
+	)
+	c.Seek(250 * time.Millisecond)
+	if got, want := c.Position.X(), float32(12.5); math.Abs(float64(got-want)) > 0.5 {
+		t.Errorf("got x=%v a quarter of the way through, want %v", got, want)
+	}
+	c.Seek(500 * time.Millisecond)
+	if got, want := c.Position.X(), float32(50); math.Abs(float64(got-want)) > 0.5 {
+		t.Errorf("got x=%v halfway through, want %v", got, want)
+	}
+}
+
+func TestRailCameraBezier(t *testing.T) {
+	c := NewRailCamera(
+		Keyframe{Position: mgl32.Vec3{0, 0, 0}, OutHandle: mgl32.Vec3{0, 100, 0}, Duration: time.Second},
+		Keyframe{Position: mgl32.Vec3{100, 0, 0}, InHandle: mgl32.Vec3{0, 100, 0}},
+	)
+	c.Spline = Bezier
+	// Halfway along, the curve is at its peak. The curve is symmetric so half the time covers half the distance.
+	c.Seek(500 * time.Millisecond)
+	if want := (mgl32.Vec3{50, 75, 0}); c.Position.Sub(want).Len() > 0.5 {
+		t.Errorf("got position %v, want %v", c.Position, want)
+	}
+}
+
+func TestRailCameraPlayback(t *testing.T) {
+	c := newTestRail()
+	c.Markers = []Marker{{"end", 3 * time.Second}, {"start", 0}, {"second", c.KeyframeTime(1)}}
+
+	// Nothing moves until playback starts.
+	c.Update(time.Second)
+	if c.Time() != 0 || len(c.PassedMarkers()) != 0 {
+		t.Errorf("got time %v and markers %v while paused, want nothing to happen", c.Time(), c.PassedMarkers())
+	}
+
+	c.Play()
+	c.Update(1500 * time.Millisecond)
+	if got, want := c.PassedMarkers(), []string{"start", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got markers %v, want %v", got, want)
+	}
+	c.Pause()
+	c.Update(time.Second)
+	if c.Time() != 1500*time.Millisecond {
+		t.Errorf("got time %v after pausing, want it unchanged", c.Time())
+	}
+
+	c.Play()
+	c.Update(5 * time.Second)
+	if got, want := c.PassedMarkers(), []string{"end"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got markers %v, want %v", got, want)
+	}
+	if !c.Finished() || c.Playing() || c.Time() != c.Duration() {
+		t.Errorf("got finished=%v playing=%v time=%v, want playback stopped at the end", c.Finished(), c.Playing(), c.Time())
+	}
+
+	// Looping wraps around and reports markers from both trips.
+	c.Loop = true
+	c.Seek(2500 * time.Millisecond)
+	c.Play()
+	c.Update(time.Second)
+	if got, want := c.Time(), 500*time.Millisecond; got != want {
+		t.Errorf("got time %v after looping, want %v", got, want)
+	}
+	if got, want := c.PassedMarkers(), []string{"start"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got markers %v, want %v", got, want)
+	}
+	c.Update(4 * time.Second)
+	if got, want := c.PassedMarkers(), []string{"second", "start", "second"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got markers %v, want %v", got, want)
+	}
+}
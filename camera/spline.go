@@ -0,0 +1,76 @@
+package camera
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// catmullRom returns the point at t, from 0 to 1, on the uniform Catmull-Rom spline segment between p1 and p2.
+// p0 and p3 are the points before and after the segment, which determine its tangents.
+func catmullRom(p0, p1, p2, p3 mgl32.Vec3, t float32) mgl32.Vec3 {
+	t2, t3 := t*t, t*t*t
+	return p1.Mul(2).
+		Add(p2.Sub(p0).Mul(t)).
+		Add(p0.Mul(2).Sub(p1.Mul(5)).Add(p2.Mul(4)).Sub(p3).Mul(t2)).
+		Add(p1.Mul(3).Sub(p0).Sub(p2.Mul(3)).Add(p3).Mul(t3)).
+		Mul(0.5)
+}
+
+// bezier returns the point at t, from 0 to 1, on the cubic Bezier curve from p0 to p3 with control points p1 and p2.
+func bezier(p0, p1, p2, p3 mgl32.Vec3, t float32) mgl32.Vec3 {
+	u := 1 - t
+	return p0.Mul(u * u * u).
+		Add(p1.Mul(3 * u * u * t)).
+		Add(p2.Mul(3 * u * t * t)).
+		Add(p3.Mul(t * t * t))
+}
+
+// arcLengthSamples is the number of straight lines used to approximate each curve when measuring its length.
+const arcLengthSamples = 32
+
+// arcLengthTable maps distance along a curve to the curve parameter that reaches that distance. Curve parameters
+// don't move at a constant speed, so this is needed to travel along a curve at a steady pace.
+type arcLengthTable struct {
+	// lengths[i] is the distance along the curve at parameter i/arcLengthSamples.
+	lengths [arcLengthSamples + 1]float32
+}
+
+func newArcLengthTable(curve func(t float32) mgl32.Vec3) arcLengthTable {
+	var table arcLengthTable
+	prev := curve(0)
+	for i := 1; i <= arcLengthSamples; i++ {
+		p := curve(float32(i) / arcLengthSamples)
+		table.lengths[i] = table.lengths[i-1] + p.Sub(prev).Len()
+		prev = p
+	}
+	return table
+}
+
+// length returns the total length of the curve.
+func (a *arcLengthTable) length() float32 {
+	return a.lengths[arcLengthSamples]
+}
+
+// parameter returns the curve parameter that is the provided fraction of the way along the curve's length.
+func (a *arcLengthTable) parameter(fraction float32) float32 {
+	total := a.length()
+	if total == 0 {
+		return fraction
+	}
+	target := mgl32.Clamp(fraction, 0, 1) * total
+	i := sort.Search(arcLengthSamples+1, func(i int) bool { return a.lengths[i] >= target })
+	if i == 0 {
+		return 0
+	}
+	if i > arcLengthSamples {
+		return 1
+	}
+	// Linearly interpolate between the two samples around the target distance.
+	before, after := a.lengths[i-1], a.lengths[i]
+	within := float32(0)
+	if after > before {
+		within = (target - before) / (after - before)
+	}
+	return (float32(i-1) + within) / arcLengthSamples
+}
@@ -2,6 +2,7 @@ package camera
 
 import (
 	"log"
+	"math"
 	"time"
 
 	"github.com/go-gl/mathgl/mgl32"
@@ -13,9 +14,21 @@ var _ CameraI = (*TargetCamera)(nil)
 
 // TODO: TargetCamera does a lot of math for its basic calculations (like the Up function). Consider making more members private so camera rotation can be cached.
 
+// ZoomMode determines how a TargetCamera zooms when using a perspective projection.
+type ZoomMode int
+
+const (
+	// ZoomDolly moves the camera toward or away from the target. The perspective changes as the camera moves, so
+	// objects that are closer to the camera than the target grow faster than the target does when zooming in.
+	ZoomDolly ZoomMode = iota
+	// ZoomFOV keeps the camera in place and narrows or widens its field of view, like the zoom lens on a real camera.
+	// Everything grows at the same rate, and zooming in flattens the perspective.
+	ZoomFOV
+)
+
 // TargetCamera is a camera that is always positioned at an offset from the target entity.
 // Target Position + Offset = Camera Position.
-// Zoomer can modify the length of the offset.
+// Zoomer can modify the length of the offset, or the field of view if ZoomMode is ZoomFOV.
 // The camera always looks toward the target with the provided Up vector determining what orientation the viewport has.
 // Create one using NewTargetCamera unless you know what you're doing.
 type TargetCamera struct {
@@ -26,8 +39,29 @@ type TargetCamera struct {
 	// TargetOffset determines where the camera is positioned in relation to the target.
 	// Camera.Target.Position + Camera.TargetOffset == Camera.Position
 	TargetOffset mgl32.Vec3
-	// Zoomer handles camera zoom.
+	// Zoomer handles camera zoom. Zoom is measured at the target: at 200% zoom, the target and anything else the same
+	// distance from the camera appear twice as large as they do at 100%. This is true for both projections.
 	Zoomer zoom.Zoom
+	// ZoomMode determines how the perspective projection zooms. The orthographic projection has no perspective, so
+	// both modes look the same with it.
+	ZoomMode ZoomMode
+	// MatchPerspective sizes the orthographic projection so that at 100% zoom it shows the same area around the
+	// target as the perspective projection does, so switching between them doesn't change the size of the target on
+	// the screen. By default the orthographic projection shows one unit of the world per pixel at 100% zoom, which
+	// suits 2D games.
+	MatchPerspective bool
+
+	// ZoomToCursor keeps the point under the cursor in place while zooming, rather than zooming toward the center of
+	// the screen. To do this, the view is shifted away from the target until ResetZoomPan is called.
+	// The point under the cursor is measured on the plane through the target that faces the camera.
+	ZoomToCursor bool
+	// GetCursor returns the cursor position in normalized device coordinates, and whether it's within the window.
+	// NewTargetCamera sets it to NormalizedCursor. It's only used if ZoomToCursor is set.
+	GetCursor func() (mgl32.Vec2, bool)
+
+	// zoomPan is how far ZoomToCursor has shifted the view, measured in halves of the view's width and height at 100% zoom.
+	// Measuring it this way lets the same shift work for any window size and for both projections.
+	zoomPan mgl32.Vec2
 }
 
 // ModelView returns a matrix used to transform from model space to camera coordinates.
@@ -48,22 +82,58 @@ func (c *TargetCamera) ModelView() mgl32.Mat4 {
 }
 
 // ProjectionOrthographic returns a matrix used to transform from camera space to screen space.
+// At 100% zoom it shows one unit per pixel, or the same area of the plane through the target as
+// ProjectionPerspective does if MatchPerspective is set.
 func (c *TargetCamera) ProjectionOrthographic(width, height float32) mgl32.Mat4 {
 	// Since distance from target doesn't do a "zoom" effect in an orthographic projection, simulate one
-	// by changing how wide the view is.
+	// by changing how much of the world the view covers.
+	halfHeight := height / 2
+	if c.MatchPerspective {
+		halfHeight = c.viewHalfHeight(height)
+	}
+	halfWidth := halfHeight * width / height
 	zoomPercent := c.GetCurrentZoomPercent()
-	return c.Camera.ProjectionOrthographic(width/zoomPercent, height/zoomPercent)
+	p := c.Camera.ProjectionOrthographic(2*halfWidth/zoomPercent, 2*halfHeight/zoomPercent)
+	return p.Mul4(c.zoomPanTranslation(halfWidth, halfHeight))
+}
+
+// ProjectionPerspective returns a matrix used to transform from camera space to screen space.
+func (c *TargetCamera) ProjectionPerspective(width, height float32) mgl32.Mat4 {
+	p := c.Camera.ProjectionPerspective(width, height)
+	if c.ZoomMode == ZoomFOV {
+		// Zooming by a percent divides the size of the view at the target by that percent, which is
+		// proportional to tan(FOV/2).
+		fov := 2 * math.Atan(math.Tan(float64(c.FOV/2))/float64(c.GetCurrentZoomPercent()))
+		p = mgl32.Perspective(float32(fov), width/height, c.Near, c.Far)
+	}
+	if c.zoomPan == (mgl32.Vec2{}) {
+		return p
+	}
+	halfHeight := c.viewHalfHeight(height)
+	return p.Mul4(c.zoomPanTranslation(halfHeight*width/height, halfHeight))
+}
+
+// viewHalfHeight returns half of the height of the area that's visible on the plane through the target at 100% zoom.
+// With a perspective projection, that's where the plane is TargetOffset away from the camera. If that's not possible
+// to find because the offset or the field of view is zero, it falls back to one unit per pixel of the provided height.
+func (c *TargetCamera) viewHalfHeight(height float32) float32 {
+	halfHeight := c.TargetOffset.Len() * float32(math.Tan(float64(c.FOV/2)))
+	if halfHeight <= 0 {
+		return height / 2
+	}
+	return halfHeight
+}
+
+// zoomPanTranslation returns a matrix that shifts the view by zoomPan, given the size of half the view at 100% zoom.
+// Shifting the view is the same as moving everything in front of the camera the opposite way.
+func (c *TargetCamera) zoomPanTranslation(halfWidth, halfHeight float32) mgl32.Mat4 {
+	return mgl32.Translate3D(-c.zoomPan.X()*halfWidth, -c.zoomPan.Y()*halfHeight, 0)
 }
 
 func (c *TargetCamera) Update(delta time.Duration) {
 	c.Camera.Update(delta)
-	if c.Zoomer != nil {
-		c.Zoomer.Update()
-	}
-	// Adjust the distance from camera to target by the amount of zoom.
-	// A zoom of 3 means everything should be 3 times as large, so the distance from target to camera should be 1/3 the default.
-	offset := c.TargetOffset.Mul(1.0 / c.GetCurrentZoomPercent())
-	c.Position = c.Target.GetPosition().Add(offset)
+	c.updateZoom()
+	c.Position = c.Target.GetPosition().Add(c.zoomedOffset())
 	c.Rotation = mgl32.QuatLookAtV(c.Position, c.Target.GetPosition(), c.Up())
 }
 
@@ -72,6 +142,7 @@ func NewTargetCamera(target entity.Target, offset mgl32.Vec3) *TargetCamera {
 		Camera:       *NewCamera(),
 		Target:       target,
 		TargetOffset: offset,
+		GetCursor:    NormalizedCursor,
 	}
 	// The camera should always face toward the target.
 	c.Rotation = mgl32.QuatLookAtV(c.Position, c.Target.GetPosition(), c.Up())
@@ -102,10 +173,57 @@ func (c *TargetCamera) Right() mgl32.Vec3 {
 }
 
 func (c *TargetCamera) GetCurrentZoomPercent() float32 {
+	return currentZoomPercent(c.Zoomer)
+}
+
+// zoomedOffset returns the offset from the target to the camera at the current zoom.
+func (c *TargetCamera) zoomedOffset() mgl32.Vec3 {
+	if c.ZoomMode == ZoomFOV {
+		return c.TargetOffset
+	}
+	// Adjust the distance from camera to target by the amount of zoom.
+	// A zoom of 3 means everything should be 3 times as large, so the distance from target to camera should be 1/3 the default.
+	return c.TargetOffset.Mul(1.0 / c.GetCurrentZoomPercent())
+}
+
+// ZoomPan returns how far ZoomToCursor has shifted the view, in halves of the view's width and height at 100% zoom.
+// For example, {1, 0} means the target appears where the right edge of the screen would be at 100% zoom.
+func (c *TargetCamera) ZoomPan() mgl32.Vec2 {
+	return c.zoomPan
+}
+
+// ResetZoomPan centers the view on the target again, undoing any shift from zooming to the cursor.
+func (c *TargetCamera) ResetZoomPan() {
+	c.zoomPan = mgl32.Vec2{}
+}
+
+// updateZoom updates the Zoomer, and then shifts the view to keep the point under the cursor in place if
+// ZoomToCursor is set.
+func (c *TargetCamera) updateZoom() {
 	if c.Zoomer == nil {
+		return
+	}
+	before := c.GetCurrentZoomPercent()
+	c.Zoomer.Update()
+	after := c.GetCurrentZoomPercent()
+	if !c.ZoomToCursor || c.GetCursor == nil || before == after {
+		return
+	}
+	cursor, ok := c.GetCursor()
+	if !ok {
+		return
+	}
+	// The visible area shrinks in proportion to zoom, so the point under the cursor is at cursor/zoom + zoomPan.
+	// Keeping it the same before and after the zoom changes gives this shift.
+	c.zoomPan = c.zoomPan.Add(cursor.Mul(1/before - 1/after))
+}
+
+// currentZoomPercent returns the zoom level of the provided Zoomer, or 1 if there is no Zoomer or it's invalid.
+func currentZoomPercent(zoomer zoom.Zoom) float32 {
+	if zoomer == nil {
 		return 1
 	}
-	zoomPercent := c.Zoomer.GetCurrentPercent()
+	zoomPercent := zoomer.GetCurrentPercent()
 	if zoomPercent <= 0 {
 		log.Printf("Invalid camera zoom: %v. Using default", zoomPercent)
 		zoomPercent = 1.0
@@ -0,0 +1,79 @@
+package camera
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+)
+
+func TestTargetCameraZoomIsConsistent(t *testing.T) {
+	size := WindowSize{Width: 800, Height: 600}
+	// screenFraction returns how much of the window's height is covered by an object 100 units tall at the target.
+	screenFraction := func(cam *TargetCamera, projection ProjectionType) float32 {
+		top := Project(cam, projection, mgl32.Vec3{0, 50, 0}, size).Position
+		bottom := Project(cam, projection, mgl32.Vec3{0, -50, 0}, size).Position
+		return (bottom.Y() - top.Y()) / size.Height
+	}
+
+	tests := []struct {
+		name       string
+		projection ProjectionType
+		mode       ZoomMode
+	}{
+		{"orthographic", Orthographic, ZoomDolly},
+		{"perspective dolly", Perspective, ZoomDolly},
+		{"perspective FOV", Perspective, ZoomFOV},
+	}
+	for _, percent := range []float32{0.25, 0.5, 1, 2, 3} {
+		for _, tt := range tests {
+			target := entity.Default()
+			cam := NewTargetCamera(&target, mgl32.Vec3{0, 0, 500})
+			cam.Zoomer = fixedZoom(percent)
+			cam.ZoomMode = tt.mode
+			cam.MatchPerspective = true
+			cam.Update(0)
+
+			// At 100% zoom, the view is 2*500*tan(FOV/2) units tall at the target. Zooming divides that by the percent.
+			visible := 2 * 500 * float32(math.Tan(float64(cam.FOV/2))) / percent
+			if got, want := screenFraction(cam, tt.projection), 100/visible; math.Abs(float64(got-want)) > 1e-3 {
+				t.Errorf("%s at %v%% zoom: got the object covering %v of the screen, want %v", tt.name, percent*100, got, want)
+			}
+		}
+	}
+}
+
+func TestTargetCameraOrthographicPixels(t *testing.T) {
+	target := entity.Default()
+	cam := NewTargetCamera(&target, mgl32.Vec3{0, 0, 500})
+	size := WindowSize{Width: 800, Height: 600}
+	for _, percent := range []float32{0.5, 1, 2} {
+		cam.Zoomer = fixedZoom(percent)
+		cam.Update(0)
+		// Without MatchPerspective, an object 100 units tall covers 100 pixels at 100% zoom.
+		top := Project(cam, Orthographic, mgl32.Vec3{0, 50, 0}, size).Position
+		bottom := Project(cam, Orthographic, mgl32.Vec3{0, -50, 0}, size).Position
+		if got, want := bottom.Y()-top.Y(), 100*percent; math.Abs(float64(got-want)) > 1e-3 {
+			t.Errorf("at %v%% zoom: got the object covering %v pixels, want %v", percent*100, got, want)
+		}
+	}
+}
+
+func TestTargetCameraZoomFOV(t *testing.T) {
+	target := entity.Default()
+	cam := NewTargetCamera(&target, mgl32.Vec3{0, 0, 500})
+	cam.Zoomer = fixedZoom(2)
+	cam.ZoomMode = ZoomFOV
+	cam.Update(0)
+	// The camera doesn't move, and the view narrows instead.
+	if want := (mgl32.Vec3{0, 0, 500}); cam.GetPosition() != want {
+		t.Errorf("got position %v, want %v", cam.GetPosition(), want)
+	}
+
+	cam.ZoomMode = ZoomDolly
+	cam.Update(0)
+	if want := (mgl32.Vec3{0, 0, 250}); cam.GetPosition() != want {
+		t.Errorf("got position %v when dollying, want %v", cam.GetPosition(), want)
+	}
+}
@@ -0,0 +1,131 @@
+package camera
+
+import (
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/util"
+)
+
+var _ CameraI = (*TrailingCamera)(nil)
+
+// TrailingCamera is a TargetCamera that lags behind its target rather than being locked to it.
+// It follows a focus point that chases the target using a critically damped spring (see util.SmoothDampVec3),
+// so it accelerates and slows down smoothly and never overshoots.
+// Focus Point + Offset = Camera Position.
+//
+// The movement only depends on the total time passed, not how it's split into frames, so the camera behaves the same
+// at any frame rate.
+// Create one using NewTrailingCamera unless you know what you're doing.
+type TrailingCamera struct {
+	TargetCamera
+
+	// SmoothTime is approximately how long the camera takes to catch up to the target. Zero follows it exactly.
+	SmoothTime time.Duration
+	// MaxSpeed limits how fast the camera can move, in units per second. Use 0 for no limit.
+	MaxSpeed float32
+
+	// DeadZone is the width and height of a rectangle centered on the focus point, in world units, within which the
+	// target can move without the camera following. The rectangle is aligned with the camera's Right and Up vectors.
+	// Movement toward or away from the camera is always followed.
+	DeadZone mgl32.Vec2
+
+	// LookAhead moves the focus point ahead of the target in the direction it's moving, by the distance it would
+	// travel in this amount of time at its current velocity. This lets players see more of where they're going.
+	LookAhead time.Duration
+	// MaxLookAhead limits how far ahead of the target the focus point can be, in world units. Use 0 for no limit.
+	MaxLookAhead float32
+
+	// focus is the point the camera is centered on. goal is where focus is moving toward.
+	focus, goal mgl32.Vec3
+	// velocity is the current speed of focus. It's needed to keep the smoothing continuous between frames.
+	velocity mgl32.Vec3
+	// lastTargetPosition is used to estimate the target's velocity for LookAhead.
+	lastTargetPosition mgl32.Vec3
+}
+
+// NewTrailingCamera creates a camera that starts at target's position plus offset and trails behind the target as
+// it moves, taking about smoothTime to catch up.
+func NewTrailingCamera(target entity.Target, offset mgl32.Vec3, smoothTime time.Duration) *TrailingCamera {
+	c := &TrailingCamera{
+		TargetCamera: *NewTargetCamera(target, offset),
+		SmoothTime:   smoothTime,
+	}
+	c.Snap()
+	return c
+}
+
+// Snap moves the camera directly to the target, skipping any smoothing. Use it when the target teleports,
+// or after changing the Target.
+func (c *TrailingCamera) Snap() {
+	pos := c.Target.GetPosition()
+	c.focus, c.goal, c.lastTargetPosition = pos, pos, pos
+	c.velocity = mgl32.Vec3{}
+	c.updatePosition()
+}
+
+// Focus returns the point that the camera is centered on.
+func (c *TrailingCamera) Focus() mgl32.Vec3 {
+	return c.focus
+}
+
+func (c *TrailingCamera) Update(delta time.Duration) {
+	c.Camera.Update(delta)
+	c.updateZoom()
+	dt := float32(delta.Seconds())
+	if dt <= 0 {
+		c.updatePosition()
+		return
+	}
+
+	targetPos := c.Target.GetPosition()
+	desired := targetPos
+	if c.LookAhead > 0 {
+		ahead := targetPos.Sub(c.lastTargetPosition).Mul(float32(c.LookAhead.Seconds()) / dt)
+		if c.MaxLookAhead > 0 && ahead.Len() > c.MaxLookAhead {
+			ahead = ahead.Normalize().Mul(c.MaxLookAhead)
+		}
+		desired = desired.Add(ahead)
+	}
+	c.lastTargetPosition = targetPos
+	c.goal = c.applyDeadZone(desired)
+
+	c.focus = util.SmoothDampVec3(c.focus, c.goal, &c.velocity, float32(c.SmoothTime.Seconds()), c.MaxSpeed, dt)
+	c.updatePosition()
+}
+
+// applyDeadZone returns the new goal for the focus point. Within the plane of the screen, the goal only moves far
+// enough to keep desired inside of the dead zone.
+func (c *TrailingCamera) applyDeadZone(desired mgl32.Vec3) mgl32.Vec3 {
+	if c.DeadZone.X() <= 0 && c.DeadZone.Y() <= 0 {
+		return desired
+	}
+	right, up := c.Right().Normalize(), c.Up()
+	diff := desired.Sub(c.goal)
+	x, y := diff.Dot(right), diff.Dot(up)
+	depth := diff.Sub(right.Mul(x)).Sub(up.Mul(y))
+	x = excess(x, c.DeadZone.X()/2)
+	y = excess(y, c.DeadZone.Y()/2)
+	return c.goal.Add(right.Mul(x)).Add(up.Mul(y)).Add(depth)
+}
+
+// excess returns how far x is outside of the range [-halfWidth, halfWidth], keeping its sign.
+func excess(x, halfWidth float32) float32 {
+	if halfWidth <= 0 {
+		return x
+	}
+	switch {
+	case x > halfWidth:
+		return x - halfWidth
+	case x < -halfWidth:
+		return x + halfWidth
+	}
+	return 0
+}
+
+func (c *TrailingCamera) updatePosition() {
+	// Like TargetCamera, zoom may adjust the distance from the camera to the point that it's looking at.
+	c.Position = c.focus.Add(c.zoomedOffset())
+	c.Rotation = mgl32.QuatLookAtV(c.Position, c.focus, c.Up())
+}
@@ -0,0 +1,130 @@
+package camera
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+)
+
+// fakeClock drives a camera and a target that moves with a constant velocity, so tests don't depend on real time.
+type fakeClock struct {
+	now      time.Duration
+	target   *entity.Entity
+	velocity mgl32.Vec3
+}
+
+// advance moves time forward by total, split into frames of the given length.
+func (f *fakeClock) advance(c CameraI, total, frame time.Duration) {
+	for elapsed := time.Duration(0); elapsed < total; elapsed += frame {
+		step := frame
+		if total-elapsed < step {
+			step = total - elapsed
+		}
+		f.now += step
+		f.target.Position = f.target.Position.Add(f.velocity.Mul(float32(step.Seconds())))
+		c.Update(step)
+	}
+}
+
+func newTestTrailingCamera() (*TrailingCamera, *fakeClock) {
+	target := entity.Default()
+	clock := &fakeClock{target: &target}
+	return NewTrailingCamera(&target, mgl32.Vec3{0, 0, 100}, 200*time.Millisecond), clock
+}
+
+func TestTrailingCameraLags(t *testing.T) {
+	c, clock := newTestTrailingCamera()
+	clock.velocity = mgl32.Vec3{10, 0, 0}
+	clock.advance(c, time.Second, time.Second/60)
+	if x := c.Focus().X(); x >= clock.target.Position.X() || x <= 0 {
+		t.Errorf("got focus x=%v, want between 0 and the target at %v", x, clock.target.Position.X())
+	}
+	if got, want := c.Position, c.Focus().Add(mgl32.Vec3{0, 0, 100}); !got.ApproxEqualThreshold(want, 1e-4) {
+		t.Errorf("got camera position %v, want %v", got, want)
+	}
+
+	// Once the target stops, the camera catches up.
+	clock.velocity = mgl32.Vec3{}
+	clock.target.Position = mgl32.Vec3{10, 0, 0}
+	clock.advance(c, 5*time.Second, time.Second/60)
+	if got, want := c.Focus(), (mgl32.Vec3{10, 0, 0}); !got.ApproxEqualThreshold(want, 1e-3) {
+		t.Errorf("got focus %v, want %v", got, want)
+	}
+}
+
+func TestTrailingCameraFrameRateIndependent(t *testing.T) {
+	frames := []time.Duration{time.Second / 144, time.Second / 60, time.Second / 30, 7 * time.Millisecond}
+	var want mgl32.Vec3
+	for i, frame := range frames {
+		c, clock := newTestTrailingCamera()
+		clock.velocity = mgl32.Vec3{3, 4, 0}
+		clock.advance(c, 2*time.Second, frame)
+		// Stop the target and let the camera settle partway.
+		clock.velocity = mgl32.Vec3{}
+		clock.target.Position = mgl32.Vec3{6, 8, 0}
+		clock.advance(c, 100*time.Millisecond, frame)
+		if i == 0 {
+			want = c.Focus()
+			continue
+		}
+		if got := c.Focus(); !got.ApproxEqualThreshold(want, 0.05) {
+			t.Errorf("frame length %v: got focus %v, want %v", frame, got, want)
+		}
+	}
+}
+
+func TestTrailingCameraMaxSpeed(t *testing.T) {
+	c, clock := newTestTrailingCamera()
+	c.MaxSpeed = 2
+	clock.velocity = mgl32.Vec3{50, 0, 0}
+	frame := time.Second / 60
+	prev := c.Focus()
+	for i := 0; i < 120; i++ {
+		clock.advance(c, frame, frame)
+		if speed := c.Focus().Sub(prev).Len() / float32(frame.Seconds()); speed > c.MaxSpeed+1e-3 {
+			t.Fatalf("frame %d: camera moved at %v units per second, want at most %v", i, speed, c.MaxSpeed)
+		}
+		prev = c.Focus()
+	}
+}
+
+func TestTrailingCameraDeadZone(t *testing.T) {
+	c, clock := newTestTrailingCamera()
+	c.DeadZone = mgl32.Vec2{4, 2}
+
+	// Moving within the dead zone doesn't move the camera.
+	clock.target.Position = mgl32.Vec3{1.5, -0.5, 0}
+	c.Update(time.Second)
+	if got := c.Focus(); got != (mgl32.Vec3{}) {
+		t.Errorf("got focus %v, want the camera to stay at the origin", got)
+	}
+
+	// Moving past the edge only moves the camera enough to keep the target at the edge.
+	clock.target.Position = mgl32.Vec3{5, 0, 0}
+	c.Update(10 * time.Second)
+	if got, want := c.Focus(), (mgl32.Vec3{3, 0, 0}); !got.ApproxEqualThreshold(want, 1e-3) {
+		t.Errorf("got focus %v, want %v", got, want)
+	}
+}
+
+func TestTrailingCameraLookAhead(t *testing.T) {
+	c, clock := newTestTrailingCamera()
+	c.LookAhead = 500 * time.Millisecond
+	c.MaxLookAhead = 4
+	clock.velocity = mgl32.Vec3{0, 6, 0}
+	clock.advance(c, 10*time.Second, time.Second/60)
+
+	// Following a steadily moving point, the spring settles at speed*SmoothTime behind it. Look ahead puts that point
+	// 6*0.5 units ahead of the target, so the camera ends up 3-1.2 units ahead.
+	if got, want := c.Focus().Sub(clock.target.Position), (mgl32.Vec3{0, 1.8, 0}); !got.ApproxEqualThreshold(want, 0.1) {
+		t.Errorf("got focus %v relative to the target, want %v", got, want)
+	}
+
+	c.LookAhead = 2 * time.Second
+	clock.advance(c, 10*time.Second, time.Second/60)
+	if got, want := c.Focus().Sub(clock.target.Position), (mgl32.Vec3{0, 2.8, 0}); !got.ApproxEqualThreshold(want, 0.1) {
+		t.Errorf("got focus %v relative to the target, want %v since look ahead is limited to 4 units", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package zoom
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var _ Zoom = (*PinchZoom)(nil)
+
+// PinchZoom implements Zoom. Intended for use with a camera's projection matrix. Intended to get data from a two finger
+// pinch gesture on a touch screen, as an alternative to ScrollZoom for mobile devices.
+type PinchZoom struct {
+	// Range of percent zoom allowed. If the range doesn't include 1.0, then the default starting zoom will be (Min+Max)/2.
+	// Min zoom means zoomed out as far as possible - everything will look small. Max zoom is zoomed in as close as possible.
+	Min, Max float32
+	// curr is the current percent. It is updated in the Update() function.
+	curr float32
+	// GetPinchScale is expected to return the ratio of the distance between two fingers now, compared to the last
+	// update period. Spreading fingers apart (values above 1.0) zooms in, and pinching them together (values below 1.0)
+	// zooms out. It should return 1.0 if there's no pinch in progress.
+	GetPinchScale func() float32
+}
+
+// NewPinchZoom creates a PinchZoom struct.
+// Example usage:
+// 	zoomer := zoom.NewPinchZoom(0.25, 3, // allows zooming out to 25% of the original size, and in to 300% of the original size.
+//	  touch.Handler.Pinch.Scale,
+//	)
+func NewPinchZoom(min, max float32, GetPinchScale func() float32) *PinchZoom {
+	if min <= 0 {
+		panic(fmt.Sprintf("invalid min zoom: %v < 0", min))
+	}
+	if GetPinchScale == nil {
+		panic("GetPinchScale is undefined")
+	}
+	// Try to default to no zoom. If range doesn't allow it, use the average.
+	curr := float32(1.0)
+	if min > 1 || max < 1 {
+		curr = (min + max) / 2
+	}
+	return &PinchZoom{
+		Min:           min,
+		Max:           max,
+		curr:          curr,
+		GetPinchScale: GetPinchScale,
+	}
+}
+
+func (z *PinchZoom) Update() {
+	// Scaling the zoom by the pinch ratio keeps the content under the fingers the same apparent size relative to them.
+	if scale := z.GetPinchScale(); scale > 0 && scale != 1 {
+		z.curr = mgl32.Clamp(z.curr*scale, z.Min, z.Max)
+	}
+}
+
+func (z *PinchZoom) Range() (min, max float32) {
+	return z.Min, z.Max
+}
+
+func (z *PinchZoom) GetCurrentPercent() float32 {
+	return z.curr
+}
@@ -0,0 +1,107 @@
+package zoom
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/util/fps"
+)
+
+var _ Zoom = (*SmoothZoom)(nil)
+
+// SmoothZoom implements Zoom. Like ScrollZoom it's intended to get data from a mouse scroll wheel, but rather than
+// jumping by a fixed amount per scroll wheel tick, it multiplies the zoom by Factor per tick and glides to the new
+// zoom over time. Multiplying keeps each tick feeling the same whether zoomed far in or far out, and scrolling in and
+// then back out by the same amount always returns to the same zoom.
+type SmoothZoom struct {
+	// Range of percent zoom allowed. If the range doesn't include 1.0, then the default starting zoom will be (Min+Max)/2.
+	// Min zoom means zoomed out as far as possible - everything will look small. Max zoom is zoomed in as close as possible.
+	Min, Max float32
+	// Factor is how much each scroll wheel tick multiplies the zoom by. For example, 1.1 zooms in by 10% per tick,
+	// and zooms out by dividing by 1.1.
+	Factor float32
+	// SmoothTime is about how long it takes to reach a new zoom. It gets within 5% of the way in this time, and the
+	// rest of the way soon after. Use 0 to change zoom immediately.
+	SmoothTime time.Duration
+
+	// GetScrollAmount is expected to return the amount scrolled in the last update period.
+	GetScrollAmount func() float32
+	// GetDeltaTime is expected to return the time since the last update. NewSmoothZoom sets it to use fps.Handler.
+	GetDeltaTime func() time.Duration
+
+	// curr is the current percent, and target is the percent that it's moving toward.
+	curr, target float32
+}
+
+// NewSmoothZoom creates a SmoothZoom struct that zooms by 10% per tick, and takes 150 milliseconds to reach the new zoom.
+// Example usage:
+// 	zoomer := zoom.NewSmoothZoom(0.25, 3, // allows zooming out to 25% of the original size, and in to 300% of the original size.
+//	  func() float32 { return mouse.Handler.Scroll().Y() },
+//	)
+func NewSmoothZoom(min, max float32, GetScrollAmount func() float32) *SmoothZoom {
+	if min <= 0 {
+		panic(fmt.Sprintf("invalid min zoom: %v < 0", min))
+	}
+	if GetScrollAmount == nil {
+		panic("GetScrollAmount is undefined")
+	}
+	// Try to default to no zoom. If range doesn't allow it, use the average.
+	curr := float32(1.0)
+	if min > 1 || max < 1 {
+		curr = (min + max) / 2
+	}
+	return &SmoothZoom{
+		Min:             min,
+		Max:             max,
+		Factor:          1.1,
+		SmoothTime:      150 * time.Millisecond,
+		GetScrollAmount: GetScrollAmount,
+		GetDeltaTime:    func() time.Duration { return fps.Handler.DeltaTime() },
+		curr:            curr,
+		target:          curr,
+	}
+}
+
+func (z *SmoothZoom) Update() {
+	if ticks := z.GetScrollAmount(); ticks != 0 && z.Factor > 0 {
+		z.target *= float32(math.Pow(float64(z.Factor), float64(ticks)))
+	}
+	// Clamp every update, in case Min or Max changed.
+	z.target = mgl32.Clamp(z.target, z.Min, z.Max)
+
+	if z.SmoothTime <= 0 {
+		z.curr = z.target
+		return
+	}
+	// Move a fraction of the remaining distance based on the time passed, so the speed doesn't depend on the frame rate.
+	// It takes three time constants to get 95% of the way, so that's what SmoothTime is split into.
+	amount := 1 - math.Exp(-3*z.GetDeltaTime().Seconds()/z.SmoothTime.Seconds())
+	// Interpolate the logarithm of the zoom. Zoom is a ratio, so this makes zooming from 1 to 2 look the same as
+	// zooming from 2 to 4.
+	from, to := math.Log(float64(z.curr)), math.Log(float64(z.target))
+	z.curr = float32(math.Exp(from + (to-from)*amount))
+	if math.Abs(float64(z.curr/z.target)-1) < 1e-4 {
+		z.curr = z.target
+	}
+}
+
+func (z *SmoothZoom) Range() (min, max float32) {
+	return z.Min, z.Max
+}
+
+func (z *SmoothZoom) GetCurrentPercent() float32 {
+	return z.curr
+}
+
+// GetTargetPercent returns the percent zoom that the current zoom is moving toward.
+func (z *SmoothZoom) GetTargetPercent() float32 {
+	return z.target
+}
+
+// Set immediately changes the zoom to the provided percent, limited to the zoom range.
+func (z *SmoothZoom) Set(percent float32) {
+	z.target = mgl32.Clamp(percent, z.Min, z.Max)
+	z.curr = z.target
+}
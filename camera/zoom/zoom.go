@@ -7,7 +7,7 @@ import (
 	"github.com/go-gl/mathgl/mgl32"
 )
 
-// arbitrary number of scroll wheel ticks to change zoom from min to max. Balanced between large size for quick scrolling, and small size for smooth zooming. TODO: Use larger size, but interpolate so it's smooth no matter what.
+// arbitrary number of scroll wheel ticks to change zoom from min to max. Balanced between large size for quick scrolling, and small size for smooth zooming. SmoothZoom interpolates instead, so it's smooth no matter how large its steps are.
 const step = 60
 
 // Zoom provides handling for zooming in and out.
@@ -3,7 +3,9 @@ package zoom_test
 import (
 	"github.com/omustardo/gome/camera/zoom"
 	"log"
+	"math"
 	"testing"
+	"time"
 )
 
 func TestScrollZoom(t *testing.T) {
@@ -39,6 +41,94 @@ func TestScrollZoom(t *testing.T) {
 	}
 }
 
+func TestPinchZoom(t *testing.T) {
+	tests := []struct {
+		pinchScales []float32
+		zoomPercent float32
+	}{
+		{
+			[]float32{1}, 1.0,
+		},
+		{
+			[]float32{2}, 2.0,
+		},
+		{
+			[]float32{2, 0.5}, 1.0,
+		},
+		{
+			[]float32{0.5, 0.25}, 0.25,
+		},
+		{
+			[]float32{10}, 3.0,
+		},
+	}
+
+	for _, tt := range tests {
+		var scale float32
+		zoomer := zoom.NewPinchZoom(0.25, 3, func() float32 { return scale })
+		for _, s := range tt.pinchScales {
+			scale = s
+			zoomer.Update()
+		}
+
+		if current := zoomer.GetCurrentPercent(); current != tt.zoomPercent {
+			t.Errorf("for pinch scales %v, got current zoom percent = %v, expected %v", tt.pinchScales, current, tt.zoomPercent)
+		}
+	}
+}
+
+func TestSmoothZoom(t *testing.T) {
+	var scroll float32
+	zoomer := zoom.NewSmoothZoom(0.25, 3, func() float32 { return scroll })
+	zoomer.GetDeltaTime = func() time.Duration { return 10 * time.Millisecond }
+
+	// Each tick multiplies the zoom, so scrolling in and back out returns to the start.
+	scroll = 2
+	zoomer.Update()
+	if got, want := zoomer.GetTargetPercent(), float32(1.21); math.Abs(float64(got-want)) > 1e-4 {
+		t.Errorf("got target %v after scrolling in twice, want %v", got, want)
+	}
+	if got := zoomer.GetCurrentPercent(); got <= 1 || got >= 1.21 {
+		t.Errorf("got current zoom %v right after scrolling, want it between the old and new zoom", got)
+	}
+	scroll = -2
+	zoomer.Update()
+	if got := zoomer.GetTargetPercent(); math.Abs(float64(got-1)) > 1e-4 {
+		t.Errorf("got target %v after scrolling back out, want 1", got)
+	}
+
+	// The target is limited to the zoom range.
+	scroll = 100
+	zoomer.Update()
+	if got := zoomer.GetTargetPercent(); got != 3 {
+		t.Errorf("got target %v after scrolling far in, want the max of 3", got)
+	}
+	scroll = 0
+	for i := 0; i < 100; i++ {
+		zoomer.Update()
+	}
+	if got := zoomer.GetCurrentPercent(); got != 3 {
+		t.Errorf("got current zoom %v long after scrolling, want 3", got)
+	}
+}
+
+func TestSmoothZoomFrameRateIndependent(t *testing.T) {
+	zoomAfter := func(frame time.Duration) float32 {
+		scroll := float32(5)
+		zoomer := zoom.NewSmoothZoom(0.25, 3, func() float32 { return scroll })
+		zoomer.GetDeltaTime = func() time.Duration { return frame }
+		for elapsed := time.Duration(0); elapsed < 100*time.Millisecond; elapsed += frame {
+			zoomer.Update()
+			scroll = 0
+		}
+		return zoomer.GetCurrentPercent()
+	}
+	slow, fast := zoomAfter(50*time.Millisecond), zoomAfter(5*time.Millisecond)
+	if math.Abs(float64(slow-fast)) > 1e-3 {
+		t.Errorf("got zoom %v at 20 FPS and %v at 200 FPS, want them to match", slow, fast)
+	}
+}
+
 func Example() {
 	var mouseScrollY float32
 
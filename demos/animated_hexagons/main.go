@@ -17,6 +17,7 @@ import (
 	"github.com/omustardo/gome/core/entity"
 	"github.com/omustardo/gome/input/keyboard"
 	"github.com/omustardo/gome/input/mouse"
+	"github.com/omustardo/gome/light"
 	"github.com/omustardo/gome/model"
 	"github.com/omustardo/gome/model/mesh"
 	"github.com/omustardo/gome/shader"
@@ -70,9 +71,15 @@ func main() {
 		Entity: entity.Default(),
 	}
 	player.SetPosition(100, 300, 0)
+	// The player carries a warm light that lights up the hexagons around it.
+	glow := light.NewPoint(mgl32.Vec3{0, 0, 150}, &color.NRGBA{255, 200, 120, 255}, 800)
+	glow.Attached = &player
+	light.Lights.Add(glow)
 
 	cam := camera.NewTargetCamera(&player, mgl32.Vec3{-500, 0, 3000})
-	cam.Zoomer = zoom.NewScrollZoom(0.1, 3, func() float32 { return mouse.Handler.Scroll().Y() })
+	cam.Zoomer = zoom.NewSmoothZoom(0.1, 3, func() float32 { return mouse.Handler.Scroll().Y() })
+	// Zoom in on whichever hexagon is under the mouse, rather than the center of the screen.
+	cam.ZoomToCursor = true
 
 	ticker := time.NewTicker(*frameRate)
 	for !view.Window.ShouldClose() {
@@ -2,7 +2,9 @@ package main
 
 import (
 	"flag"
+	"image/color"
 	"log"
+	"math/rand"
 	"time"
 
 	"github.com/go-gl/mathgl/mgl32"
@@ -11,15 +13,20 @@ import (
 	"github.com/omustardo/gome"
 	"github.com/omustardo/gome/asset"
 	"github.com/omustardo/gome/camera"
+	"github.com/omustardo/gome/camera/effect"
 	"github.com/omustardo/gome/camera/zoom"
 	"github.com/omustardo/gome/demos/asteroids/asteroid"
 	"github.com/omustardo/gome/demos/asteroids/bullet"
 	"github.com/omustardo/gome/demos/asteroids/player"
 	"github.com/omustardo/gome/input/keyboard"
 	"github.com/omustardo/gome/input/mouse"
+	"github.com/omustardo/gome/input/touch"
+	"github.com/omustardo/gome/input/virtual"
 	"github.com/omustardo/gome/model"
+	"github.com/omustardo/gome/model/material"
 	"github.com/omustardo/gome/shader"
 	"github.com/omustardo/gome/util/fps"
+	"github.com/omustardo/gome/util/glutil"
 	"github.com/omustardo/gome/view"
 )
 
@@ -49,14 +56,27 @@ func main() {
 		log.Fatalf("Unable to load asteroid texture: %v", err)
 	}
 	shipMesh.SetTexture(shipTexture)
+	shipMesh.Material = loadMaterial("assets/ship/shipnormal.jpg", "assets/ship/specular.jpg")
 	ship := player.New(shipMesh)
 
-	cam := camera.NewTargetCamera(ship, mgl32.Vec3{0, 0, 500})
-	cam.Zoomer = zoom.NewScrollZoom(0.1, 3,
+	stars, err := starfield(1024, 600)
+	if err != nil {
+		log.Fatalf("Unable to create the sky: %v", err)
+	}
+	sky := model.NewSkybox(stars)
+
+	// The camera turns with the ship so the direction it's facing is always up on the screen.
+	shipCam := camera.NewDirectionalCamera(&ship.Entity, mgl32.Vec3{0, 0, 500}, camera.Directional2D)
+	shipCam.RotationRate = 4
+	shipCam.Zoomer = zoom.NewScrollZoom(0.1, 3,
 		func() float32 {
 			return mouse.Handler.Scroll().Y()
 		},
 	)
+	// Wrap the camera so it can shake when asteroids are destroyed.
+	cam := effect.NewCamera(shipCam)
+	shake := effect.NewShake(time.Now().UnixNano())
+	cam.Add(shake)
 
 	asteroidMesh, err := asset.LoadOBJ("assets/rock/rock1.obj", asset.OBJOpts{Normalize: true, Center: &mgl32.Vec3{0.5, 0.5, 0.5}})
 	if err != nil {
@@ -67,6 +87,8 @@ func main() {
 		log.Fatalf("Unable to load asteroid texture: %v", err)
 	}
 	asteroidMesh.SetTexture(asteroidTexture)
+	// Normal mapping makes the rocks look craggy without needing more vertices.
+	asteroidMesh.Material = loadMaterial("assets/rock/normal1.jpg", "")
 	asteroid.SetMesh(asteroidMesh)
 
 	bullets := []*bullet.Bullet{}
@@ -75,11 +97,24 @@ func main() {
 		asteroids = append(asteroids, asteroid.New())
 	}
 
+	// On screen controls for touch devices. They send the same key presses as the keyboard controls.
+	// They're hidden until the screen is touched so they don't clutter the desktop version.
+	controls := virtual.NewControls()
+	controls.Joysticks = append(controls.Joysticks, virtual.NewFloatingJoystick(mgl32.Vec2{0.2, 0.8}, 0.12))
+	controls.Buttons = append(controls.Buttons, virtual.NewButton(mgl32.Vec2{0.85, 0.8}, 0.08, glfw.KeySpace))
+	controls.Hidden = true
+
 	ticker := time.NewTicker(time.Second / 60)
 	for !view.Window.ShouldClose() {
 		fps.Handler.Update()
 		glfw.PollEvents() // Reads window events, like keyboard and mouse input.
 		// Handler.Update takes current input and stores it. This is necessary to detect things like the start of a keypress.
+		touch.Handler.Update()
+		w, h := view.Window.GetSize()
+		if touch.Handler.Count() > 0 {
+			controls.Hidden = false
+		}
+		controls.Update(touch.Handler.Touches(), float32(w), float32(h)) // Must be before the keyboard update, since it simulates key presses.
 		keyboard.Handler.Update()
 		mouse.Handler.Update()
 
@@ -114,6 +149,7 @@ func main() {
 				if !bulletsToRemove[j] && a.Position.Sub(b.Position).Len() <= a.Scale.X()+b.Scale.X() {
 					bulletsToRemove[j] = true
 					asteroidsToRemove[i] = true
+					shake.AddTrauma(0.4)
 					a1, a2 := a.Split()
 					if a1 != nil && a2 != nil {
 						asteroidsToAdd = append(asteroidsToAdd, a1, a2)
@@ -160,7 +196,6 @@ func main() {
 
 		// Set up Model-View-Projection Matrix and send it to the shader programs.
 		mvMatrix := cam.ModelView()
-		w, h := view.Window.GetSize()
 		pMatrix := cam.ProjectionPerspective(float32(w), float32(h))
 		shader.Model.SetMVPMatrix(pMatrix, mvMatrix)
 
@@ -177,9 +212,48 @@ func main() {
 			b.Render()
 		}
 		ship.Render()
+		// The sky goes behind everything in the world, but not the controls which are drawn on top of it.
+		sky.Render()
+		controls.Render(float32(w), float32(h))
 
 		// Swaps the buffer that was drawn on to be visible. The visible buffer becomes the one that gets drawn on until it's swapped again.
 		view.Window.SwapBuffers()
 		<-ticker.C // wait up to 1/60th of a second. This caps framerate to 60 FPS.
 	}
 }
+
+// starfield creates a cubemap of randomly placed stars on a black background.
+func starfield(size, stars int) (*glutil.Cubemap, error) {
+	var faces [6][]uint8
+	for i := range faces {
+		face := make([]uint8, size*size*4)
+		for alpha := 3; alpha < len(face); alpha += 4 {
+			face[alpha] = 255
+		}
+		for s := 0; s < stars; s++ {
+			p := rand.Intn(size*size) * 4
+			brightness := uint8(100 + rand.Intn(156))
+			face[p], face[p+1], face[p+2] = brightness, brightness, brightness
+		}
+		faces[i] = face
+	}
+	return glutil.LoadCubemapData(size, faces)
+}
+
+// loadMaterial creates a material with the provided normal and specular maps. Leave a path empty to skip that map.
+func loadMaterial(normalMapPath, specularMapPath string) *material.Material {
+	m := material.New()
+	m.Specular = &color.NRGBA{120, 120, 120, 255}
+	var err error
+	if normalMapPath != "" {
+		if m.NormalMap, err = asset.LoadTexture(normalMapPath); err != nil {
+			log.Fatalf("Unable to load normal map: %v", err)
+		}
+	}
+	if specularMapPath != "" {
+		if m.SpecularMap, err = asset.LoadTexture(specularMapPath); err != nil {
+			log.Fatalf("Unable to load specular map: %v", err)
+		}
+	}
+	return m
+}
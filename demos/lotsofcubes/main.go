@@ -3,6 +3,7 @@ package main
 import (
 	"flag"
 	"image/color"
+	"log"
 	"math"
 	"math/rand"
 	"time"
@@ -24,7 +25,9 @@ import (
 )
 
 var (
-	count        = flag.Int("count", 100, "number of objects to draw")
+	count        = flag.Int("count", 10000, "number of objects to draw")
+	instanced    = flag.Bool("instanced", true, "draw all of the cubes at once rather than one at a time")
+	batch        = flag.Bool("batch", false, "with -instanced, merge the cubes into large meshes rather than using instanced drawing")
 	windowWidth  = flag.Int("window_width", 1000, "initial window width")
 	windowHeight = flag.Int("window_height", 1000, "initial window height")
 )
@@ -34,27 +37,43 @@ func main() {
 	terminate := gome.Initialize("Lots of Cubes", *windowWidth, *windowHeight, "")
 	defer terminate()
 
-	genCubes := func(count int) []*model.Model {
+	genCubes := func(count int) []model.Instance {
 		// Try to evenly space the cubes in a grid centered at (0,0)
 		scaleMin, scaleMax := float32(50), float32(150)
 		countPerRow := int(math.Sqrt(float64(count)))
-		var cubes []*model.Model
+		var cubes []model.Instance
 		for i := 0; i < count; i++ {
-			col := &color.NRGBA{util.RandUint8(), util.RandUint8(), util.RandUint8(), 255}
 			scale := rand.Float32()*(scaleMax-scaleMin) + scaleMin
-			c := &model.Model{
-				Mesh: mesh.NewCube(col, gl.Texture{}),
+			cubes = append(cubes, model.Instance{
 				Entity: entity.Entity{
 					Position: mgl32.Vec3{float32(i%countPerRow)*scaleMax - scaleMax*float32(countPerRow)/2.0, float32(i/countPerRow)*scaleMax - scaleMax*float32(countPerRow)/2.0, 0},
 					Scale:    mgl32.Vec3{scale, scale, scale},
 					Rotation: util.RandQuat(),
 				},
-			}
-			cubes = append(cubes, c)
+				Color: &color.NRGBA{util.RandUint8(), util.RandUint8(), util.RandUint8(), 255},
+			})
 		}
 		return cubes
 	}
-	cubes := genCubes(*count)
+
+	// All of the cubes are either drawn together with a few draw calls, or as separate models that each take a draw
+	// call but can be culled individually.
+	var cubeSet *model.Instanced
+	var cubes []camera.Bounded
+	if *instanced {
+		cubeSet = model.NewInstanced(mesh.NewCube(nil, gl.Texture{}), genCubes(*count))
+		cubeSet.Batch = *batch
+		if err := cubeSet.Update(); err != nil {
+			log.Println(err)
+		}
+	} else {
+		for _, c := range genCubes(*count) {
+			cubes = append(cubes, &model.Model{
+				Mesh:   mesh.NewCube(c.Color, gl.Texture{}),
+				Entity: c.Entity,
+			})
+		}
+	}
 
 	// target is what the camera is meant to look at and follow. It is not rendered.
 	target := &model.Model{
@@ -65,6 +84,10 @@ func main() {
 	cam := camera.NewRotateCamera(target, 1000)
 	rotationPerSecond := mgl32.AnglesToQuat(float32(math.Pi/4)*0.8, float32(math.Pi/4), float32(math.Pi/4)*1.3, mgl32.XYZ)
 
+	// visible is reused every frame to avoid allocating a new slice.
+	var visible []camera.Bounded
+	debugLogTicker := time.NewTicker(time.Second)
+
 	ticker := time.NewTicker(time.Second / 60)
 	for !view.Window.ShouldClose() {
 		fps.Handler.Update()
@@ -89,8 +112,28 @@ func main() {
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 		model.RenderXYZAxes()
 
-		for _, c := range cubes {
-			c.Render()
+		var culled int
+		if cubeSet != nil {
+			cubeSet.Render()
+			visible = visible[:0]
+		} else {
+			// Skip cubes that are outside of the camera's view.
+			frustum := camera.NewFrustum(pMatrix.Mul4(mvMatrix))
+			visible, culled = frustum.Cull(visible[:0], cubes)
+			for _, c := range visible {
+				c.(*model.Model).Render()
+			}
+		}
+
+		// Debug logging - limited to once every X seconds to avoid spam.
+		select {
+		case <-debugLogTicker.C:
+			rendered := len(visible)
+			if cubeSet != nil {
+				rendered = len(cubeSet.Instances)
+			}
+			log.Printf("%d fps, rendered %d cubes, culled %d", fps.Handler.FPS(), rendered, culled)
+		default:
 		}
 
 		// Swaps the buffer that was drawn on to be visible. The visible buffer becomes the one that gets drawn on until it's swapped again.
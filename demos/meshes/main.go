@@ -19,12 +19,15 @@ import (
 	"github.com/omustardo/gome/core/entity"
 	"github.com/omustardo/gome/input/keyboard"
 	"github.com/omustardo/gome/input/mouse"
+	"github.com/omustardo/gome/light"
 	"github.com/omustardo/gome/model"
+	"github.com/omustardo/gome/model/material"
 	"github.com/omustardo/gome/model/mesh"
 	"github.com/omustardo/gome/shader"
 	"github.com/omustardo/gome/util"
 	"github.com/omustardo/gome/util/fps"
 	"github.com/omustardo/gome/view"
+	"github.com/omustardo/gome/view/postprocess"
 )
 
 var (
@@ -32,12 +35,52 @@ var (
 	windowHeight = flag.Int("window_height", 1000, "initial window height")
 
 	frameRate = flag.Duration("framerate", time.Second/60, `Cap on framerate. Provide with units, like "16.66ms"`)
+	arcball   = flag.Bool("arcball", true, "Use a mouse controlled camera to inspect the meshes. Drag with the left mouse button to rotate, drag with the right or middle button to pan, scroll to dolly, and double click a mesh to rotate around it. If false, W,A,S,D moves the camera.")
 
 	// Explicitly listing the base dir is a hack. It's needed because `go run` produces a binary in a tmp folder so we can't
 	// use relative asset paths. More explanation in omustardo\gome\asset\asset.go
 	baseDir = flag.String("base_dir", `C:\workspace\Go\src\github.com\omustardo\gome\demos\meshes`, "All file paths should be specified relative to this root.")
 )
 
+// toonVertexSource and toonFragmentSource are a custom shader program. The standard uniforms and attributes, like
+// uPMatrix, aNormal, and the lights, are set automatically because they have the same names as in the model shader.
+const (
+	toonVertexSource = `
+attribute vec3 aVertexPosition;
+attribute vec3 aNormal;
+
+uniform mat4 uTranslationMatrix, uRotationMatrix, uScaleMatrix;
+uniform mat3 uNormalMatrix;
+uniform mat4 uMVMatrix;
+uniform mat4 uPMatrix;
+
+varying vec3 vNormal;
+
+void main() {
+	vNormal = uNormalMatrix * aNormal;
+	gl_Position = uPMatrix * uMVMatrix * uTranslationMatrix * uRotationMatrix * uScaleMatrix * vec4(aVertexPosition, 1.0);
+}
+`
+	toonFragmentSource = `
+uniform vec4 uColor;
+uniform float uBands;
+// Only the most relevant light is used, which is the sun. Its direction is the way it shines.
+uniform int uLightCount;
+uniform vec4 uLightDirection[1];
+
+varying vec3 vNormal;
+
+void main() {
+	float brightness = 0.0;
+	if (uLightCount > 0) {
+		brightness = max(dot(normalize(vNormal), -uLightDirection[0].xyz), 0.0);
+	}
+	brightness = ceil(brightness * uBands) / uBands;
+	gl_FragColor = vec4(uColor.rgb * (0.3 + 0.7 * brightness), uColor.a);
+}
+`
+)
+
 func main() {
 	flag.Parse()
 	terminate := gome.Initialize("Mesh Showcase", *windowWidth, *windowHeight, *baseDir)
@@ -240,6 +283,61 @@ func main() {
 		},
 	}
 
+	// Polish the most detailed sphere so it shows highlights from the lights.
+	shiny := material.New()
+	shiny.Specular = &color.NRGBA{255, 255, 255, 255}
+	shiny.Shininess = 64
+	models[4].Material = shiny
+
+	// Draw the least detailed sphere with a custom cartoon style shader, which lights it in a few flat bands.
+	toon, err := shader.Register("toon", toonVertexSource, toonFragmentSource)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := toon.Set("uBands", float32(3)); err != nil {
+		log.Fatal(err)
+	}
+	models[0].Program = toon
+
+	// Replace the default light with a sun that casts the meshes' shadows onto a floor behind them.
+	light.Lights.Clear()
+	sun := light.NewDirectional(mgl32.Vec3{-0.5, 0.5, -1}, &color.NRGBA{170, 170, 170, 255})
+	sun.Shadow = light.NewShadow(1024, 3)
+	sun.Shadow.MaxDistance = 3000
+	light.Lights.Add(sun)
+	floor := &model.Model{
+		Tag:  "Floor",
+		Mesh: mesh.NewRect(&color.NRGBA{200, 200, 200, 255}, gl.Texture{}),
+		Entity: entity.Entity{
+			Position: mgl32.Vec3{0, 0, -200},
+			Rotation: mgl32.QuatIdent(),
+			Scale:    mgl32.Vec3{4000, 4000, 1},
+		},
+	}
+	queue := model.NewQueue()
+
+	// Post processing effects. Each is toggled by a number key, in the order they're listed.
+	warmth := postprocess.IdentityLUT(16)
+	for i := 0; i < len(warmth.Pix); i += 4 {
+		warmth.Pix[i+2] = uint8(float32(warmth.Pix[i+2]) * 0.8) // Less blue.
+	}
+	lut, lutSize, err := postprocess.LoadLUT(warmth)
+	if err != nil {
+		log.Fatal(err)
+	}
+	colorGrade := postprocess.NewColorGrade(lut, lutSize)
+	colorGrade.Disabled = true
+	blur := postprocess.NewBlur(4)
+	blur.Disabled = true
+	motionBlur := postprocess.NewMotionBlur(0.8)
+	motionBlur.Disabled = true
+	fw, fh := view.Window.GetFramebufferSize()
+	post, err := postprocess.New(fw, fh, postprocess.NewBloom(), colorGrade, blur, motionBlur, postprocess.NewVignette(), postprocess.NewFXAA())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer post.Delete()
+
 	// Adjust model positions so they're spaced nicely by making them into roughly a square layout that's centered at the origin.
 	dimensions := float32(math.Ceil(math.Sqrt(float64(len(models)))))
 	cellSize := float32(200)
@@ -258,12 +356,22 @@ func main() {
 	// Player is an empty model. It has no mesh so it can't be rendered, but it can still exist in the world.
 	player := &model.Model{}
 	player.Position[0] = 0
-	cam := camera.NewTargetCamera(player, mgl32.Vec3{0, 0, 1000})
-	cam.Zoomer = zoom.NewScrollZoom(0.1, 3,
-		func() float32 {
-			return mouse.Handler.Scroll().Y()
-		},
-	)
+	var cam camera.CameraI
+	if *arcball {
+		arcballCam := camera.NewArcballCamera(mgl32.Vec3{}, 1000)
+		arcballCam.PickPoint = func(origin, direction mgl32.Vec3) (mgl32.Vec3, bool) {
+			return pickModel(models, origin, direction)
+		}
+		cam = arcballCam
+	} else {
+		targetCam := camera.NewTargetCamera(player, mgl32.Vec3{0, 0, 1000})
+		targetCam.Zoomer = zoom.NewSmoothZoom(0.1, 3,
+			func() float32 {
+				return mouse.Handler.Scroll().Y()
+			},
+		)
+		cam = targetCam
+	}
 
 	rotationPerSecond := mgl32.AnglesToQuat(float32(math.Pi/4), float32(math.Pi/4), float32(math.Pi/4), mgl32.XYZ)
 
@@ -274,7 +382,10 @@ func main() {
 		keyboard.Handler.Update()
 		mouse.Handler.Update()
 
-		ApplyInputs(player)
+		if !*arcball {
+			ApplyInputs(player)
+		}
+		toggleEffects(post)
 
 		// Update the rotation.
 		for i := range models {
@@ -285,15 +396,29 @@ func main() {
 		mvMatrix := cam.ModelView()
 		w, h := view.Window.GetSize()
 		pMatrix := cam.ProjectionPerspective(float32(w), float32(h))
+		queue.Submit(0, models...)
+
+		// Draw the shadow map before the scene, since it uses its own matrices.
+		if err := sun.RenderShadows(pMatrix, mvMatrix, func() { queue.Render(mvMatrix) }); err != nil {
+			log.Printf("Disabling shadows: %v", err)
+			sun.Shadow = nil
+		}
 		shader.Model.SetMVPMatrix(pMatrix, mvMatrix)
 
 		cam.Update(fps.Handler.DeltaTime())
+		// Draw the scene into the post processing pipeline, which then draws it to the window.
+		if err := post.Resize(view.Window.GetFramebufferSize()); err != nil {
+			log.Fatal(err)
+		}
+		post.Begin()
 		// Clear screen, then Draw everything
 		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 		model.RenderXYZAxes()
 
-		for _, m := range models {
-			m.Render()
+		queue.Submit(0, floor)
+		queue.Flush(mvMatrix)
+		if err := post.End(); err != nil {
+			log.Fatal(err)
 		}
 
 		// Swaps the buffer that was drawn on to be visible. The visible buffer becomes the one that gets drawn on until it's swapped again.
@@ -302,6 +427,18 @@ func main() {
 	}
 }
 
+// toggleEffects turns post processing effects on and off with the number keys.
+func toggleEffects(post *postprocess.Pipeline) {
+	keys := []glfw.Key{glfw.Key1, glfw.Key2, glfw.Key3, glfw.Key4, glfw.Key5, glfw.Key6, glfw.Key7, glfw.Key8, glfw.Key9}
+	for i, e := range post.Effects {
+		if i >= len(keys) || !keyboard.Handler.JustPressed(keys[i]) {
+			continue
+		}
+		e.SetActive(!e.Active())
+		fmt.Printf("%T active: %v\n", e, e.Active())
+	}
+}
+
 func ApplyInputs(target *model.Model) {
 	var move mgl32.Vec2
 	if keyboard.Handler.IsKeyDown(glfw.KeyA, glfw.KeyLeft) {
@@ -331,3 +468,22 @@ func ApplyInputs(target *model.Model) {
 		target.ModifyPosition(move[0], move[1], 0)
 	}
 }
+
+// pickModel returns the center of the closest model that the ray hits. Each model is treated as a sphere that
+// surrounds its unit sized mesh.
+func pickModel(models []*model.Model, origin, direction mgl32.Vec3) (mgl32.Vec3, bool) {
+	var closest mgl32.Vec3
+	closestDist := float32(math.Inf(1))
+	for _, m := range models {
+		radius := m.Scale.Len() / 2
+		toCenter := m.Position.Sub(origin)
+		along := toCenter.Dot(direction)
+		if along < 0 || toCenter.Len() > closestDist {
+			continue
+		}
+		if toCenter.Sub(direction.Mul(along)).Len() <= radius {
+			closest, closestDist = m.Position, toCenter.Len()
+		}
+	}
+	return closest, !math.IsInf(float64(closestDist), 1)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/omustardo/gome/input/keyboard"
 	"github.com/omustardo/gome/input/mouse"
 	"github.com/omustardo/gome/model"
+	"github.com/omustardo/gome/model/material"
 	"github.com/omustardo/gome/model/mesh"
 	"github.com/omustardo/gome/shader"
 	"github.com/omustardo/gome/util/fps"
@@ -41,8 +42,6 @@ func main() {
 	terminate := gome.Initialize("Text Demo", *windowWidth, *windowHeight, *baseDir)
 	defer terminate()
 
-	shader.Model.SetAmbientLight(&color.NRGBA{255, 255, 255, 0})
-
 	target := &model.Model{
 		Mesh: mesh.NewRect(&color.NRGBA{255, 255, 255, 255}, generateTextTexture()),
 		Entity: entity.Entity{
@@ -51,6 +50,8 @@ func main() {
 			Rotation: mgl32.QuatIdent(),
 		},
 	}
+	// Text should look the same regardless of the lights in the scene.
+	target.Material = material.NewUnlit()
 
 	cam := camera.NewTargetCamera(target, mgl32.Vec3{0, 0, 500})
 
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"flag"
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/goxjs/glfw"
+	"github.com/omustardo/gome"
+	"github.com/omustardo/gome/camera"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/input/keyboard"
+	"github.com/omustardo/gome/input/mouse"
+	"github.com/omustardo/gome/model"
+	"github.com/omustardo/gome/model/mesh"
+	"github.com/omustardo/gome/shader"
+	"github.com/omustardo/gome/util/fps"
+	"github.com/omustardo/gome/view"
+	"github.com/omustardo/gome/view/viewport"
+)
+
+var (
+	windowWidth  = flag.Int("window_width", 1600, "initial window width")
+	windowHeight = flag.Int("window_height", 800, "initial window height")
+)
+
+const playerSpeed = 500 // units per second
+
+func main() {
+	flag.Parse()
+	terminate := gome.Initialize("Split Screen", *windowWidth, *windowHeight, "")
+	defer terminate()
+
+	newCube := func(col *color.NRGBA, position mgl32.Vec3, scale float32) *model.Model {
+		return &model.Model{
+			Mesh: mesh.NewCube(col, gl.Texture{}),
+			Entity: entity.Entity{
+				Position: position,
+				Scale:    mgl32.Vec3{scale, scale, scale},
+				Rotation: mgl32.QuatIdent(),
+			},
+		}
+	}
+
+	// A grid of small cubes on the ground, so it's possible to see the players move.
+	var ground []*model.Model
+	for x := -10; x <= 10; x++ {
+		for y := -10; y <= 10; y++ {
+			shade := uint8(100 + 5*(x+10))
+			ground = append(ground, newCube(&color.NRGBA{shade, 100, 255 - shade, 255}, mgl32.Vec3{float32(x) * 200, float32(y) * 200, -50}, 20))
+		}
+	}
+	player1 := newCube(&color.NRGBA{255, 50, 50, 255}, mgl32.Vec3{-300, 0, 0}, 60)
+	player2 := newCube(&color.NRGBA{50, 255, 50, 255}, mgl32.Vec3{300, 0, 0}, 60)
+
+	// Each player's camera follows them, but stops at the edges of the grid so it never shows the empty space past it.
+	level := camera.RectBounds{Min: mgl32.Vec2{-2100, -2100}, Max: mgl32.Vec2{2100, 2100}}
+	cam1 := camera.NewBoundedCamera(camera.NewTargetCamera(player1, mgl32.Vec3{0, -300, 800}), level, camera.Perspective)
+	cam2 := camera.NewBoundedCamera(camera.NewTargetCamera(player2, mgl32.Vec3{0, -300, 800}), level, camera.Perspective)
+
+	// Each player gets half of the window, and the minimap is drawn on top of both of them.
+	layout := viewport.SplitScreen(cam1, cam2)
+	layout.Get("player1").ClearColor = mgl32.Vec4{0.15, 0.05, 0.05, 1}
+	layout.Get("player2").ClearColor = mgl32.Vec4{0.05, 0.15, 0.05, 1}
+	// The cameras are only as wide as their viewports, so they can get closer to the sides of the level than
+	// a camera that covers the whole window.
+	for _, v := range layout {
+		v := v
+		v.Camera.(*camera.BoundedCamera).GetWindowSize = func() (float32, float32) {
+			size := v.Size(camera.CurrentWindowSize())
+			return size.Width, size.Height
+		}
+	}
+
+	overview := camera.NewCamera()
+	overview.Position = mgl32.Vec3{0, 0, 5000}
+	minimap := viewport.New("minimap", overview, viewport.Rect{X: 0.4, Y: 0.02, Width: 0.2, Height: 0.3}, viewport.Normalized)
+	minimap.ClearColor = mgl32.Vec4{0.1, 0.1, 0.1, 1}
+	layout = append(layout, minimap)
+
+	ticker := time.NewTicker(time.Second / 60)
+	for !view.Window.ShouldClose() {
+		fps.Handler.Update()
+		glfw.PollEvents() // Reads window events, like keyboard and mouse input.
+		// Handler.Update takes current input and stores it. This is necessary to detect things like the start of a keypress.
+		keyboard.Handler.Update()
+		mouse.Handler.Update()
+
+		move := func(m *model.Model, up, down, left, right glfw.Key) {
+			var dir mgl32.Vec3
+			if keyboard.Handler.IsKeyDown(up) {
+				dir[1]++
+			}
+			if keyboard.Handler.IsKeyDown(down) {
+				dir[1]--
+			}
+			if keyboard.Handler.IsKeyDown(left) {
+				dir[0]--
+			}
+			if keyboard.Handler.IsKeyDown(right) {
+				dir[0]++
+			}
+			if dir.Len() > 0 {
+				m.Position = m.Position.Add(dir.Normalize().Mul(playerSpeed * fps.Handler.DeltaTimeSeconds()))
+			}
+		}
+		move(player1, glfw.KeyW, glfw.KeyS, glfw.KeyA, glfw.KeyD)
+		move(player2, glfw.KeyUp, glfw.KeyDown, glfw.KeyLeft, glfw.KeyRight)
+		if keyboard.Handler.JustPressed(glfw.KeyM) {
+			minimap.Hidden = !minimap.Hidden
+		}
+
+		// Spin the players so they're easy to pick out on the minimap.
+		spin := mgl32.QuatRotate(float32(math.Pi)*fps.Handler.DeltaTimeSeconds(), mgl32.Vec3{0, 0, 1})
+		player1.ModifyRotationGlobalQ(spin)
+		player2.ModifyRotationGlobalQ(spin)
+
+		for _, v := range layout {
+			v.Camera.Update(fps.Handler.DeltaTime())
+		}
+
+		// Clear the whole window, in case the viewports don't cover all of it.
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+		layout.Render(camera.CurrentWindowSize(), func(v *viewport.Viewport, pMatrix, mvMatrix mgl32.Mat4) {
+			shader.Model.SetMVPMatrix(pMatrix, mvMatrix)
+			for _, g := range ground {
+				g.Render()
+			}
+			player1.Render()
+			player2.Render()
+		})
+
+		// Swaps the buffer that was drawn on to be visible. The visible buffer becomes the one that gets drawn on until it's swapped again.
+		view.Window.SwapBuffers()
+		<-ticker.C // wait up to 1/60th of a second. This caps framerate to 60 FPS.
+	}
+}
@@ -287,8 +287,9 @@ func main() {
 			// log.Println(fps.Handler.DeltaTime(), "delta time")
 			// log.Println("zoom%:", cam.GetCurrentZoomPercent())
 
-			//w, h := view.Window.GetSize()
-			//log.Println("mouse screen->world:", mouse.Handler.Position(), cam.ScreenToWorldCoord2D(mouse.Handler.Position(), w, h))
+			//ground := camera.Plane{Normal: mgl32.Vec3{0, 0, 1}}
+			//clicked, _ := camera.UnprojectToPlane(cam, camera.Perspective, mouse.Handler.Position(), ground, camera.CurrentWindowSize())
+			//log.Println("mouse screen->world:", mouse.Handler.Position(), clicked)
 		default:
 		}
 
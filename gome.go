@@ -9,6 +9,8 @@ import (
 	"github.com/omustardo/gome/asset"
 	"github.com/omustardo/gome/input/keyboard"
 	"github.com/omustardo/gome/input/mouse"
+	"github.com/omustardo/gome/input/touch"
+	"github.com/omustardo/gome/light"
 	"github.com/omustardo/gome/model/mesh"
 	"github.com/omustardo/gome/shader"
 	"github.com/omustardo/gome/util/fps"
@@ -26,6 +28,8 @@ func Initialize(windowTitle string, windowWidth, windowHeight int, baseDir strin
 	log.SetOutput(os.Stdout)
 
 	asset.SetBaseDir(baseDir)
+	// Let shaders include asset files.
+	shader.IncludeLoader = asset.LoadFile
 
 	// Initialize gl constants and the glfw window. Note that this must be done before all other gl usage.
 	if view.Window != nil {
@@ -43,11 +47,14 @@ func Initialize(windowTitle string, windowWidth, windowHeight int, baseDir strin
 		log.Fatalf("gl error: %v", err)
 	}
 	shader.Model.SetAmbientLight(&color.NRGBA{60, 60, 60, 0}) // 3D objects don't look 3D in max lighting, so tone it down as a default.
-	shader.Model.SetDiffuse(mgl32.Vec3{0, 0, 1}, &color.NRGBA{150, 150, 150, 255})
+	// A light shining down the -Z axis, toward the usual 2D view. It lights surfaces facing +Z the same as the old
+	// default diffuse light did.
+	light.Lights.Add(light.NewDirectional(mgl32.Vec3{0, 0, -1}, &color.NRGBA{150, 150, 150, 255}))
 
 	// Initialize singletons.
 	mouse.Initialize(view.Window)
 	keyboard.Initialize(view.Window)
+	touch.Initialize(view.Window)
 	fps.Initialize()
 
 	// Load standard meshes (cubes, rectangles, etc). These depend on OpenGL buffers, which depend on having an OpenGL
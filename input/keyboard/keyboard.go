@@ -119,6 +119,12 @@ func (h *handler) Update() {
 	h.process(keyEvents)
 }
 
+// SimulateKey records a key event as if it came from the window. It's handled in the next call to Update.
+// This allows other sources of input, like on screen touch controls, to drive game logic written for the keyboard.
+func (h *handler) SimulateKey(key glfw.Key, action glfw.Action) {
+	h.keyEventList.Callback(nil, key, 0, action, 0)
+}
+
 // ====== Helper functions ======
 
 // IsKeyDown returns whether any of the provided keys are currently pressed.
@@ -6,7 +6,6 @@ package mouse
 import (
 	"fmt"
 	"log"
-	"math"
 
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/goxjs/glfw"
@@ -87,24 +86,6 @@ func (h *handler) scrollCallback(_ *glfw.Window, xoff, yoff float64) {
 	h.scrollBuffer[1] += float32(yoff)
 }
 
-// normalizeScrollDelta is an empirically "ok" solution to normalize scroll wheel values between different between OS's,
-// input hardware, and browsers. It uses a log function to get deltas to reasonably similar values while still maintaining
-// some difference so things like MacOS trackpad kinetic scrolling still works.
-// See https://github.com/goxjs/glfw/issues/10 for more information.
-func normalizeScrollDelta(delta float64) float64 {
-	switch {
-	// If delta is small, return as is. This is likely the case for kinetic trackpad scrolling events.
-	case math.Abs(delta) <= 1:
-		return delta
-	case delta > 0:
-		return math.Log10(delta)
-	case delta < 0:
-		return -math.Log10(-delta)
-	default:
-		return 0
-	}
-}
-
 func setState(state map[glfw.MouseButton]bool, button glfw.MouseButton, action glfw.Action) {
 	if state == nil {
 		log.Println("found nil mouse button state")
@@ -142,12 +123,18 @@ func (h *handler) LeftPressed() bool {
 func (h *handler) RightPressed() bool {
 	return h.buttons[glfw.MouseButtonRight]
 }
+func (h *handler) MiddlePressed() bool {
+	return h.buttons[glfw.MouseButtonMiddle]
+}
 func (h *handler) WasLeftPressed() bool {
 	return h.previousButtons[glfw.MouseButtonLeft]
 }
 func (h *handler) WasRightPressed() bool {
 	return h.previousButtons[glfw.MouseButtonRight]
 }
+func (h *handler) WasMiddlePressed() bool {
+	return h.previousButtons[glfw.MouseButtonMiddle]
+}
 
 // Position returns the screen coordinate where the mouse pointer is.
 // (0,0) is the top left of the drawable region (i.e. not including the title bar in a desktop environment).
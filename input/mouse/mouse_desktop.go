@@ -0,0 +1,24 @@
+// +build !js
+
+package mouse
+
+import "math"
+
+// normalizeScrollDelta is an empirically "ok" solution to normalize scroll wheel values between different OS's and
+// input hardware. Most mice report 1.0 per scroll wheel tick, but some drivers report larger values when scrolling
+// quickly. A log function brings those to reasonably similar values while still maintaining some difference so things
+// like MacOS trackpad kinetic scrolling still work.
+// See https://github.com/goxjs/glfw/issues/10 for more information.
+func normalizeScrollDelta(delta float64) float64 {
+	switch {
+	// If delta is small, return as is. This is likely the case for kinetic trackpad scrolling events.
+	case math.Abs(delta) <= 1:
+		return delta
+	case delta > 0:
+		return math.Log10(delta)
+	case delta < 0:
+		return -math.Log10(-delta)
+	default:
+		return 0
+	}
+}
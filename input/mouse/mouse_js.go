@@ -0,0 +1,15 @@
+// +build js
+
+package mouse
+
+import "math"
+
+// normalizeScrollDelta makes scroll wheel values in the browser match the 1.0 per tick reported on the desktop.
+// Browsers report the distance scrolled rather than ticks, in pixels, lines, or pages depending on the browser and
+// the OS, so a single tick can be anywhere from 1 to over 100. The direction is always right though, and a mouse wheel
+// sends one event per tick, so each event is limited to one tick. Trackpads send many small events, which are kept as
+// they are so scrolling stays smooth.
+// See https://github.com/goxjs/glfw/issues/10 for more information.
+func normalizeScrollDelta(delta float64) float64 {
+	return math.Max(-1, math.Min(1, delta))
+}
@@ -0,0 +1,338 @@
+package touch
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+var (
+	_ Recognizer = (*TapRecognizer)(nil)
+	_ Recognizer = (*LongPressRecognizer)(nil)
+	_ Recognizer = (*SwipeRecognizer)(nil)
+	_ Recognizer = (*PinchRecognizer)(nil)
+	_ Recognizer = (*RotateRecognizer)(nil)
+)
+
+// Recognizer detects a gesture from the state of all touches.
+type Recognizer interface {
+	// Update is called by the touch handler once per handler Update, with all touches sorted by ID.
+	Update(touches []Touch, now time.Time)
+}
+
+// singleTouch keeps track of whether the current gesture has only ever used one finger.
+// Single finger gestures shouldn't trigger when the last finger of a pinch is lifted.
+type singleTouch struct {
+	multiple bool
+}
+
+// update returns the touch being used for a single finger gesture, or false if there isn't exactly one.
+func (s *singleTouch) update(touches []Touch) (Touch, bool) {
+	if len(touches) == 0 {
+		s.multiple = false
+		return Touch{}, false
+	}
+	if len(touches) > 1 {
+		s.multiple = true
+	}
+	if s.multiple {
+		if len(touches) == 1 && !touches[0].Down() {
+			// The last finger is being lifted. The next touch starts a fresh gesture.
+			s.multiple = false
+		}
+		return Touch{}, false
+	}
+	return touches[0], true
+}
+
+// TapRecognizer detects a quick touch and release without much movement.
+type TapRecognizer struct {
+	// MaxDuration is the longest a finger can be down and still count as a tap.
+	MaxDuration time.Duration
+	// MaxMovement is how many pixels a finger can move from where it started and still count as a tap.
+	MaxMovement float32
+
+	single   singleTouch
+	tapped   bool
+	position mgl32.Vec2
+}
+
+func NewTapRecognizer() *TapRecognizer {
+	return &TapRecognizer{
+		MaxDuration: 250 * time.Millisecond,
+		MaxMovement: 20,
+	}
+}
+
+func (r *TapRecognizer) Update(touches []Touch, now time.Time) {
+	r.tapped = false
+	t, ok := r.single.update(touches)
+	if !ok || t.Phase != Ended {
+		return
+	}
+	if now.Sub(t.StartTime) <= r.MaxDuration && t.Position.Sub(t.StartPosition).Len() <= r.MaxMovement {
+		r.tapped = true
+		r.position = t.Position
+	}
+}
+
+// Tapped returns whether a tap finished in the last Update.
+func (r *TapRecognizer) Tapped() bool {
+	return r.tapped
+}
+
+// Position returns where the most recent tap occurred.
+func (r *TapRecognizer) Position() mgl32.Vec2 {
+	return r.position
+}
+
+// LongPressRecognizer detects a single finger held in place.
+type LongPressRecognizer struct {
+	// MinDuration is how long a finger must be down to count as a long press.
+	MinDuration time.Duration
+	// MaxMovement is how many pixels a finger can move from where it started and still count as a long press.
+	MaxMovement float32
+
+	single singleTouch
+	// failed is true if the current touch can no longer become a long press.
+	failed      bool
+	touchID     int
+	justPressed bool
+	held        bool
+	position    mgl32.Vec2
+}
+
+func NewLongPressRecognizer() *LongPressRecognizer {
+	return &LongPressRecognizer{
+		MinDuration: 500 * time.Millisecond,
+		MaxMovement: 20,
+	}
+}
+
+func (r *LongPressRecognizer) Update(touches []Touch, now time.Time) {
+	r.justPressed = false
+	t, ok := r.single.update(touches)
+	if !ok || !t.Down() {
+		r.held = false
+		return
+	}
+	if t.Phase == Began || t.ID != r.touchID {
+		r.touchID = t.ID
+		r.failed = false
+		r.held = false
+	}
+	if r.failed || r.held {
+		return
+	}
+	if t.Position.Sub(t.StartPosition).Len() > r.MaxMovement {
+		r.failed = true
+		return
+	}
+	if now.Sub(t.StartTime) >= r.MinDuration {
+		r.justPressed = true
+		r.held = true
+		r.position = t.Position
+	}
+}
+
+// JustPressed returns whether a long press was recognized in the last Update.
+func (r *LongPressRecognizer) JustPressed() bool {
+	return r.justPressed
+}
+
+// Held returns whether a recognized long press is still being held down.
+func (r *LongPressRecognizer) Held() bool {
+	return r.held
+}
+
+// Position returns where the most recent long press was recognized.
+func (r *LongPressRecognizer) Position() mgl32.Vec2 {
+	return r.position
+}
+
+// SwipeRecognizer detects a single finger quickly moved across the screen and released.
+type SwipeRecognizer struct {
+	// MinDistance is the fewest pixels a finger must travel to count as a swipe.
+	MinDistance float32
+	// MaxDuration is the longest a swipe can take.
+	MaxDuration time.Duration
+
+	single    singleTouch
+	swiped    bool
+	direction mgl32.Vec2
+	speed     float32
+}
+
+func NewSwipeRecognizer() *SwipeRecognizer {
+	return &SwipeRecognizer{
+		MinDistance: 50,
+		MaxDuration: 500 * time.Millisecond,
+	}
+}
+
+func (r *SwipeRecognizer) Update(touches []Touch, now time.Time) {
+	r.swiped = false
+	t, ok := r.single.update(touches)
+	if !ok || t.Phase != Ended {
+		return
+	}
+	move := t.Position.Sub(t.StartPosition)
+	duration := now.Sub(t.StartTime)
+	if move.Len() < r.MinDistance || duration > r.MaxDuration {
+		return
+	}
+	r.swiped = true
+	r.direction = move.Normalize()
+	if duration > 0 {
+		r.speed = move.Len() / float32(duration.Seconds())
+	} else {
+		r.speed = float32(math.Inf(1))
+	}
+}
+
+// Swiped returns whether a swipe finished in the last Update.
+func (r *SwipeRecognizer) Swiped() bool {
+	return r.swiped
+}
+
+// Direction returns a unit vector in the direction of the most recent swipe.
+// It's in screen coordinates, so a swipe toward the top of the screen has a negative Y value.
+func (r *SwipeRecognizer) Direction() mgl32.Vec2 {
+	return r.direction
+}
+
+// Speed returns the average speed of the most recent swipe in pixels per second.
+func (r *SwipeRecognizer) Speed() float32 {
+	return r.speed
+}
+
+// fingerPair keeps track of the two touches used for two finger gestures.
+// The two touches with the lowest IDs are used, so extra fingers are ignored.
+type fingerPair struct {
+	ids    [2]int
+	active bool
+}
+
+// update returns the two touches in use and whether they're the same two touches as the last update.
+// If ok is false, there isn't a valid pair and the gesture should end.
+func (p *fingerPair) update(touches []Touch) (a, b Touch, continued, ok bool) {
+	if len(touches) < 2 || !touches[0].Down() || !touches[1].Down() {
+		p.active = false
+		return Touch{}, Touch{}, false, false
+	}
+	a, b = touches[0], touches[1]
+	continued = p.active && p.ids == [2]int{a.ID, b.ID}
+	p.ids = [2]int{a.ID, b.ID}
+	p.active = true
+	return a, b, continued, true
+}
+
+// PinchRecognizer detects two fingers moving toward or away from each other.
+// To use pinching for camera zoom, see zoom.NewPinchZoom:
+//   zoomer := zoom.NewPinchZoom(0.25, 3, touch.Handler.Pinch.Scale)
+type PinchRecognizer struct {
+	pair fingerPair
+
+	startDistance, distance float32
+	scale                   float32
+	center                  mgl32.Vec2
+}
+
+func (r *PinchRecognizer) Update(touches []Touch, _ time.Time) {
+	r.scale = 1
+	a, b, continued, ok := r.pair.update(touches)
+	if !ok {
+		return
+	}
+	distance := b.Position.Sub(a.Position).Len()
+	r.center = a.Position.Add(b.Position).Mul(0.5)
+	if !continued {
+		r.startDistance = distance
+		r.distance = distance
+		return
+	}
+	if r.distance > 0 && distance > 0 {
+		r.scale = distance / r.distance
+	}
+	r.distance = distance
+}
+
+// Active returns whether two fingers are currently down.
+func (r *PinchRecognizer) Active() bool {
+	return r.pair.active
+}
+
+// Scale returns the ratio of the distance between the two fingers now, compared to the previous Update.
+// Values above 1 mean the fingers moved apart (zoom in) and values below 1 mean they moved together (zoom out).
+// It returns 1 if there's no pinch in progress.
+func (r *PinchRecognizer) Scale() float32 {
+	return r.scale
+}
+
+// TotalScale returns the ratio of the distance between the two fingers now, compared to when the pinch started.
+func (r *PinchRecognizer) TotalScale() float32 {
+	if !r.pair.active || r.startDistance <= 0 {
+		return 1
+	}
+	return r.distance / r.startDistance
+}
+
+// Center returns the point halfway between the two fingers.
+func (r *PinchRecognizer) Center() mgl32.Vec2 {
+	return r.center
+}
+
+// RotateRecognizer detects two fingers twisting around each other.
+type RotateRecognizer struct {
+	pair fingerPair
+
+	angle, totalAngle float32
+	// previous is the angle of the line between the two fingers in the last Update.
+	previous float32
+}
+
+func (r *RotateRecognizer) Update(touches []Touch, _ time.Time) {
+	r.angle = 0
+	a, b, continued, ok := r.pair.update(touches)
+	if !ok {
+		return
+	}
+	// Screen coordinates have Y pointing down, so negate it to get counter-clockwise as positive like a unit circle.
+	v := b.Position.Sub(a.Position)
+	current := float32(math.Atan2(float64(-v.Y()), float64(v.X())))
+	if !continued {
+		r.previous = current
+		r.totalAngle = 0
+		return
+	}
+	r.angle = wrapAngle(current - r.previous)
+	r.totalAngle += r.angle
+	r.previous = current
+}
+
+// Active returns whether two fingers are currently down.
+func (r *RotateRecognizer) Active() bool {
+	return r.pair.active
+}
+
+// Angle returns how many radians the fingers rotated in the last Update. Counter-clockwise is positive.
+func (r *RotateRecognizer) Angle() float32 {
+	return r.angle
+}
+
+// TotalAngle returns how many radians the fingers have rotated since the gesture started. Counter-clockwise is positive.
+func (r *RotateRecognizer) TotalAngle() float32 {
+	return r.totalAngle
+}
+
+// wrapAngle returns the equivalent angle in the range [-Pi, Pi].
+func wrapAngle(a float32) float32 {
+	for a > math.Pi {
+		a -= 2 * math.Pi
+	}
+	for a < -math.Pi {
+		a += 2 * math.Pi
+	}
+	return a
+}
@@ -0,0 +1,258 @@
+// touch handles touch screen interaction, like on phones and tablets.
+// Touch events are only available in the web build. On desktop, glfw doesn't provide touch events so the handler never
+// sees any touches unless they're simulated with Handler.Callback.
+// Sample usage:
+//   touch.Initialize(view.Window)
+//   for { // game loop
+//     touch.Handler.Update()
+//     if touch.Handler.Tap.Tapped() {
+//       fmt.Println("tapped at", touch.Handler.Tap.Position())
+//     }
+//   }
+package touch
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/glfw"
+)
+
+// Handler is the singleton touch handler. It should be initialized with touch.Initialize(), and then
+// all touch related input should be obtained though it.
+var Handler *handler
+
+// Initialize sets up the touch.Handler singleton.
+func Initialize(window *glfw.Window) {
+	if Handler != nil {
+		panic("touch.Handler already initialized")
+	}
+	if window == nil {
+		panic("window is nil")
+	}
+	fmt.Println("Initializing touch handler...")
+
+	Handler = newHandler(time.Now)
+	registerCallbacks(window, Handler)
+}
+
+// Phase describes where a touch is in its lifecycle.
+type Phase int
+
+const (
+	// Began means the finger touched the screen since the last Update.
+	Began Phase = iota
+	// Moved means the finger is down and changed position since the last Update.
+	Moved
+	// Stationary means the finger is down and hasn't moved since the last Update.
+	Stationary
+	// Ended means the finger was lifted since the last Update. The touch is removed on the following Update.
+	Ended
+	// Cancelled means the system interrupted the touch, for example if the browser took over the gesture or too many
+	// fingers were on the screen. The touch is removed on the following Update.
+	Cancelled
+)
+
+func (p Phase) String() string {
+	switch p {
+	case Began:
+		return "Began"
+	case Moved:
+		return "Moved"
+	case Stationary:
+		return "Stationary"
+	case Ended:
+		return "Ended"
+	case Cancelled:
+		return "Cancelled"
+	default:
+		return fmt.Sprintf("Phase(%d)", int(p))
+	}
+}
+
+// Touch is a single finger on the screen.
+// Positions are screen coordinates in the same space as mouse.Handler.Position():
+// (0,0) is the top left of the drawable region, and down and right are positive.
+type Touch struct {
+	// ID identifies the finger for as long as it's on the screen. IDs may be reused after a touch ends.
+	ID    int
+	Phase Phase
+
+	Position         mgl32.Vec2
+	PreviousPosition mgl32.Vec2
+
+	// StartPosition and StartTime are where and when the touch began.
+	StartPosition mgl32.Vec2
+	StartTime     time.Time
+}
+
+// Delta returns how far the touch moved in the last Update.
+func (t Touch) Delta() mgl32.Vec2 {
+	return t.Position.Sub(t.PreviousPosition)
+}
+
+// Down returns whether the finger is still touching the screen.
+func (t Touch) Down() bool {
+	return t.Phase != Ended && t.Phase != Cancelled
+}
+
+type touchEvent struct {
+	id       int
+	phase    Phase
+	position mgl32.Vec2
+}
+
+const eventListCap = 20 // expected max number of touch events between a single call to touch.Handler.Update()
+
+type touchEventList []touchEvent
+
+func newTouchEventList() *touchEventList {
+	eventList := touchEventList(make([]touchEvent, 0, eventListCap))
+	return &eventList
+}
+
+// freeze returns the list of touch events since it was last called and clears the internal buffer.
+func (eventList *touchEventList) freeze() []touchEvent {
+	frozen := *eventList
+	*eventList = make([]touchEvent, 0, eventListCap)
+	return frozen
+}
+
+// handler is the singleton member of the touch package. Create it using touch.Initialize()
+type handler struct {
+	// touches maps from touch ID to the state of that touch as of the last call to Update.
+	touches map[int]*Touch
+
+	// eventList is modified by the platform specific callbacks, or directly for testing, to keep track of touch
+	// events between calls to handler.Update()
+	eventList *touchEventList
+
+	// timeGetter allows mocking of time.Now() for testing.
+	timeGetter func() time.Time
+
+	// Built in gesture recognizers. Their thresholds can be modified directly.
+	Tap       *TapRecognizer
+	LongPress *LongPressRecognizer
+	Swipe     *SwipeRecognizer
+	Pinch     *PinchRecognizer
+	Rotate    *RotateRecognizer
+
+	// recognizers are all gesture recognizers that are updated with the touch state, including the built in ones.
+	recognizers []Recognizer
+}
+
+func newHandler(timeGetter func() time.Time) *handler {
+	h := &handler{
+		touches:    make(map[int]*Touch),
+		eventList:  newTouchEventList(),
+		timeGetter: timeGetter,
+		Tap:        NewTapRecognizer(),
+		LongPress:  NewLongPressRecognizer(),
+		Swipe:      NewSwipeRecognizer(),
+		Pinch:      &PinchRecognizer{},
+		Rotate:     &RotateRecognizer{},
+	}
+	h.recognizers = []Recognizer{h.Tap, h.LongPress, h.Swipe, h.Pinch, h.Rotate}
+	return h
+}
+
+// Callback records a touch event to be handled in the next call to Update. It's used by the platform specific
+// event listeners, and can also be called directly to simulate touch events.
+// The phase should be one of Began, Moved, Ended, or Cancelled.
+func (h *handler) Callback(id int, phase Phase, x, y float64) {
+	*h.eventList = append(*h.eventList, touchEvent{id, phase, mgl32.Vec2{float32(x), float32(y)}})
+}
+
+// AddRecognizer adds a custom gesture recognizer. It is updated at the end of every call to Update, after
+// the built in recognizers.
+func (h *handler) AddRecognizer(r Recognizer) {
+	h.recognizers = append(h.recognizers, r)
+}
+
+// Update is expected to be called once per frame. It handles any touch events since it was last called and then
+// updates all gesture recognizers.
+func (h *handler) Update() {
+	now := h.timeGetter()
+
+	// Touches that ended in the previous update are removed. Everything else is stationary unless an event says otherwise.
+	for id, t := range h.touches {
+		if !t.Down() {
+			delete(h.touches, id)
+			continue
+		}
+		t.Phase = Stationary
+		t.PreviousPosition = t.Position
+	}
+
+	// Get a snapshot of touch events so incoming ones don't affect the processing.
+	for _, event := range h.eventList.freeze() {
+		h.process(event, now)
+	}
+
+	touches := h.Touches()
+	for _, r := range h.recognizers {
+		r.Update(touches, now)
+	}
+}
+
+func (h *handler) process(event touchEvent, now time.Time) {
+	if event.phase == Began {
+		h.touches[event.id] = &Touch{
+			ID:               event.id,
+			Phase:            Began,
+			Position:         event.position,
+			PreviousPosition: event.position,
+			StartPosition:    event.position,
+			StartTime:        now,
+		}
+		return
+	}
+	t, ok := h.touches[event.id]
+	if !ok {
+		// Most likely the touch began before the handler was initialized. There's nothing useful to do with it.
+		return
+	}
+	t.Position = event.position
+	switch event.phase {
+	case Moved:
+		// A touch that began in this update stays as Began so it isn't missed.
+		if t.Phase != Began {
+			t.Phase = Moved
+		}
+	case Ended, Cancelled:
+		t.Phase = event.phase
+	}
+}
+
+// Touches returns all touches known as of the last call to Update, sorted by ID.
+// This includes touches that ended since the previous Update so their final position can be read.
+func (h *handler) Touches() []Touch {
+	touches := make([]Touch, 0, len(h.touches))
+	for _, t := range h.touches {
+		touches = append(touches, *t)
+	}
+	sort.Slice(touches, func(i, j int) bool { return touches[i].ID < touches[j].ID })
+	return touches
+}
+
+// Touch returns the touch with the given ID, if it exists.
+func (h *handler) Touch(id int) (Touch, bool) {
+	t, ok := h.touches[id]
+	if !ok {
+		return Touch{}, false
+	}
+	return *t, true
+}
+
+// Count returns the number of fingers currently on the screen.
+func (h *handler) Count() int {
+	count := 0
+	for _, t := range h.touches {
+		if t.Down() {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,9 @@
+// +build !js
+
+package touch
+
+import "github.com/goxjs/glfw"
+
+// registerCallbacks does nothing on desktop since glfw doesn't provide touch events.
+// Touches can still be simulated by calling Handler.Callback directly.
+func registerCallbacks(_ *glfw.Window, _ *handler) {}
@@ -0,0 +1,36 @@
+// +build js
+
+package touch
+
+import (
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/goxjs/glfw"
+)
+
+// registerCallbacks listens for touch events on the web page. goxjs/glfw doesn't expose touch events,
+// so they're read directly from the DOM.
+func registerCallbacks(_ *glfw.Window, h *handler) {
+	document := js.Global.Get("document")
+	listen := func(eventType string, phase Phase) {
+		document.Call("addEventListener", eventType, func(event *js.Object) {
+			// Stop the browser from scrolling or zooming the page, and from emulating mouse events for the touch.
+			event.Call("preventDefault")
+
+			// Scale by the pixel ratio to match the mouse coordinates reported by goxjs/glfw.
+			ratio := 1.0
+			if r := js.Global.Get("devicePixelRatio"); r != js.Undefined {
+				ratio = r.Float()
+			}
+			// changedTouches contains only the touches that this event is about.
+			touches := event.Get("changedTouches")
+			for i := 0; i < touches.Length(); i++ {
+				t := touches.Index(i)
+				h.Callback(t.Get("identifier").Int(), phase, t.Get("clientX").Float()*ratio, t.Get("clientY").Float()*ratio)
+			}
+		}, js.M{"passive": false}) // Listeners must be explicitly non-passive for preventDefault to work on touch events.
+	}
+	listen("touchstart", Began)
+	listen("touchmove", Moved)
+	listen("touchend", Ended)
+	listen("touchcancel", Cancelled)
+}
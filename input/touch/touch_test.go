@@ -0,0 +1,176 @@
+package touch
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// mockTime is a clock that only moves when told to.
+type mockTime struct {
+	now time.Time
+}
+
+func (m *mockTime) Now() time.Time {
+	return m.now
+}
+
+func (m *mockTime) Advance(d time.Duration) {
+	m.now = m.now.Add(d)
+}
+
+func newTestHandler() (*handler, *mockTime) {
+	clock := &mockTime{now: time.Unix(1000, 0)}
+	return newHandler(clock.Now), clock
+}
+
+func TestPhases(t *testing.T) {
+	h, _ := newTestHandler()
+
+	h.Callback(1, Began, 10, 10)
+	h.Update()
+	if got, ok := h.Touch(1); !ok || got.Phase != Began {
+		t.Fatalf("after touch start, got %v (exists: %v), want phase %v", got.Phase, ok, Began)
+	}
+
+	h.Update()
+	if got, _ := h.Touch(1); got.Phase != Stationary {
+		t.Errorf("after no events, got phase %v, want %v", got.Phase, Stationary)
+	}
+
+	h.Callback(1, Moved, 15, 10)
+	h.Update()
+	got, _ := h.Touch(1)
+	if got.Phase != Moved {
+		t.Errorf("after touch move, got phase %v, want %v", got.Phase, Moved)
+	}
+	if want := (mgl32.Vec2{5, 0}); got.Delta() != want {
+		t.Errorf("after touch move, got delta %v, want %v", got.Delta(), want)
+	}
+	if h.Count() != 1 {
+		t.Errorf("got %d touches down, want 1", h.Count())
+	}
+
+	h.Callback(1, Ended, 15, 10)
+	h.Update()
+	if got, ok := h.Touch(1); !ok || got.Phase != Ended {
+		t.Errorf("after touch end, got %v (exists: %v), want phase %v", got.Phase, ok, Ended)
+	}
+	if h.Count() != 0 {
+		t.Errorf("got %d touches down, want 0", h.Count())
+	}
+
+	h.Update()
+	if _, ok := h.Touch(1); ok {
+		t.Errorf("ended touch wasn't removed")
+	}
+}
+
+func TestTap(t *testing.T) {
+	tests := []struct {
+		name     string
+		hold     time.Duration
+		move     float64
+		wantTap  bool
+		wantLong bool
+	}{
+		{name: "quick tap", hold: 100 * time.Millisecond, move: 0, wantTap: true},
+		{name: "moved too far", hold: 100 * time.Millisecond, move: 100, wantTap: false},
+		{name: "held too long", hold: time.Second, move: 0, wantTap: false, wantLong: true},
+	}
+	for _, tt := range tests {
+		h, clock := newTestHandler()
+		gotLong := false
+
+		h.Callback(1, Began, 50, 50)
+		h.Update()
+		for elapsed := time.Duration(0); elapsed < tt.hold; elapsed += 50 * time.Millisecond {
+			clock.Advance(50 * time.Millisecond)
+			h.Update()
+			gotLong = gotLong || h.LongPress.JustPressed()
+		}
+		h.Callback(1, Ended, 50+tt.move, 50)
+		h.Update()
+
+		if got := h.Tap.Tapped(); got != tt.wantTap {
+			t.Errorf("%s: got tapped %v, want %v", tt.name, got, tt.wantTap)
+		}
+		if gotLong != tt.wantLong {
+			t.Errorf("%s: got long press %v, want %v", tt.name, gotLong, tt.wantLong)
+		}
+	}
+}
+
+func TestSwipe(t *testing.T) {
+	h, clock := newTestHandler()
+	h.Callback(1, Began, 100, 100)
+	h.Update()
+	clock.Advance(100 * time.Millisecond)
+	h.Callback(1, Moved, 100, 50)
+	h.Update()
+	clock.Advance(100 * time.Millisecond)
+	h.Callback(1, Ended, 100, 0)
+	h.Update()
+
+	if !h.Swipe.Swiped() {
+		t.Fatal("swipe not recognized")
+	}
+	if want := (mgl32.Vec2{0, -1}); !h.Swipe.Direction().ApproxEqual(want) {
+		t.Errorf("got swipe direction %v, want %v", h.Swipe.Direction(), want)
+	}
+	if want := float32(500); !mgl32.FloatEqual(h.Swipe.Speed(), want) {
+		t.Errorf("got swipe speed %v, want %v", h.Swipe.Speed(), want)
+	}
+	if h.Tap.Tapped() {
+		t.Error("swipe was also recognized as a tap")
+	}
+}
+
+func TestPinchAndRotate(t *testing.T) {
+	h, _ := newTestHandler()
+	h.Callback(1, Began, 100, 100)
+	h.Callback(2, Began, 200, 100)
+	h.Update()
+	if !h.Pinch.Active() || h.Pinch.Scale() != 1 {
+		t.Errorf("at pinch start, got active=%v scale=%v, want active=true scale=1", h.Pinch.Active(), h.Pinch.Scale())
+	}
+
+	// Spread the fingers to twice the distance.
+	h.Callback(1, Moved, 50, 100)
+	h.Callback(2, Moved, 250, 100)
+	h.Update()
+	if got := h.Pinch.Scale(); !mgl32.FloatEqual(got, 2) {
+		t.Errorf("got pinch scale %v, want 2", got)
+	}
+	if got, want := h.Pinch.Center(), (mgl32.Vec2{150, 100}); got != want {
+		t.Errorf("got pinch center %v, want %v", got, want)
+	}
+
+	// Rotate the second finger a quarter turn counter-clockwise around the first as seen on screen.
+	// Up on the screen is toward negative Y.
+	h.Callback(2, Moved, 50, -100)
+	h.Update()
+	if got := h.Pinch.Scale(); !mgl32.FloatEqual(got, 1) {
+		t.Errorf("got pinch scale %v during pure rotation, want 1", got)
+	}
+	if got := h.Rotate.Angle(); !mgl32.FloatEqual(got, math.Pi/2) {
+		t.Errorf("got rotation %v, want %v", got, math.Pi/2)
+	}
+	if got := h.Pinch.TotalScale(); !mgl32.FloatEqual(got, 2) {
+		t.Errorf("got total pinch scale %v, want 2", got)
+	}
+
+	// Lifting the fingers one at a time ends the gesture and isn't a tap.
+	h.Callback(2, Ended, 50, -100)
+	h.Update()
+	if h.Pinch.Active() || h.Rotate.Active() {
+		t.Error("two finger gestures still active after a finger was lifted")
+	}
+	h.Callback(1, Ended, 50, 100)
+	h.Update()
+	if h.Tap.Tapped() {
+		t.Error("lifting the last finger of a pinch was recognized as a tap")
+	}
+}
@@ -0,0 +1,99 @@
+package virtual
+
+import (
+	"image/color"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/goxjs/glfw"
+	"github.com/omustardo/gome/input/touch"
+	"github.com/omustardo/gome/model"
+	"github.com/omustardo/gome/model/mesh"
+)
+
+// Button is a round on screen button. It holds down its Key for as long as a touch that started on it stays down,
+// even if the finger slides off of it.
+type Button struct {
+	// Center is where the button is drawn, as a fraction of the screen size from the top left corner.
+	Center mgl32.Vec2
+	// Radius is the size of the button as a fraction of the smaller screen dimension.
+	Radius float32
+
+	// Key is held down while the button is pressed. Set it to glfw.KeyUnknown to not send a key.
+	Key glfw.Key
+
+	// Color and PressedColor are optional colors for rendering. Leaving them nil uses translucent white.
+	Color, PressedColor *color.NRGBA
+
+	touchID int
+	active  bool
+	// pressed and previous are whether the button was pressed as of the last two calls to Update.
+	pressed, previous bool
+}
+
+// NewButton creates a button that sends the given key.
+func NewButton(center mgl32.Vec2, radius float32, key glfw.Key) *Button {
+	return &Button{
+		Center: center,
+		Radius: radius,
+		Key:    key,
+	}
+}
+
+// Pressed returns whether the button is currently held down.
+func (b *Button) Pressed() bool {
+	return b.pressed
+}
+
+// JustPressed returns whether the button was pressed in the last Update.
+func (b *Button) JustPressed() bool {
+	return b.pressed && !b.previous
+}
+
+func (b *Button) owner() (int, bool) {
+	return b.touchID, b.active
+}
+
+func (b *Button) claim(t touch.Touch, l layout) bool {
+	if b.active || t.Position.Sub(l.position(b.Center)).Len() > l.size(b.Radius) {
+		return false
+	}
+	b.touchID = t.ID
+	b.active = true
+	return true
+}
+
+func (b *Button) update(t *touch.Touch, _ layout) {
+	b.previous = b.pressed
+	b.pressed = t != nil && t.Down()
+	if !b.pressed {
+		b.active = false
+	}
+}
+
+func (b *Button) keys() []glfw.Key {
+	if b.pressed && b.Key != glfw.KeyUnknown {
+		return []glfw.Key{b.Key}
+	}
+	return nil
+}
+
+func (b *Button) render(l layout) {
+	col := b.Color
+	if b.pressed && b.PressedColor != nil {
+		col = b.PressedColor
+	}
+	if col == nil {
+		col = &color.NRGBA{255, 255, 255, 80}
+		if b.pressed {
+			col = &color.NRGBA{255, 255, 255, 160}
+		}
+	}
+	radius := l.size(b.Radius)
+	m := model.Model{Mesh: mesh.NewCircle(col, gl.Texture{})}
+	m.Material = unlit
+	m.Rotation = mgl32.QuatIdent()
+	m.Position = l.screenToRender(l.position(b.Center))
+	m.Scale = mgl32.Vec3{radius, radius, 1}
+	m.Render()
+}
@@ -0,0 +1,170 @@
+package virtual
+
+import (
+	"image/color"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/goxjs/glfw"
+	"github.com/omustardo/gome/input/touch"
+	"github.com/omustardo/gome/model"
+	"github.com/omustardo/gome/model/mesh"
+)
+
+// Joystick is an on screen thumbstick. Dragging the stick away from the center of its base produces a 2D axis.
+type Joystick struct {
+	// Center is where the base is drawn, as a fraction of the screen size from the top left corner.
+	// A floating joystick moves its base to wherever a touch starts, and returns to Center when released.
+	Center mgl32.Vec2
+	// Radius is how far the stick can move from the center of the base, as a fraction of the smaller screen dimension.
+	Radius float32
+
+	// Floating joysticks start wherever a touch begins within the region between RegionMin and RegionMax.
+	// Fixed joysticks only start when a touch begins within their base.
+	Floating bool
+	// RegionMin and RegionMax are the top left and bottom right corners of the area where a floating joystick can be
+	// started, as fractions of the screen size.
+	RegionMin, RegionMax mgl32.Vec2
+
+	// DeadZone is the fraction of Radius near the center that's ignored, so resting a thumb on the stick doesn't move.
+	DeadZone float32
+
+	// UpKey, DownKey, LeftKey, and RightKey are held down while the stick is pushed past KeyThreshold in that
+	// direction. Set any of them to glfw.KeyUnknown to not send a key for that direction.
+	UpKey, DownKey, LeftKey, RightKey glfw.Key
+	// KeyThreshold is how far the stick needs to be pushed in a direction, from 0 to 1, for it to count as a key press.
+	KeyThreshold float32
+
+	// BaseColor and StickColor are optional colors for rendering. Leaving them nil uses translucent white.
+	BaseColor, StickColor *color.NRGBA
+
+	touchID int
+	active  bool
+	// base and stick are the current pixel locations of the base and stick.
+	base, stick mgl32.Vec2
+	axis        mgl32.Vec2
+}
+
+// NewJoystick creates a fixed joystick that sends W,A,S,D key presses.
+func NewJoystick(center mgl32.Vec2, radius float32) *Joystick {
+	return &Joystick{
+		Center:       center,
+		Radius:       radius,
+		DeadZone:     0.1,
+		UpKey:        glfw.KeyW,
+		DownKey:      glfw.KeyS,
+		LeftKey:      glfw.KeyA,
+		RightKey:     glfw.KeyD,
+		KeyThreshold: 0.5,
+	}
+}
+
+// NewFloatingJoystick creates a floating joystick that sends W,A,S,D key presses. It starts wherever a touch begins
+// on the left half of the screen.
+func NewFloatingJoystick(center mgl32.Vec2, radius float32) *Joystick {
+	j := NewJoystick(center, radius)
+	j.Floating = true
+	j.RegionMin = mgl32.Vec2{0, 0}
+	j.RegionMax = mgl32.Vec2{0.5, 1}
+	return j
+}
+
+// Axis returns how far the stick is pushed. X is positive to the right and Y is positive up, like the default
+// camera orientation. The length is never more than 1.
+func (j *Joystick) Axis() mgl32.Vec2 {
+	return j.axis
+}
+
+// Active returns whether a touch is currently using the joystick.
+func (j *Joystick) Active() bool {
+	return j.active
+}
+
+func (j *Joystick) owner() (int, bool) {
+	return j.touchID, j.active
+}
+
+func (j *Joystick) claim(t touch.Touch, l layout) bool {
+	if j.active {
+		return false
+	}
+	pos := t.Position
+	if j.Floating {
+		min, max := l.position(j.RegionMin), l.position(j.RegionMax)
+		if pos.X() < min.X() || pos.X() > max.X() || pos.Y() < min.Y() || pos.Y() > max.Y() {
+			return false
+		}
+		j.base = pos
+	} else {
+		if pos.Sub(l.position(j.Center)).Len() > l.size(j.Radius) {
+			return false
+		}
+		j.base = l.position(j.Center)
+	}
+	j.touchID = t.ID
+	j.active = true
+	return true
+}
+
+func (j *Joystick) update(t *touch.Touch, l layout) {
+	if t == nil || !t.Down() {
+		j.active = false
+		j.axis = mgl32.Vec2{}
+		return
+	}
+	radius := l.size(j.Radius)
+	offset := t.Position.Sub(j.base)
+	if offset.Len() > radius {
+		offset = offset.Normalize().Mul(radius)
+	}
+	j.stick = j.base.Add(offset)
+
+	// Convert to a unit range, flipping Y so up is positive.
+	axis := mgl32.Vec2{offset.X() / radius, -offset.Y() / radius}
+	if axis.Len() <= j.DeadZone {
+		axis = mgl32.Vec2{}
+	}
+	j.axis = axis
+}
+
+func (j *Joystick) keys() []glfw.Key {
+	var keys []glfw.Key
+	add := func(key glfw.Key, pressed bool) {
+		if pressed && key != glfw.KeyUnknown {
+			keys = append(keys, key)
+		}
+	}
+	add(j.UpKey, j.axis.Y() >= j.KeyThreshold)
+	add(j.DownKey, j.axis.Y() <= -j.KeyThreshold)
+	add(j.LeftKey, j.axis.X() <= -j.KeyThreshold)
+	add(j.RightKey, j.axis.X() >= j.KeyThreshold)
+	return keys
+}
+
+func (j *Joystick) render(l layout) {
+	base, stick := l.position(j.Center), l.position(j.Center)
+	if j.active {
+		base, stick = j.base, j.stick
+	}
+	radius := l.size(j.Radius)
+
+	baseColor, stickColor := j.BaseColor, j.StickColor
+	if baseColor == nil {
+		baseColor = &color.NRGBA{255, 255, 255, 60}
+	}
+	if stickColor == nil {
+		stickColor = &color.NRGBA{255, 255, 255, 120}
+	}
+	m := model.Model{Mesh: mesh.NewCircle(baseColor, gl.Texture{})}
+	m.Material = unlit
+	m.Rotation = mgl32.QuatIdent()
+	m.Position = l.screenToRender(base)
+	m.Scale = mgl32.Vec3{radius, radius, 1}
+	m.Render()
+
+	m.Mesh = mesh.NewCircle(stickColor, gl.Texture{})
+	m.Material = unlit
+	m.Position = l.screenToRender(stick)
+	m.Scale = mgl32.Vec3{radius / 2, radius / 2, 1}
+	m.Render()
+}
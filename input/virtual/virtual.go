@@ -0,0 +1,195 @@
+// virtual provides on screen controls for touch devices, like a thumbstick and buttons.
+// The controls turn touches into the same key presses that a keyboard would send, so game logic written for the
+// keyboard package works unchanged on phones and tablets. The thumbstick also provides an analog axis.
+//
+// Layout is resolution independent. Positions are fractions of the screen size, measured from the top left corner,
+// and sizes are fractions of the smaller screen dimension so controls stay round and reachable in both portrait and
+// landscape.
+//
+// Sample usage:
+//   controls := virtual.NewControls()
+//   stick := virtual.NewJoystick(mgl32.Vec2{0.2, 0.8}, 0.12) // Sends W,A,S,D by default.
+//   fire := virtual.NewButton(mgl32.Vec2{0.85, 0.8}, 0.08, glfw.KeySpace)
+//   controls.Joysticks = append(controls.Joysticks, stick)
+//   controls.Buttons = append(controls.Buttons, fire)
+//   for { // game loop
+//     glfw.PollEvents()
+//     touch.Handler.Update()
+//     w, h := view.Window.GetSize()
+//     controls.Update(touch.Handler.Touches(), float32(w), float32(h)) // Must be before keyboard.Handler.Update()
+//     keyboard.Handler.Update()
+//     // Game logic and rendering...
+//     controls.Render(float32(w), float32(h)) // Render last so the controls are on top.
+//   }
+package virtual
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/goxjs/glfw"
+	"github.com/omustardo/gome/input/keyboard"
+	"github.com/omustardo/gome/input/touch"
+	"github.com/omustardo/gome/model/material"
+	"github.com/omustardo/gome/shader"
+)
+
+// Controls is a set of on screen controls that share the touches on the screen.
+// Each touch belongs to at most one control, from when it begins until it ends, so a joystick and a button can
+// be held at the same time.
+type Controls struct {
+	Joysticks []*Joystick
+	Buttons   []*Button
+
+	// Hidden determines whether the controls are rendered. They still handle touches while hidden.
+	Hidden bool
+
+	// SendKey is called whenever a control presses or releases a key.
+	// NewControls sets it to send the key to keyboard.Handler.
+	SendKey func(key glfw.Key, action glfw.Action)
+}
+
+// NewControls creates an empty set of controls that send key presses to keyboard.Handler.
+// keyboard.Initialize must be called before the controls are updated.
+func NewControls() *Controls {
+	return &Controls{
+		SendKey: func(key glfw.Key, action glfw.Action) {
+			keyboard.Handler.SimulateKey(key, action)
+		},
+	}
+}
+
+// control is the shared behavior of all on screen controls.
+type control interface {
+	// claim offers a touch that just began to the control. It returns whether the control took the touch.
+	claim(t touch.Touch, l layout) bool
+	// owner returns the ID of the touch that the control is using, and false if it isn't using one.
+	owner() (id int, ok bool)
+	// update provides the control with its current touch, or nil if it has no touch.
+	update(t *touch.Touch, l layout)
+	// keys returns the keys that the control is currently holding down.
+	keys() []glfw.Key
+}
+
+// Update assigns new touches to controls and updates the state of every control.
+// Width and height are the size of the screen in the same units as touch positions, usually view.Window.GetSize().
+// It must be called before keyboard.Handler.Update() so key presses take effect in the same frame.
+func (c *Controls) Update(touches []touch.Touch, width, height float32) {
+	l := layout{width, height}
+	controls := c.controls()
+	before := make([][]glfw.Key, len(controls))
+	for i, ctrl := range controls {
+		before[i] = ctrl.keys()
+	}
+
+	// Hand out new touches. Buttons are checked first since they're small and might overlap a floating joystick's region.
+	for i := range touches {
+		t := &touches[i]
+		if t.Phase != touch.Began {
+			continue
+		}
+		for _, ctrl := range controls {
+			if ctrl.claim(*t, l) {
+				break
+			}
+		}
+	}
+
+	byID := make(map[int]*touch.Touch, len(touches))
+	for i := range touches {
+		byID[touches[i].ID] = &touches[i]
+	}
+	for i, ctrl := range controls {
+		var t *touch.Touch
+		if id, ok := ctrl.owner(); ok {
+			t = byID[id] // nil if the touch is gone.
+		}
+		ctrl.update(t, l)
+		c.sendKeyChanges(before[i], ctrl.keys())
+	}
+}
+
+func (c *Controls) controls() []control {
+	controls := make([]control, 0, len(c.Buttons)+len(c.Joysticks))
+	for _, b := range c.Buttons {
+		controls = append(controls, b)
+	}
+	for _, j := range c.Joysticks {
+		controls = append(controls, j)
+	}
+	return controls
+}
+
+// sendKeyChanges presses keys that are newly held and releases keys that no longer are.
+func (c *Controls) sendKeyChanges(before, after []glfw.Key) {
+	if c.SendKey == nil {
+		return
+	}
+	for _, k := range before {
+		if !containsKey(after, k) {
+			c.SendKey(k, glfw.Release)
+		}
+	}
+	for _, k := range after {
+		if !containsKey(before, k) {
+			c.SendKey(k, glfw.Press)
+		}
+	}
+}
+
+func containsKey(keys []glfw.Key, key glfw.Key) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Render draws the controls on top of everything else.
+// Width and height must be the same values passed to Update.
+// Note that this changes the model shader's MVP matrix, so it should be called after the rest of the scene is drawn.
+func (c *Controls) Render(width, height float32) {
+	if c.Hidden {
+		return
+	}
+	l := layout{width, height}
+
+	// Draw in screen pixels with the origin in the bottom left. Controls are flat, so disable depth testing to keep them
+	// on top of the scene. They use an unlit material so their colors aren't darkened by the scene lighting.
+	shader.Model.SetMVPMatrix(mgl32.Ortho(0, width, 0, height, -1, 1), mgl32.Ident4())
+	gl.Disable(gl.DEPTH_TEST)
+	defer gl.Enable(gl.DEPTH_TEST)
+
+	for _, j := range c.Joysticks {
+		j.render(l)
+	}
+	for _, b := range c.Buttons {
+		b.render(l)
+	}
+}
+
+// unlit is the material used for all controls, so they aren't affected by the scene's lights.
+var unlit = material.NewUnlit()
+
+// layout converts resolution independent sizes into pixels.
+type layout struct {
+	width, height float32
+}
+
+// position converts a fraction of the screen size into pixels.
+func (l layout) position(fraction mgl32.Vec2) mgl32.Vec2 {
+	return mgl32.Vec2{fraction.X() * l.width, fraction.Y() * l.height}
+}
+
+// size converts a fraction of the smaller screen dimension into pixels.
+func (l layout) size(fraction float32) float32 {
+	if l.width < l.height {
+		return fraction * l.width
+	}
+	return fraction * l.height
+}
+
+// screenToRender converts a touch position, which has Y pointing down, into the Y up space used by Render.
+func (l layout) screenToRender(pos mgl32.Vec2) mgl32.Vec3 {
+	return mgl32.Vec3{pos.X(), l.height - pos.Y(), 0}
+}
@@ -0,0 +1,118 @@
+package virtual
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/glfw"
+	"github.com/omustardo/gome/input/touch"
+)
+
+type keyRecorder map[glfw.Key]bool
+
+func (r keyRecorder) send(key glfw.Key, action glfw.Action) {
+	r[key] = action == glfw.Press
+}
+
+func newTestControls() (*Controls, *Joystick, *Button, keyRecorder) {
+	keys := keyRecorder{}
+	stick := NewJoystick(mgl32.Vec2{0.25, 0.75}, 0.1)
+	fire := NewButton(mgl32.Vec2{0.75, 0.75}, 0.1, glfw.KeySpace)
+	c := &Controls{
+		Joysticks: []*Joystick{stick},
+		Buttons:   []*Button{fire},
+		SendKey:   keys.send,
+	}
+	return c, stick, fire, keys
+}
+
+func TestMultiTouch(t *testing.T) {
+	// Try multiple screen sizes to make sure the layout scales with resolution.
+	sizes := []mgl32.Vec2{{1000, 1000}, {1920, 1080}, {720, 1280}}
+	for _, size := range sizes {
+		w, h := size.X(), size.Y()
+		c, stick, fire, keys := newTestControls()
+		radius := 0.1 * w
+		if h < w {
+			radius = 0.1 * h
+		}
+		stickCenter := mgl32.Vec2{0.25 * w, 0.75 * h}
+		fireCenter := mgl32.Vec2{0.75 * w, 0.75 * h}
+
+		// Put one finger on the joystick and one on the fire button at the same time.
+		c.Update([]touch.Touch{
+			{ID: 1, Phase: touch.Began, Position: stickCenter},
+			{ID: 2, Phase: touch.Began, Position: fireCenter},
+		}, w, h)
+		if !stick.Active() || !fire.Pressed() || !fire.JustPressed() {
+			t.Errorf("%v: got joystick active=%v, button pressed=%v just pressed=%v, want all true", size, stick.Active(), fire.Pressed(), fire.JustPressed())
+		}
+		if !keys[glfw.KeySpace] {
+			t.Errorf("%v: fire button didn't press space", size)
+		}
+
+		// Push the stick fully up and to the right while still holding fire.
+		c.Update([]touch.Touch{
+			{ID: 1, Phase: touch.Moved, Position: stickCenter.Add(mgl32.Vec2{radius * 2, -radius * 2})},
+			{ID: 2, Phase: touch.Stationary, Position: fireCenter},
+		}, w, h)
+		if got, want := stick.Axis(), (mgl32.Vec2{1, 1}).Normalize(); !got.ApproxEqualThreshold(want, 1e-4) {
+			t.Errorf("%v: got axis %v, want %v", size, got, want)
+		}
+		if !keys[glfw.KeyW] || !keys[glfw.KeyD] || keys[glfw.KeyA] || keys[glfw.KeyS] {
+			t.Errorf("%v: got keys %v, want W and D pressed", size, keys)
+		}
+		if !fire.Pressed() || fire.JustPressed() {
+			t.Errorf("%v: got button pressed=%v just pressed=%v, want true, false", size, fire.Pressed(), fire.JustPressed())
+		}
+
+		// Lift the fire finger. Sliding the stick finger over the button shouldn't press it.
+		c.Update([]touch.Touch{
+			{ID: 1, Phase: touch.Moved, Position: fireCenter},
+			{ID: 2, Phase: touch.Ended, Position: fireCenter},
+		}, w, h)
+		if fire.Pressed() || keys[glfw.KeySpace] {
+			t.Errorf("%v: button still pressed after its touch ended", size)
+		}
+		if keys[glfw.KeyW] || !keys[glfw.KeyD] {
+			t.Errorf("%v: got keys %v, want only D pressed", size, keys)
+		}
+
+		// Lift the stick finger.
+		c.Update([]touch.Touch{
+			{ID: 1, Phase: touch.Ended, Position: fireCenter},
+		}, w, h)
+		if stick.Active() || stick.Axis() != (mgl32.Vec2{}) || keys[glfw.KeyD] {
+			t.Errorf("%v: joystick still active after its touch ended", size)
+		}
+	}
+}
+
+func TestFloatingJoystick(t *testing.T) {
+	keys := keyRecorder{}
+	stick := NewFloatingJoystick(mgl32.Vec2{0.25, 0.75}, 0.1)
+	c := &Controls{Joysticks: []*Joystick{stick}, SendKey: keys.send}
+	w, h := float32(1000), float32(1000)
+
+	// Touches outside of the region are ignored.
+	c.Update([]touch.Touch{{ID: 1, Phase: touch.Began, Position: mgl32.Vec2{900, 500}}}, w, h)
+	if stick.Active() {
+		t.Error("floating joystick started outside of its region")
+	}
+
+	// The base moves to wherever the touch starts, so there's no initial movement.
+	start := mgl32.Vec2{100, 200}
+	c.Update([]touch.Touch{{ID: 2, Phase: touch.Began, Position: start}}, w, h)
+	if !stick.Active() || stick.Axis() != (mgl32.Vec2{}) {
+		t.Errorf("got active=%v axis=%v, want active with no movement", stick.Active(), stick.Axis())
+	}
+
+	// Moving down by half the radius.
+	c.Update([]touch.Touch{{ID: 2, Phase: touch.Moved, Position: start.Add(mgl32.Vec2{0, 50})}}, w, h)
+	if got, want := stick.Axis(), (mgl32.Vec2{0, -0.5}); !got.ApproxEqualThreshold(want, 1e-4) {
+		t.Errorf("got axis %v, want %v", got, want)
+	}
+	if !keys[glfw.KeyS] {
+		t.Errorf("got keys %v, want S pressed", keys)
+	}
+}
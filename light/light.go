@@ -0,0 +1,255 @@
+// Package light contains lights for lighting models, and a Manager which picks the lights that affect each model.
+//
+// Point lights and spotlights can be attached to anything with a position, like an entity, so they move along with it.
+// If what they're attached to also has an orientation, like an *entity.Entity, their offset and direction turn with it.
+package light
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/shader"
+)
+
+var (
+	_ Light = (*Directional)(nil)
+	_ Light = (*Point)(nil)
+	_ Light = (*Spot)(nil)
+)
+
+// Light is anything that can light a model.
+type Light interface {
+	// ShaderLight returns the light as it's sent to the model shader.
+	ShaderLight() shader.Light
+	// Relevance returns how much the light affects a sphere. It's used to pick the lights that matter most when there
+	// are too many to use at once. Zero means the light doesn't reach the sphere at all.
+	Relevance(center mgl32.Vec3, radius float32) float32
+}
+
+// Orientation is implemented by things that have a direction they face, like *entity.Entity.
+// Lights attached to something with an Orientation turn along with it.
+type Orientation interface {
+	Forward() mgl32.Vec3
+	Up() mgl32.Vec3
+	Right() mgl32.Vec3
+}
+
+// Directional is light that shines the same direction everywhere, like sunlight.
+type Directional struct {
+	// Direction is the direction that the light shines.
+	Direction mgl32.Vec3
+	// Color of the light. Nil means white. Alpha is ignored.
+	Color *color.NRGBA
+	// Intensity multiplies the color. Values over 1 make very bright lights.
+	Intensity float32
+	// Shadow makes things block the light, if it's set. See RenderShadows.
+	Shadow *Shadow
+	// Disabled lights don't light anything.
+	Disabled bool
+}
+
+// NewDirectional creates a directional light shining in the provided direction.
+func NewDirectional(direction mgl32.Vec3, color *color.NRGBA) *Directional {
+	return &Directional{
+		Direction: direction,
+		Color:     color,
+		Intensity: 1,
+	}
+}
+
+func (l *Directional) ShaderLight() shader.Light {
+	return shader.Light{
+		Type:      shader.DirectionalLight,
+		Direction: l.Direction,
+		Color:     colorVec(l.Color, l.Intensity),
+		Shadowed:  l.Shadow != nil && l.Shadow == applied,
+	}
+}
+
+// RenderShadows draws the light's shadow map for a camera with the provided matrices, and sends it to the model
+// shader. It does nothing if the light has no Shadow. See Shadow.Render for details.
+func (l *Directional) RenderShadows(pMatrix, mvMatrix mgl32.Mat4, draw func()) error {
+	if l.Shadow == nil {
+		return nil
+	}
+	return l.Shadow.Render(l.Direction, pMatrix, mvMatrix, draw)
+}
+
+// Relevance of a directional light is its brightness, since it reaches everything equally.
+func (l *Directional) Relevance(center mgl32.Vec3, radius float32) float32 {
+	if l.Disabled {
+		return 0
+	}
+	return brightness(l.Color, l.Intensity)
+}
+
+// Point is light that shines in every direction from a position, like a light bulb.
+type Point struct {
+	// Position of the light. If the light is attached to something, this is an offset from it instead.
+	Position mgl32.Vec3
+	// Attached is what the light moves along with. Leave it nil for a light that stays in one place.
+	Attached entity.Target
+	// Color of the light. Nil means white. Alpha is ignored.
+	Color *color.NRGBA
+	// Intensity multiplies the color. Values over 1 make very bright lights.
+	Intensity float32
+	// Range is how far the light reaches. It fades out smoothly until this distance.
+	Range float32
+	// Disabled lights don't light anything.
+	Disabled bool
+}
+
+// NewPoint creates a point light at the provided position, which reaches up to lightRange away.
+func NewPoint(position mgl32.Vec3, color *color.NRGBA, lightRange float32) *Point {
+	return &Point{
+		Position:  position,
+		Color:     color,
+		Intensity: 1,
+		Range:     lightRange,
+	}
+}
+
+// GetPosition returns the position of the light in the world.
+func (l *Point) GetPosition() mgl32.Vec3 {
+	return attachedPoint(l.Attached, l.Position)
+}
+
+func (l *Point) ShaderLight() shader.Light {
+	return shader.Light{
+		Type:     shader.PointLight,
+		Position: l.GetPosition(),
+		Color:    colorVec(l.Color, l.Intensity),
+		Range:    l.Range,
+	}
+}
+
+// Relevance of a point light is how bright it is at the closest part of the sphere.
+func (l *Point) Relevance(center mgl32.Vec3, radius float32) float32 {
+	if l.Disabled {
+		return 0
+	}
+	return brightness(l.Color, l.Intensity) * attenuation(l.GetPosition().Sub(center).Len()-radius, l.Range)
+}
+
+// Spot is light that shines in a cone from a position, like a flashlight.
+type Spot struct {
+	// Position of the light. If the light is attached to something, this is an offset from it instead.
+	Position mgl32.Vec3
+	// Direction that the light shines. If the light is attached to something with an Orientation, this is relative to
+	// it, where entity.Forward is the direction it faces.
+	Direction mgl32.Vec3
+	// Attached is what the light moves along with. Leave it nil for a light that stays in one place.
+	Attached entity.Target
+	// Color of the light. Nil means white. Alpha is ignored.
+	Color *color.NRGBA
+	// Intensity multiplies the color. Values over 1 make very bright lights.
+	Intensity float32
+	// Range is how far the light reaches. It fades out smoothly until this distance.
+	Range float32
+	// InnerAngle and OuterAngle are the angles in radians from the center of the light to the edges of its cone.
+	// Everything within InnerAngle is fully lit, and the light fades out between InnerAngle and OuterAngle.
+	InnerAngle, OuterAngle float32
+	// Disabled lights don't light anything.
+	Disabled bool
+}
+
+// NewSpot creates a spotlight at the provided position. It reaches up to lightRange away, and its cone spreads out
+// by angle radians from its center, with a soft edge.
+func NewSpot(position, direction mgl32.Vec3, color *color.NRGBA, lightRange, angle float32) *Spot {
+	return &Spot{
+		Position:   position,
+		Direction:  direction,
+		Color:      color,
+		Intensity:  1,
+		Range:      lightRange,
+		InnerAngle: angle * 0.8,
+		OuterAngle: angle,
+	}
+}
+
+// GetPosition returns the position of the light in the world.
+func (l *Spot) GetPosition() mgl32.Vec3 {
+	return attachedPoint(l.Attached, l.Position)
+}
+
+// GetDirection returns the direction the light shines in the world.
+func (l *Spot) GetDirection() mgl32.Vec3 {
+	if o, ok := l.Attached.(Orientation); ok {
+		return orient(o, l.Direction)
+	}
+	return l.Direction
+}
+
+func (l *Spot) ShaderLight() shader.Light {
+	return shader.Light{
+		Type:       shader.SpotLight,
+		Position:   l.GetPosition(),
+		Direction:  l.GetDirection(),
+		Color:      colorVec(l.Color, l.Intensity),
+		Range:      l.Range,
+		InnerAngle: l.InnerAngle,
+		OuterAngle: l.OuterAngle,
+	}
+}
+
+// Relevance of a spotlight is how bright it is at the closest part of the sphere, or zero if the sphere is entirely
+// outside of its cone.
+func (l *Spot) Relevance(center mgl32.Vec3, radius float32) float32 {
+	if l.Disabled {
+		return 0
+	}
+	toCenter := center.Sub(l.GetPosition())
+	dist := toCenter.Len()
+	if dist > radius && l.GetDirection().Len() > 0 {
+		// The sphere covers this angle as seen from the light, so it's outside the cone if its center is further than
+		// that from the cone's edge.
+		spread := math.Asin(float64(radius / dist))
+		angle := math.Acos(float64(mgl32.Clamp(toCenter.Normalize().Dot(l.GetDirection().Normalize()), -1, 1)))
+		if angle-spread >= float64(l.OuterAngle) {
+			return 0
+		}
+	}
+	return brightness(l.Color, l.Intensity) * attenuation(dist-radius, l.Range)
+}
+
+// attachedPoint returns where an offset from a target is in the world.
+func attachedPoint(target entity.Target, offset mgl32.Vec3) mgl32.Vec3 {
+	if target == nil {
+		return offset
+	}
+	if o, ok := target.(Orientation); ok {
+		offset = orient(o, offset)
+	}
+	return target.GetPosition().Add(offset)
+}
+
+// orient turns a vector relative to something with an orientation into world space.
+// Since entity.Forward is down the negative Z axis, Z is flipped.
+func orient(o Orientation, v mgl32.Vec3) mgl32.Vec3 {
+	return o.Right().Mul(v.X()).Add(o.Up().Mul(v.Y())).Sub(o.Forward().Mul(v.Z()))
+}
+
+// attenuation matches the falloff in the model shader. It's 1 at the light, and fades to 0 at lightRange.
+func attenuation(dist, lightRange float32) float32 {
+	if lightRange <= 0 {
+		return 0
+	}
+	falloff := mgl32.Clamp(1-dist/lightRange, 0, 1)
+	return falloff * falloff
+}
+
+// colorVec converts a color to the vector the shader expects. Nil is white.
+func colorVec(c *color.NRGBA, intensity float32) mgl32.Vec3 {
+	if c == nil {
+		return mgl32.Vec3{intensity, intensity, intensity}
+	}
+	return mgl32.Vec3{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255}.Mul(intensity)
+}
+
+// brightness is how bright a color is, from the average of its components.
+func brightness(c *color.NRGBA, intensity float32) float32 {
+	v := colorVec(c, intensity)
+	return (v[0] + v[1] + v[2]) / 3
+}
@@ -0,0 +1,111 @@
+package light
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+)
+
+func TestPointRelevance(t *testing.T) {
+	l := NewPoint(mgl32.Vec3{0, 0, 0}, nil, 100)
+	// Only the closest part of the sphere matters, so a large sphere is lit even if its center is out of range.
+	if r := l.Relevance(mgl32.Vec3{150, 0, 0}, 60); r <= 0 {
+		t.Errorf("got relevance %v for a sphere within range, want it to be lit", r)
+	}
+	if r := l.Relevance(mgl32.Vec3{150, 0, 0}, 10); r != 0 {
+		t.Errorf("got relevance %v for a sphere out of range, want 0", r)
+	}
+	if near, far := l.Relevance(mgl32.Vec3{10, 0, 0}, 1), l.Relevance(mgl32.Vec3{50, 0, 0}, 1); near <= far {
+		t.Errorf("got relevance %v nearby and %v further away, want the light to fade with distance", near, far)
+	}
+	l.Disabled = true
+	if r := l.Relevance(mgl32.Vec3{}, 1); r != 0 {
+		t.Errorf("got relevance %v for a disabled light, want 0", r)
+	}
+}
+
+func TestSpotRelevance(t *testing.T) {
+	l := NewSpot(mgl32.Vec3{0, 0, 0}, mgl32.Vec3{1, 0, 0}, nil, 100, mgl32.DegToRad(20))
+	tests := []struct {
+		center mgl32.Vec3
+		radius float32
+		lit    bool
+	}{
+		{mgl32.Vec3{50, 0, 0}, 1, true},
+		{mgl32.Vec3{50, 10, 0}, 1, true},  // About 11 degrees off center.
+		{mgl32.Vec3{50, 30, 0}, 1, false}, // About 31 degrees off center.
+		{mgl32.Vec3{50, 30, 0}, 15, true}, // The edge of a larger sphere reaches into the cone.
+		{mgl32.Vec3{-50, 0, 0}, 1, false}, // Behind the light.
+		{mgl32.Vec3{-50, 0, 0}, 60, true}, // Surrounding the light.
+		{mgl32.Vec3{150, 0, 0}, 1, false}, // Out of range.
+	}
+	for _, tt := range tests {
+		if r := l.Relevance(tt.center, tt.radius); (r > 0) != tt.lit {
+			t.Errorf("got relevance %v for a sphere at %v with radius %v, want lit=%v", r, tt.center, tt.radius, tt.lit)
+		}
+	}
+}
+
+func TestAttached(t *testing.T) {
+	e := entity.Default()
+	e.Position = mgl32.Vec3{100, 0, 0}
+	// Turn to face down the positive X axis.
+	e.Rotation = mgl32.QuatRotate(-mgl32.DegToRad(90), entity.Up)
+
+	// A headlight a little in front of the entity, shining the way it faces.
+	l := NewSpot(mgl32.Vec3{0, 0, -10}, entity.Forward, nil, 100, 1)
+	l.Attached = &e
+	if got, want := l.GetPosition(), (mgl32.Vec3{110, 0, 0}); got.Sub(want).Len() > 1e-4 {
+		t.Errorf("got position %v, want %v", got, want)
+	}
+	if got, want := l.GetDirection(), (mgl32.Vec3{1, 0, 0}); got.Sub(want).Len() > 1e-4 {
+		t.Errorf("got direction %v, want %v", got, want)
+	}
+
+	// Targets without an orientation only move the light.
+	p := NewPoint(mgl32.Vec3{0, 5, 0}, nil, 100)
+	p.Attached = staticTarget{10, 0, 0}
+	if got, want := p.ShaderLight().Position, (mgl32.Vec3{10, 5, 0}); got != want {
+		t.Errorf("got position %v, want %v", got, want)
+	}
+}
+
+type staticTarget mgl32.Vec3
+
+func (s staticTarget) GetPosition() mgl32.Vec3 { return mgl32.Vec3(s) }
+
+func TestManagerRelevant(t *testing.T) {
+	m := NewManager()
+	m.MaxLights = 2
+	sun := NewDirectional(mgl32.Vec3{0, 0, -1}, &color.NRGBA{50, 50, 50, 255})
+	near := NewPoint(mgl32.Vec3{10, 0, 0}, nil, 100)
+	far := NewPoint(mgl32.Vec3{90, 0, 0}, nil, 100)
+	outOfRange := NewPoint(mgl32.Vec3{500, 0, 0}, nil, 100)
+	m.Add(far, outOfRange, sun, near)
+
+	got := m.Relevant(mgl32.Vec3{}, 1)
+	if len(got) != 2 || got[0] != near || got[1] != sun {
+		t.Errorf("got %v, want the nearby light and then the dim sun", got)
+	}
+
+	m.MaxLights = 10
+	if got := m.Relevant(mgl32.Vec3{}, 1); len(got) != 3 {
+		t.Errorf("got %d lights, want all 3 in range", len(got))
+	}
+	// The manager reuses its buffers, but earlier results shouldn't change.
+	if len(got) != 2 || got[0] != near || got[1] != sun {
+		t.Errorf("got %v after finding lights again, want the first result unchanged", got)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { m.findRelevant(mgl32.Vec3{}, 1) }); allocs > 0 {
+		t.Errorf("got %v allocations finding relevant lights, want none", allocs)
+	}
+
+	if !m.Remove(near) || m.Remove(near) {
+		t.Error("expected removing a light to only succeed once")
+	}
+	if got := len(m.All()); got != 3 {
+		t.Errorf("got %d lights after removing one, want 3", got)
+	}
+}
@@ -0,0 +1,131 @@
+package light
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/shader"
+)
+
+// Lights is the default Manager. Models use it when they're rendered.
+var Lights = NewManager()
+
+// Manager holds the lights in a scene. Before each model is drawn, it picks the lights that affect the model the most
+// and sends them to the model shader. This allows any number of lights in a scene, as long as only a few affect each
+// model.
+//
+// Sample usage:
+//   sun := light.NewDirectional(mgl32.Vec3{-1, -1, -1}, &color.NRGBA{200, 200, 200, 255})
+//   headlight := light.NewSpot(mgl32.Vec3{0, 0, -1}, entity.Forward, nil, 500, mgl32.DegToRad(25))
+//   headlight.Attached = &player.Entity
+//   light.Lights.Add(sun, headlight)
+//   for { // game loop
+//     player.Render() // Models apply the lights themselves.
+//   }
+type Manager struct {
+	// MaxLights is the most lights used for each model. It can't be more than shader.MaxLights.
+	// Using fewer can make rendering faster.
+	MaxLights int
+
+	lights []Light
+
+	// Apply runs before every model is drawn, so it reuses these rather than allocating new ones each time.
+	candidates   []scoredLight
+	relevant     []Light
+	shaderLights []shader.Light
+}
+
+// scoredLight is a light and how much it affects something.
+type scoredLight struct {
+	light     Light
+	relevance float32
+}
+
+// NewManager creates a Manager with no lights, that uses up to shader.MaxLights for each model.
+func NewManager() *Manager {
+	return &Manager{
+		MaxLights: shader.MaxLights,
+	}
+}
+
+// Add adds lights to the scene.
+func (m *Manager) Add(lights ...Light) {
+	m.lights = append(m.lights, lights...)
+}
+
+// Remove removes a light from the scene. It returns false if the light wasn't found.
+func (m *Manager) Remove(l Light) bool {
+	for i := range m.lights {
+		if m.lights[i] == l {
+			m.lights = append(m.lights[:i], m.lights[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Clear removes all lights from the scene.
+func (m *Manager) Clear() {
+	m.lights = nil
+}
+
+// All returns every light in the scene.
+func (m *Manager) All() []Light {
+	return m.lights
+}
+
+// Relevant returns the lights that affect a sphere the most, brightest first. Lights that don't reach the sphere at
+// all aren't included.
+func (m *Manager) Relevant(center mgl32.Vec3, radius float32) []Light {
+	return append([]Light(nil), m.findRelevant(center, radius)...)
+}
+
+// findRelevant is like Relevant, but the returned slice is reused by the next call.
+func (m *Manager) findRelevant(center mgl32.Vec3, radius float32) []Light {
+	// Insert each light in order, most relevant first. Scenes rarely have many lights, and unlike package sort this
+	// doesn't allocate.
+	m.candidates = m.candidates[:0]
+	for _, l := range m.lights {
+		r := l.Relevance(center, radius)
+		if r <= 0 {
+			continue
+		}
+		m.candidates = append(m.candidates, scoredLight{l, r})
+		for i := len(m.candidates) - 1; i > 0 && m.candidates[i-1].relevance < r; i-- {
+			m.candidates[i-1], m.candidates[i] = m.candidates[i], m.candidates[i-1]
+		}
+	}
+
+	max := m.MaxLights
+	if max > shader.MaxLights {
+		max = shader.MaxLights
+	}
+	candidates := m.candidates
+	if len(candidates) > max {
+		candidates = candidates[:max]
+	}
+	m.relevant = m.relevant[:0]
+	for _, c := range candidates {
+		m.relevant = append(m.relevant, c.light)
+	}
+	return m.relevant
+}
+
+// Apply sends the lights that affect a sphere the most to the model shader, so they're used for the following draw
+// calls. Models call this on Lights when they're rendered, so it only needs to be called directly when drawing
+// without a model, or when using a different Manager.
+func (m *Manager) Apply(center mgl32.Vec3, radius float32) {
+	m.shaderLights = m.shaderLights[:0]
+	for _, l := range m.findRelevant(center, radius) {
+		m.shaderLights = append(m.shaderLights, l.ShaderLight())
+	}
+	shader.Model.SetLights(m.shaderLights)
+}
+
+// ApplyProgram is like Apply, but sends the lights to a custom program along with the model shader's ambient light.
+// Models drawn with a custom program call this rather than Apply. See shader.Program.SetLights.
+func (m *Manager) ApplyProgram(p *shader.Program, center mgl32.Vec3, radius float32) {
+	m.shaderLights = m.shaderLights[:0]
+	for _, l := range m.findRelevant(center, radius) {
+		m.shaderLights = append(m.shaderLights, l.ShaderLight())
+	}
+	p.SetLights(m.shaderLights, shader.Model.AmbientLight())
+}
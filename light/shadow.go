@@ -0,0 +1,352 @@
+package light
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/shader"
+	"github.com/omustardo/gome/util/glutil"
+	"github.com/omustardo/gome/view/rendertarget"
+)
+
+// applied is the shadow most recently sent to the model shader. Only the light it belongs to is shadowed.
+var applied *Shadow
+
+// maxTextureSize caches gl.MAX_TEXTURE_SIZE, since it doesn't change. Zero means it hasn't been read yet.
+var maxTextureSize int
+
+// Shadow is a shadow map for a Directional light. Everything drawn into it blocks the light from whatever is behind it.
+//
+// The shadow map only covers what the camera can see, up to MaxDistance away, so its resolution isn't wasted on the
+// rest of the world. That view can be split into cascades: slices by distance from the camera that each get their own
+// shadow map, so shadows close to the camera are sharp while distant ones are still visible.
+//
+// Sample usage:
+//   sun := light.NewDirectional(mgl32.Vec3{-1, -1, -2}, nil)
+//   sun.Shadow = light.NewShadow(1024, 3)
+//   light.Lights.Add(sun)
+//   for { // game loop
+//     pMatrix, mvMatrix := cam.ProjectionPerspective(width, height), cam.ModelView()
+//     if err := sun.RenderShadows(pMatrix, mvMatrix, drawScene); err != nil {
+//       log.Println(err)
+//     }
+//     shader.Model.SetMVPMatrix(pMatrix, mvMatrix)
+//     drawScene()
+//   }
+type Shadow struct {
+	// Size is the width and height of each cascade's shadow map, in texels. Larger sizes make sharper shadows.
+	// The cascades are side by side in one texture, so Size is reduced if they don't fit in the largest texture the
+	// GPU supports.
+	Size int
+	// Cascades is how many slices the camera's view is split into, from 1 to shader.MaxShadowCascades.
+	Cascades int
+	// MaxDistance is how far in front of the camera shadows are drawn. Zero uses the camera's far plane, which is
+	// usually too far away for sharp shadows.
+	MaxDistance float32
+	// SplitWeight from 0 to 1 is how the cascades are spaced. 0 splits the distance evenly. 1 makes each cascade
+	// cover the same ratio of distances, so the closest ones are much smaller and sharper.
+	SplitWeight float32
+	// CasterDistance is how far outside of the camera's view, toward the light, things still cast shadows into view.
+	CasterDistance float32
+	// Bias is subtracted from depths before comparing them, so surfaces don't shadow themselves. It's measured in the
+	// depth range of the shadow map, from 0 to 1. SlopeBias is added to it for surfaces at an angle to the light,
+	// which need more.
+	Bias, SlopeBias float32
+	// FilterRadius softens the edges of shadows by this many texels, up to shader.MaxShadowFilterRadius.
+	// Zero makes hard edges.
+	FilterRadius int
+
+	framebuffer gl.Framebuffer
+	texture     gl.Texture
+	// renderbuffer holds the depth buffer if depth is packed into texture's color. Otherwise it's an unused color
+	// buffer, since some drivers don't allow framebuffers without one.
+	renderbuffer gl.Renderbuffer
+	// packed is true if depth textures aren't supported, so depths are packed into colors.
+	packed bool
+	// width and height are the size of texture, which holds every cascade side by side.
+	width, height int
+
+	uniforms shader.Shadow
+}
+
+// NewShadow creates a shadow with the provided size in texels for each of its cascades.
+// The GPU resources for it are created when it's first rendered.
+func NewShadow(size, cascades int) *Shadow {
+	return &Shadow{
+		Size:           size,
+		Cascades:       cascades,
+		SplitWeight:    0.75,
+		CasterDistance: 1000,
+		Bias:           0.0005,
+		SlopeBias:      0.002,
+		FilterRadius:   1,
+	}
+}
+
+// Render draws the shadow map for a light shining in the provided direction, as seen by a camera with the provided
+// matrices. The draw function should draw everything that casts shadows, using the model shader, without changing
+// its matrices. Models can be rendered normally.
+//
+// The shadow map is then sent to the model shader, so it's used for the following draw calls. The model shader's
+// matrices are restored afterward, along with the rest of the state that drawing the shadow map changes.
+func (s *Shadow) Render(direction mgl32.Vec3, pMatrix, mvMatrix mgl32.Mat4, draw func()) error {
+	if s.Size <= 0 {
+		return fmt.Errorf("shadow map size must be >0. got %d", s.Size)
+	}
+	cascades := s.Cascades
+	if cascades < 1 {
+		cascades = 1
+	} else if cascades > shader.MaxShadowCascades {
+		cascades = shader.MaxShadowCascades
+	}
+	if maxTextureSize == 0 {
+		maxTextureSize = gl.GetInteger(gl.MAX_TEXTURE_SIZE)
+	}
+	size := cascadeSize(s.Size, cascades, maxTextureSize)
+	if err := s.allocate(size*cascades, size); err != nil {
+		return err
+	}
+
+	near, far, nearDepth, farDepth := frustumCorners(pMatrix, mvMatrix)
+	maxDepth := farDepth
+	if s.MaxDistance > 0 && s.MaxDistance < maxDepth {
+		maxDepth = s.MaxDistance
+	}
+	splits := cascadeSplits(nearDepth, maxDepth, cascades, s.SplitWeight)
+
+	// Keep the state that's changed, so it can be restored for drawing the rest of the scene. That may be into a
+	// render target rather than the window.
+	framebuffer := rendertarget.Current()
+	cameraP, cameraMV := shader.Model.MVPMatrix()
+	var viewport [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, viewport[:])
+	clearColor := make([]float32, 4)
+	gl.GetFloatv(clearColor, gl.COLOR_CLEAR_VALUE)
+	scissor := gl.IsEnabled(gl.SCISSOR_TEST)
+
+	// The shadow map can't be read while it's being drawn to.
+	shader.Model.SetShadow(nil)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, s.framebuffer)
+	gl.Disable(gl.SCISSOR_TEST)
+	// White unpacks to a depth past the far plane, so anywhere nothing is drawn is lit.
+	gl.ClearColor(1, 1, 1, 1)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	shader.Model.SetDepthOnly(true)
+
+	matrices := make([]mgl32.Mat4, cascades)
+	start := nearDepth
+	for i := range matrices {
+		projection, view := fitLight(direction, frustumSlice(near, far, nearDepth, farDepth, start, splits[i]), size, s.CasterDistance)
+		gl.Viewport(i*size, 0, size, size)
+		shader.Model.SetMVPMatrix(projection, view)
+		draw()
+		matrices[i] = textureMatrix(i, cascades).Mul4(projection).Mul4(view)
+		start = splits[i]
+	}
+
+	shader.Model.SetDepthOnly(false)
+	shader.Model.SetMVPMatrix(cameraP, cameraMV)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, framebuffer)
+	gl.Viewport(int(viewport[0]), int(viewport[1]), int(viewport[2]), int(viewport[3]))
+	gl.ClearColor(clearColor[0], clearColor[1], clearColor[2], clearColor[3])
+	if scissor {
+		gl.Enable(gl.SCISSOR_TEST)
+	}
+
+	s.uniforms = shader.Shadow{
+		Texture:      s.texture,
+		Packed:       s.packed,
+		Matrices:     matrices,
+		Splits:       splits,
+		TexelSize:    mgl32.Vec2{1 / float32(s.width), 1 / float32(s.height)},
+		Bias:         s.Bias,
+		SlopeBias:    s.SlopeBias,
+		FilterRadius: s.FilterRadius,
+	}
+	shader.Model.SetShadow(&s.uniforms)
+	applied = s
+	return nil
+}
+
+// Delete frees the shadow map on the GPU. It's created again if the shadow is rendered again.
+func (s *Shadow) Delete() {
+	if s.framebuffer.Valid() {
+		gl.DeleteFramebuffer(s.framebuffer)
+	}
+	if s.texture.Valid() {
+		gl.DeleteTexture(s.texture)
+	}
+	if s.renderbuffer.Valid() {
+		gl.DeleteRenderbuffer(s.renderbuffer)
+	}
+	s.framebuffer, s.texture, s.renderbuffer = gl.Framebuffer{}, gl.Texture{}, gl.Renderbuffer{}
+	s.width, s.height = 0, 0
+	if applied == s {
+		shader.Model.SetShadow(nil)
+		applied = nil
+	}
+}
+
+// allocate creates the shadow map if it doesn't exist or is the wrong size.
+func (s *Shadow) allocate(width, height int) error {
+	if s.framebuffer.Valid() && s.width == width && s.height == height {
+		return nil
+	}
+	s.Delete()
+	s.packed = !glutil.DepthTextureSupported()
+	err := s.create(width, height)
+	if err != nil && !s.packed {
+		// Some drivers claim to support depth textures but can't draw into them, so fall back to packing depth.
+		s.Delete()
+		s.packed = true
+		err = s.create(width, height)
+	}
+	if err != nil {
+		s.Delete()
+		return err
+	}
+	s.width, s.height = width, height
+	return nil
+}
+
+func (s *Shadow) create(width, height int) error {
+	s.framebuffer = gl.CreateFramebuffer()
+	gl.BindFramebuffer(gl.FRAMEBUFFER, s.framebuffer)
+	defer gl.BindFramebuffer(gl.FRAMEBUFFER, rendertarget.Current())
+
+	s.texture = gl.CreateTexture()
+	gl.BindTexture(gl.TEXTURE_2D, s.texture)
+	if s.packed {
+		gl.TexImage2D(gl.TEXTURE_2D, 0, width, height, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	} else {
+		gl.TexImage2D(gl.TEXTURE_2D, 0, width, height, gl.DEPTH_COMPONENT, gl.UNSIGNED_INT, nil)
+	}
+	// Depths can't be blended, so texels are read exactly. Without mipmaps and with clamped edges, the size doesn't
+	// need to be a power of two.
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, gl.Texture{})
+
+	s.renderbuffer = gl.CreateRenderbuffer()
+	gl.BindRenderbuffer(gl.RENDERBUFFER, s.renderbuffer)
+	if s.packed {
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT16, width, height)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, s.texture, 0)
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, s.renderbuffer)
+	} else {
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.RGBA4, width, height)
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.RENDERBUFFER, s.renderbuffer)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.TEXTURE_2D, s.texture, 0)
+	}
+	gl.BindRenderbuffer(gl.RENDERBUFFER, gl.Renderbuffer{})
+
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		return fmt.Errorf("shadow map framebuffer is incomplete: status 0x%x", int(status))
+	}
+	return nil
+}
+
+// cascadeSize returns the size of each cascade's shadow map, reduced if needed so every cascade fits side by side in
+// a texture no wider than maxTexture. A maxTexture of zero means there's no limit.
+func cascadeSize(size, cascades, maxTexture int) int {
+	if maxTexture > 0 && size*cascades > maxTexture {
+		return maxTexture / cascades
+	}
+	return size
+}
+
+// frustumCorners returns the corners of the near and far planes of a camera's view in world space, along with their
+// distances in front of the camera. Corners are in the same order for both planes, so each pair makes an edge.
+func frustumCorners(pMatrix, mvMatrix mgl32.Mat4) (near, far [4]mgl32.Vec3, nearDepth, farDepth float32) {
+	inverse := pMatrix.Mul4(mvMatrix).Inv()
+	for i, corner := range [4]mgl32.Vec2{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+		near[i] = mgl32.TransformCoordinate(corner.Vec3(-1), inverse)
+		far[i] = mgl32.TransformCoordinate(corner.Vec3(1), inverse)
+	}
+	depth := func(p mgl32.Vec3) float32 {
+		return -mvMatrix.Mul4x1(p.Vec4(1)).Z()
+	}
+	return near, far, depth(near[0]), depth(far[0])
+}
+
+// frustumSlice returns the corners of the part of a camera's view between two distances in front of it.
+func frustumSlice(near, far [4]mgl32.Vec3, nearDepth, farDepth, from, to float32) [8]mgl32.Vec3 {
+	var corners [8]mgl32.Vec3
+	for i := range near {
+		edge := far[i].Sub(near[i])
+		corners[i] = near[i].Add(edge.Mul((from - nearDepth) / (farDepth - nearDepth)))
+		corners[i+4] = near[i].Add(edge.Mul((to - nearDepth) / (farDepth - nearDepth)))
+	}
+	return corners
+}
+
+// cascadeSplits returns the distances where each cascade ends. The last one is always far.
+// Cascades are a mix of even splits and logarithmic splits, which keep the ratio of the start and end of each
+// cascade the same. Logarithmic splits match how perspective makes things smaller with distance, but make the
+// closest cascades tiny, so weight picks between them.
+func cascadeSplits(near, far float32, count int, weight float32) []float32 {
+	splits := make([]float32, count)
+	for i := range splits {
+		fraction := float32(i+1) / float32(count)
+		split := near + (far-near)*fraction
+		if near > 0 {
+			logarithmic := near * float32(math.Pow(float64(far/near), float64(fraction)))
+			split += (logarithmic - split) * weight
+		}
+		splits[i] = split
+	}
+	splits[count-1] = far
+	return splits
+}
+
+// fitLight returns the orthographic projection and view matrices of a light shining in the provided direction that
+// see all of the provided corners, as well as anything up to casterDistance beyond them toward the light.
+//
+// The view fits around a sphere containing the corners, so it's the same size no matter which way the camera faces.
+// It moves in steps of whole texels of a shadow map with the provided size. Together these keep the edges of shadows
+// from shimmering as the camera moves and turns.
+func fitLight(direction mgl32.Vec3, corners [8]mgl32.Vec3, size int, casterDistance float32) (projection, view mgl32.Mat4) {
+	var center mgl32.Vec3
+	for _, c := range corners {
+		center = center.Add(c)
+	}
+	center = center.Mul(1.0 / float32(len(corners)))
+	var radius float32
+	for _, c := range corners {
+		if d := c.Sub(center).Len(); d > radius {
+			radius = d
+		}
+	}
+
+	if direction.Len() == 0 {
+		direction = mgl32.Vec3{0, 0, -1}
+	}
+	direction = direction.Normalize()
+	up := mgl32.Vec3{0, 1, 0}
+	if math.Abs(float64(direction.Y())) > 0.99 {
+		up = mgl32.Vec3{0, 0, 1}
+	}
+	eye := center.Sub(direction.Mul(radius + casterDistance))
+	view = mgl32.LookAtV(eye, center, up)
+	projection = mgl32.Ortho(-radius, radius, -radius, radius, 0, 2*radius+casterDistance)
+
+	// Shift the projection so the world's origin lands exactly on a texel, which puts every texel in a fixed place in
+	// the world.
+	origin := projection.Mul4(view).Mul4x1(mgl32.Vec4{0, 0, 0, 1})
+	texels := float32(size) / 2
+	x, y := origin.X()*texels, origin.Y()*texels
+	projection[12] += (float32(math.Floor(float64(x)+0.5)) - x) / texels
+	projection[13] += (float32(math.Floor(float64(y)+0.5)) - y) / texels
+	return projection, view
+}
+
+// textureMatrix converts from the light's clip space to texture coordinates in a cascade's part of the shadow map,
+// which holds each of count cascades side by side. Depth is converted from [-1,1] to [0,1] to match the depth buffer.
+func textureMatrix(cascade, count int) mgl32.Mat4 {
+	tile := mgl32.Translate3D(float32(cascade)/float32(count), 0, 0).Mul4(mgl32.Scale3D(1/float32(count), 1, 1))
+	return tile.Mul4(mgl32.Translate3D(0.5, 0.5, 0.5)).Mul4(mgl32.Scale3D(0.5, 0.5, 0.5))
+}
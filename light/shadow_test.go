@@ -0,0 +1,156 @@
+package light
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestCascadeSplits(t *testing.T) {
+	tests := []struct {
+		near, far float32
+		count     int
+		weight    float32
+		want      []float32
+	}{
+		{1, 1000, 1, 0.5, []float32{1000}},
+		{1, 1000, 3, 0, []float32{334, 667, 1000}},
+		{1, 1000, 3, 1, []float32{10, 100, 1000}},
+		{1, 1000, 3, 0.5, []float32{172, 383.5, 1000}},
+		// Logarithmic splits don't work from zero, so they're even.
+		{0, 300, 3, 1, []float32{100, 200, 300}},
+	}
+	for _, tt := range tests {
+		got := cascadeSplits(tt.near, tt.far, tt.count, tt.weight)
+		if len(got) != len(tt.want) {
+			t.Fatalf("cascadeSplits(%v, %v, %v, %v) = %v, want %v", tt.near, tt.far, tt.count, tt.weight, got, tt.want)
+		}
+		for i := range got {
+			if math.Abs(float64(got[i]-tt.want[i])) > 1e-2 {
+				t.Errorf("cascadeSplits(%v, %v, %v, %v) = %v, want %v", tt.near, tt.far, tt.count, tt.weight, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFrustumSlice(t *testing.T) {
+	pMatrix := mgl32.Perspective(mgl32.DegToRad(60), 1.5, 1, 1000)
+	mvMatrix := mgl32.LookAtV(mgl32.Vec3{10, 20, 30}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
+	near, far, nearDepth, farDepth := frustumCorners(pMatrix, mvMatrix)
+	if math.Abs(float64(nearDepth-1)) > 1e-3 || math.Abs(float64(farDepth-1000)) > 0.5 {
+		t.Errorf("got near and far depths %v and %v, want 1 and 1000", nearDepth, farDepth)
+	}
+
+	corners := frustumSlice(near, far, nearDepth, farDepth, 10, 50)
+	projectionModelView := pMatrix.Mul4(mvMatrix)
+	for i, c := range corners {
+		wantDepth := float32(10)
+		if i >= 4 {
+			wantDepth = 50
+		}
+		if depth := -mvMatrix.Mul4x1(c.Vec4(1)).Z(); math.Abs(float64(depth-wantDepth)) > 1e-2 {
+			t.Errorf("got corner %d at depth %v, want %v", i, depth, wantDepth)
+		}
+		// The corners are on the edges of the view.
+		ndc := mgl32.TransformCoordinate(c, projectionModelView)
+		if math.Abs(math.Abs(float64(ndc.X()))-1) > 1e-3 || math.Abs(math.Abs(float64(ndc.Y()))-1) > 1e-3 {
+			t.Errorf("got corner %d at %v on screen, want it in a corner", i, ndc)
+		}
+	}
+}
+
+func TestFitLight(t *testing.T) {
+	const size = 512
+	direction := mgl32.Vec3{-1, -2, -0.5}
+	corners := [8]mgl32.Vec3{
+		{0, 0, 0}, {10, 0, 0}, {10, 10, 0}, {0, 10, 0},
+		{-20, -20, -50}, {30, -20, -50}, {30, 30, -50}, {-20, 30, -50},
+	}
+	projection, view := fitLight(direction, corners, size, 100)
+	lightMatrix := projection.Mul4(view)
+
+	// Snapping to texels can move the view by up to half a texel, which is 1/size in clip space.
+	const tolerance = 1.0 / size
+	for i, c := range corners {
+		p := mgl32.TransformCoordinate(c, lightMatrix)
+		for axis := 0; axis < 3; axis++ {
+			if p[axis] < -1-tolerance || p[axis] > 1+tolerance {
+				t.Errorf("got corner %d at %v in the light's view, want it within [-1,1]", i, p)
+				break
+			}
+		}
+	}
+
+	// Things between the corners and the light cast shadows onto them, so they're in view too.
+	caster := corners[0].Sub(direction.Normalize().Mul(90))
+	if p := mgl32.TransformCoordinate(caster, lightMatrix); p.Z() < -1 || p.Z() > 1 {
+		t.Errorf("got caster at depth %v in the light's view, want it within [-1,1]", p.Z())
+	}
+	// Further away toward the light is closer in depth.
+	if a, b := mgl32.TransformCoordinate(caster, lightMatrix), mgl32.TransformCoordinate(corners[0], lightMatrix); a.Z() >= b.Z() {
+		t.Errorf("got caster depth %v and surface depth %v, want the caster closer", a.Z(), b.Z())
+	}
+}
+
+func TestFitLightSnapsToTexels(t *testing.T) {
+	const size = 256
+	direction := mgl32.Vec3{1, -1, -1}
+	var corners [8]mgl32.Vec3
+	for i := range corners {
+		corners[i] = mgl32.Vec3{float32(i%2) * 40, float32(i/2%2) * 40, float32(i/4) * -40}
+	}
+
+	// Move the corners by small amounts, as if the camera was moving. The world's origin should always be on the
+	// corner of a texel, so texels stay in the same place in the world.
+	for step := 0; step < 10; step++ {
+		offset := mgl32.Vec3{0.37, 0.11, -0.23}.Mul(float32(step))
+		var moved [8]mgl32.Vec3
+		for i := range corners {
+			moved[i] = corners[i].Add(offset)
+		}
+		projection, view := fitLight(direction, moved, size, 0)
+		origin := projection.Mul4(view).Mul4x1(mgl32.Vec4{0, 0, 0, 1})
+		for axis := 0; axis < 2; axis++ {
+			texel := float64(origin[axis] * size / 2)
+			if math.Abs(texel-math.Floor(texel+0.5)) > 1e-2 {
+				t.Errorf("step %d: got origin at texel %v, want a whole number", step, texel)
+			}
+		}
+	}
+}
+
+func TestTextureMatrix(t *testing.T) {
+	// The corners of clip space map to the corners of the second of four tiles, with depth from 0 to 1.
+	m := textureMatrix(1, 4)
+	tests := []struct {
+		clip, want mgl32.Vec3
+	}{
+		{mgl32.Vec3{-1, -1, -1}, mgl32.Vec3{0.25, 0, 0}},
+		{mgl32.Vec3{1, 1, 1}, mgl32.Vec3{0.5, 1, 1}},
+		{mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0.375, 0.5, 0.5}},
+	}
+	for _, tt := range tests {
+		if got := mgl32.TransformCoordinate(tt.clip, m); got.Sub(tt.want).Len() > 1e-6 {
+			t.Errorf("got %v for %v, want %v", got, tt.clip, tt.want)
+		}
+	}
+}
+
+func TestCascadeSize(t *testing.T) {
+	tests := []struct {
+		size, cascades, maxTexture int
+		want                       int
+	}{
+		{size: 1024, cascades: 3, maxTexture: 4096, want: 1024},
+		{size: 2048, cascades: 3, maxTexture: 4096, want: 1365},
+		{size: 4096, cascades: 1, maxTexture: 4096, want: 4096},
+		{size: 2048, cascades: 4, maxTexture: 0, want: 2048},
+	}
+	for _, tt := range tests {
+		if got := cascadeSize(tt.size, tt.cascades, tt.maxTexture); got != tt.want {
+			t.Errorf("cascadeSize(%d, %d, %d) = %d, want %d", tt.size, tt.cascades, tt.maxTexture, got, tt.want)
+		}
+	}
+}
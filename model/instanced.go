@@ -0,0 +1,414 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/light"
+	"github.com/omustardo/gome/model/material"
+	"github.com/omustardo/gome/model/mesh"
+	"github.com/omustardo/gome/shader"
+	"github.com/omustardo/gome/util/glutil"
+)
+
+// Instance is a single copy of the mesh drawn by Instanced.
+type Instance struct {
+	entity.Entity
+	// Color multiplies the mesh's color and texture. Nil is white, which leaves them unchanged.
+	Color *color.NRGBA
+}
+
+// Instanced draws many copies of a mesh, each with its own position, rotation, scale, and color, in a handful of
+// draw calls. Drawing each copy as a Model takes a draw call each, which is slow for thousands of copies.
+//
+// If instanced drawing is supported, which it nearly always is, every copy is drawn with a single draw call.
+// Otherwise, or if Batch is set, the copies are merged into a few large meshes, which only works for meshes with
+// Data (see mesh.Mesh.Data) that are drawn with gl.TRIANGLES, gl.LINES, or gl.POINTS.
+// If neither works, each copy is drawn separately.
+//
+// Changes to Instances aren't seen until Update is called.
+// Instances are always drawn with the model shader, so the Program of the mesh's material isn't used.
+//
+// Sample usage:
+//   cubes := model.NewInstanced(mesh.NewCube(nil, gl.Texture{}), nil)
+//   for i := 0; i < 10000; i++ {
+//     cubes.Instances = append(cubes.Instances, model.Instance{
+//       Entity: entity.Entity{Position: mgl32.Vec3{float32(i % 100), float32(i / 100), 0}.Mul(200), Rotation: mgl32.QuatIdent(), Scale: mgl32.Vec3{100, 100, 100}},
+//       Color:  &color.NRGBA{util.RandUint8(), util.RandUint8(), util.RandUint8(), 255},
+//     })
+//   }
+//   if err := cubes.Update(); err != nil {
+//     log.Println(err)
+//   }
+//   for { // game loop
+//     cubes.Render()
+//   }
+type Instanced struct {
+	// Tag is a human readable string for debugging.
+	Tag string
+
+	// Hidden determines whether the instances will be rendered or not. False by default.
+	Hidden bool
+
+	Mesh      mesh.Mesh
+	Instances []Instance
+
+	// Batch merges the copies into large meshes even if instanced drawing is supported. Merged meshes are slow to
+	// update, but are the fastest to draw, so this is good for scenery that doesn't change.
+	Batch bool
+
+	method  drawMethod
+	updated bool
+	// instanceBuffer holds the position, rotation, scale, and color of each instance. See instanceData.
+	instanceBuffer gl.Buffer
+	batches        []batch
+	// center and radius are a sphere around every instance, for picking lights.
+	center mgl32.Vec3
+	radius float32
+}
+
+// drawMethod is how Instanced draws its copies.
+type drawMethod int
+
+const (
+	drawInstanced drawMethod = iota
+	drawBatched
+	drawSeparately
+)
+
+// instanceFloats is the number of values in instanceData for each instance: position, rotation, scale, and color.
+const instanceFloats = 3 + 4 + 3 + 4
+
+// batch is a merged mesh on the GPU.
+type batch struct {
+	vertices, normals, tangents, textureCoords, colors, indices gl.Buffer
+	count                                                      int
+}
+
+// NewInstanced creates a group of copies of a mesh. Instances can be nil, and added later.
+func NewInstanced(m mesh.Mesh, instances []Instance) *Instanced {
+	return &Instanced{
+		Mesh:      m,
+		Instances: instances,
+	}
+}
+
+// Update sends the instances to the GPU. It must be called after changing the instances or the mesh, and is called
+// automatically the first time they're rendered.
+//
+// An error is returned if the instances need to be merged but the mesh can't be, in which case each instance is drawn
+// separately.
+func (in *Instanced) Update() error {
+	in.Delete()
+	in.updated = true
+	in.center, in.radius = instanceBounds(in.Instances, in.Mesh.Radius())
+	if len(in.Instances) == 0 {
+		return nil
+	}
+	if !in.Batch && glutil.InstancingSupported() {
+		in.method = drawInstanced
+		in.instanceBuffer = glutil.LoadBufferFloat32(instanceData(in.Instances, in.Mesh.BaseRotation))
+		return nil
+	}
+
+	batches, err := batchInstances(in.Mesh.Data(), in.Mesh.VBOMode(), in.Instances, in.Mesh.BaseRotation)
+	if err != nil {
+		in.method = drawSeparately
+		return err
+	}
+	in.method = drawBatched
+	for _, b := range batches {
+		in.batches = append(in.batches, batch{
+			vertices:      glutil.LoadBufferVec3(b.vertices),
+			normals:       glutil.LoadBufferVec3(b.normals),
+			tangents:      glutil.LoadBufferVec4(b.tangents),
+			textureCoords: glutil.LoadBufferVec2(b.textureCoords),
+			colors:        glutil.LoadBufferVec4(b.colors),
+			indices:       glutil.LoadIndexBuffer(b.indices),
+			count:         len(b.indices),
+		})
+	}
+	return nil
+}
+
+// Delete frees the instances' buffers on the GPU. They're created again if the instances are updated or rendered.
+func (in *Instanced) Delete() {
+	if in.instanceBuffer.Valid() {
+		gl.DeleteBuffer(in.instanceBuffer)
+	}
+	for _, b := range in.batches {
+		for _, buf := range []gl.Buffer{b.vertices, b.normals, b.tangents, b.textureCoords, b.colors, b.indices} {
+			gl.DeleteBuffer(buf)
+		}
+	}
+	in.instanceBuffer = gl.Buffer{}
+	in.batches = nil
+	in.updated = false
+}
+
+// BoundingSphere returns a sphere that contains every instance. Each instance is sized like Model.BoundingSphere.
+func (in *Instanced) BoundingSphere() (center mgl32.Vec3, radius float32) {
+	if !in.updated {
+		return instanceBounds(in.Instances, in.Mesh.Radius())
+	}
+	return in.center, in.radius
+}
+
+func (in *Instanced) Render() {
+	if in.Hidden || len(in.Instances) == 0 {
+		return
+	}
+	if !in.Mesh.VertexVBO().Valid() {
+		log.Println("Attempted to draw instances with no vertices")
+		return
+	}
+	if !in.updated {
+		if err := in.Update(); err != nil {
+			log.Printf("Drawing %d instances separately: %v", len(in.Instances), err)
+		}
+	}
+
+	light.Lights.Apply(in.center, in.radius)
+	// Each instance has its own transform, so the model matrices don't change anything.
+	shader.Model.SetTranslationMatrix(0, 0, 0)
+	shader.Model.SetRotationMatrix(0, 0, 0)
+	shader.Model.SetScaleMatrix(1, 1, 1)
+	mat := in.Mesh.Material
+	if mat == nil {
+		mat = material.Default
+	}
+	mat.Apply(in.Mesh.Color, in.Mesh.Texture())
+	shader.Model.SetInstanced(true)
+	defer shader.Model.SetInstanced(false)
+
+	switch in.method {
+	case drawInstanced:
+		in.renderInstanced()
+	case drawBatched:
+		in.renderBatched()
+	default:
+		in.renderSeparately()
+	}
+}
+
+// instanceAttribs are the model shader's instance attributes, along with how many values they each have.
+func instanceAttribs() []struct {
+	attrib gl.Attrib
+	size   int
+} {
+	return []struct {
+		attrib gl.Attrib
+		size   int
+	}{
+		{shader.Model.InstancePositionAttrib, 3},
+		{shader.Model.InstanceRotationAttrib, 4},
+		{shader.Model.InstanceScaleAttrib, 3},
+		{shader.Model.InstanceColorAttrib, 4},
+	}
+}
+
+// renderInstanced draws every instance with one draw call, reading each one's values from the instance buffer.
+func (in *Instanced) renderInstanced() {
+	bindAttributes(in.Mesh.VertexVBO(), in.Mesh.NormalVBO(), in.Mesh.TangentVBO(), in.Mesh.TextureCoords())
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, in.instanceBuffer)
+	offset := 0
+	for _, a := range instanceAttribs() {
+		gl.EnableVertexAttribArray(a.attrib)
+		gl.VertexAttribPointer(a.attrib, a.size, gl.FLOAT, false, instanceFloats*4, offset*4)
+		glutil.VertexAttribDivisor(a.attrib, 1) // Move to the next value once per instance, rather than once per vertex.
+		offset += a.size
+	}
+
+	if in.Mesh.VertexIndices().Valid() {
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, in.Mesh.VertexIndices())
+		glutil.DrawElementsInstanced(in.Mesh.VBOMode(), in.Mesh.ItemCount(), gl.UNSIGNED_SHORT, 0, len(in.Instances))
+	} else {
+		glutil.DrawArraysInstanced(in.Mesh.VBOMode(), 0, in.Mesh.ItemCount(), len(in.Instances))
+	}
+
+	// Divisors apply to everything drawn with the attribute, so reset them for other draw calls.
+	for _, a := range instanceAttribs() {
+		glutil.VertexAttribDivisor(a.attrib, 0)
+		gl.DisableVertexAttribArray(a.attrib)
+	}
+}
+
+// renderBatched draws the merged meshes. Their vertices are already transformed, so the instance transform is set to
+// do nothing, and only the color is read for each vertex.
+func (in *Instanced) renderBatched() {
+	gl.VertexAttrib3f(shader.Model.InstancePositionAttrib, 0, 0, 0)
+	gl.VertexAttrib4f(shader.Model.InstanceRotationAttrib, 0, 0, 0, 1)
+	gl.VertexAttrib3f(shader.Model.InstanceScaleAttrib, 1, 1, 1)
+	for _, b := range in.batches {
+		bindAttributes(b.vertices, b.normals, b.tangents, b.textureCoords)
+		gl.BindBuffer(gl.ARRAY_BUFFER, b.colors)
+		gl.EnableVertexAttribArray(shader.Model.InstanceColorAttrib)
+		gl.VertexAttribPointer(shader.Model.InstanceColorAttrib, 4, gl.FLOAT, false, 0, 0)
+		draw(in.Mesh.VBOMode(), b.count, b.indices)
+	}
+	gl.DisableVertexAttribArray(shader.Model.InstanceColorAttrib)
+}
+
+// renderSeparately draws each instance with its own draw call. Instance attributes are set to constant values for
+// each one, which is slow but works with any mesh.
+func (in *Instanced) renderSeparately() {
+	bindAttributes(in.Mesh.VertexVBO(), in.Mesh.NormalVBO(), in.Mesh.TangentVBO(), in.Mesh.TextureCoords())
+	data := instanceData(in.Instances, in.Mesh.BaseRotation)
+	for i := 0; i < len(data); i += instanceFloats {
+		v := data[i : i+instanceFloats]
+		gl.VertexAttrib3f(shader.Model.InstancePositionAttrib, v[0], v[1], v[2])
+		gl.VertexAttrib4f(shader.Model.InstanceRotationAttrib, v[3], v[4], v[5], v[6])
+		gl.VertexAttrib3f(shader.Model.InstanceScaleAttrib, v[7], v[8], v[9])
+		gl.VertexAttrib4f(shader.Model.InstanceColorAttrib, v[10], v[11], v[12], v[13])
+		draw(in.Mesh.VBOMode(), in.Mesh.ItemCount(), in.Mesh.VertexIndices())
+	}
+}
+
+// instanceData lays out the position, rotation, scale, and color of each instance one after another, as the model
+// shader's instance attributes read them. baseRotation is the mesh's BaseRotation.
+func instanceData(instances []Instance, baseRotation mgl32.Quat) []float32 {
+	data := make([]float32, 0, instanceFloats*len(instances))
+	for _, inst := range instances {
+		r := inst.Rotation.Mul(baseRotation)
+		c := colorVec4(inst.Color)
+		data = append(data,
+			inst.Position[0], inst.Position[1], inst.Position[2],
+			r.V[0], r.V[1], r.V[2], r.W,
+			inst.Scale[0], inst.Scale[1], inst.Scale[2],
+			c[0], c[1], c[2], c[3],
+		)
+	}
+	return data
+}
+
+// batchData is a merged mesh in memory.
+type batchData struct {
+	vertices, normals []mgl32.Vec3
+	tangents          []mgl32.Vec4
+	textureCoords     []mgl32.Vec2
+	colors            []mgl32.Vec4
+	indices           []uint16
+}
+
+// maxBatchVertices is the most vertices in a batch, since indices are 16 bits.
+const maxBatchVertices = math.MaxUint16 + 1
+
+// batchInstances transforms a copy of the mesh data for each instance, and merges them into as few meshes as
+// possible. Missing normals, tangents, and texture coordinates are filled with zeros so every batch has all of them.
+func batchInstances(data *mesh.Data, mode gl.Enum, instances []Instance, baseRotation mgl32.Quat) ([]batchData, error) {
+	if data == nil || len(data.Vertices) == 0 {
+		return nil, errors.New("mesh has no Data to merge")
+	}
+	switch mode {
+	case gl.TRIANGLES, gl.LINES, gl.POINTS:
+	default:
+		// Strips, fans, and loops connect every vertex, so separate copies can't be merged into one.
+		return nil, fmt.Errorf("meshes drawn with mode 0x%x can't be merged", int(mode))
+	}
+	n := len(data.Vertices)
+	if n > maxBatchVertices {
+		return nil, fmt.Errorf("mesh has %d vertices, which is more than fit in a batch (%d)", n, maxBatchVertices)
+	}
+	indices := data.Indices
+	if len(indices) == 0 {
+		indices = make([]uint16, n)
+		for i := range indices {
+			indices[i] = uint16(i)
+		}
+	}
+
+	perBatch := maxBatchVertices / n
+	var batches []batchData
+	for start := 0; start < len(instances); start += perBatch {
+		end := start + perBatch
+		if end > len(instances) {
+			end = len(instances)
+		}
+		var b batchData
+		for _, inst := range instances[start:end] {
+			offset := uint16(len(b.vertices))
+			for _, i := range indices {
+				b.indices = append(b.indices, offset+i)
+			}
+
+			r := inst.Rotation.Mul(baseRotation)
+			// Normals are divided by the scale to keep them perpendicular to their surfaces. See shader.NormalMatrix.
+			var inverseScale mgl32.Vec3
+			for axis := 0; axis < 3; axis++ {
+				inverseScale[axis] = 1
+				if inst.Scale[axis] != 0 {
+					inverseScale[axis] = 1 / inst.Scale[axis]
+				}
+			}
+			c := colorVec4(inst.Color)
+			for i, v := range data.Vertices {
+				b.vertices = append(b.vertices, r.Rotate(mulElem(v, inst.Scale)).Add(inst.Position))
+				var normal mgl32.Vec3
+				if i < len(data.Normals) {
+					normal = normalize(r.Rotate(mulElem(data.Normals[i], inverseScale)))
+				}
+				b.normals = append(b.normals, normal)
+				var tangent mgl32.Vec4
+				if i < len(data.Tangents) {
+					t := data.Tangents[i]
+					tangent = normalize(r.Rotate(mulElem(t.Vec3(), inst.Scale))).Vec4(t.W())
+				}
+				b.tangents = append(b.tangents, tangent)
+				var uv mgl32.Vec2
+				if i < len(data.TextureCoords) {
+					uv = data.TextureCoords[i]
+				}
+				b.textureCoords = append(b.textureCoords, uv)
+				b.colors = append(b.colors, c)
+			}
+		}
+		batches = append(batches, b)
+	}
+	return batches, nil
+}
+
+// instanceBounds returns a sphere around every instance of a mesh with the given Radius, treating each one as a sphere
+// like Model.BoundingSphere.
+func instanceBounds(instances []Instance, meshRadius float32) (center mgl32.Vec3, radius float32) {
+	if len(instances) == 0 {
+		return mgl32.Vec3{}, 0
+	}
+	inf := float32(math.Inf(1))
+	min, max := mgl32.Vec3{inf, inf, inf}, mgl32.Vec3{-inf, -inf, -inf}
+	for _, inst := range instances {
+		r := meshRadius * maxScale(inst.Scale)
+		for axis := 0; axis < 3; axis++ {
+			min[axis] = float32(math.Min(float64(min[axis]), float64(inst.Position[axis]-r)))
+			max[axis] = float32(math.Max(float64(max[axis]), float64(inst.Position[axis]+r)))
+		}
+	}
+	return min.Add(max).Mul(0.5), max.Sub(min).Len() / 2
+}
+
+// colorVec4 converts a color to values from 0 to 1. Nil is white.
+func colorVec4(c *color.NRGBA) mgl32.Vec4 {
+	if c == nil {
+		return mgl32.Vec4{1, 1, 1, 1}
+	}
+	return mgl32.Vec4{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255, float32(c.A) / 255}
+}
+
+// mulElem multiplies two vectors component by component.
+func mulElem(a, b mgl32.Vec3) mgl32.Vec3 {
+	return mgl32.Vec3{a[0] * b[0], a[1] * b[1], a[2] * b[2]}
+}
+
+// normalize normalizes a vector, or returns zero for vectors with no length.
+func normalize(v mgl32.Vec3) mgl32.Vec3 {
+	if v.Len() == 0 {
+		return v
+	}
+	return v.Normalize()
+}
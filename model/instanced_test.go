@@ -0,0 +1,137 @@
+package model
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/model/mesh"
+)
+
+func testInstance(position, scale mgl32.Vec3, rotation mgl32.Quat, c *color.NRGBA) Instance {
+	return Instance{
+		Entity: entity.Entity{Position: position, Rotation: rotation, Scale: scale},
+		Color:  c,
+	}
+}
+
+func TestInstanceData(t *testing.T) {
+	rotation := mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{0, 0, 1})
+	instances := []Instance{
+		testInstance(mgl32.Vec3{1, 2, 3}, mgl32.Vec3{4, 5, 6}, rotation, &color.NRGBA{255, 0, 51, 255}),
+		testInstance(mgl32.Vec3{}, mgl32.Vec3{1, 1, 1}, mgl32.QuatIdent(), nil),
+	}
+	got := instanceData(instances, mgl32.QuatIdent())
+	want := []float32{
+		1, 2, 3, rotation.V[0], rotation.V[1], rotation.V[2], rotation.W, 4, 5, 6, 1, 0, 0.2, 1,
+		0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d values, want %d", len(got), len(want))
+	}
+	for i := range got {
+		if math.Abs(float64(got[i]-want[i])) > 1e-6 {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestBatchInstances(t *testing.T) {
+	data := &mesh.Data{
+		Vertices: []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+		Normals:  []mgl32.Vec3{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}},
+		Indices:  []uint16{0, 1, 2},
+	}
+	quarterTurn := mgl32.QuatRotate(mgl32.DegToRad(90), mgl32.Vec3{1, 0, 0})
+	instances := []Instance{
+		testInstance(mgl32.Vec3{10, 0, 0}, mgl32.Vec3{2, 2, 2}, mgl32.QuatIdent(), &color.NRGBA{255, 0, 0, 255}),
+		testInstance(mgl32.Vec3{0, 10, 0}, mgl32.Vec3{1, 1, 1}, quarterTurn, nil),
+	}
+	batches, err := batchInstances(data, gl.TRIANGLES, instances, mgl32.QuatIdent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 1 {
+		t.Fatalf("got %d batches, want 1", len(batches))
+	}
+	b := batches[0]
+
+	wantVertices := []mgl32.Vec3{{10, 0, 0}, {12, 0, 0}, {10, 2, 0}, {0, 10, 0}, {1, 10, 0}, {0, 10, 1}}
+	wantNormals := []mgl32.Vec3{{0, 0, 1}, {0, 0, 1}, {0, 0, 1}, {0, -1, 0}, {0, -1, 0}, {0, -1, 0}}
+	for i := range wantVertices {
+		if b.vertices[i].Sub(wantVertices[i]).Len() > 1e-5 {
+			t.Errorf("got vertex %d at %v, want %v", i, b.vertices[i], wantVertices[i])
+		}
+		if b.normals[i].Sub(wantNormals[i]).Len() > 1e-5 {
+			t.Errorf("got normal %d of %v, want %v", i, b.normals[i], wantNormals[i])
+		}
+	}
+	if want := []uint16{0, 1, 2, 3, 4, 5}; len(b.indices) != len(want) || b.indices[3] != want[3] || b.indices[5] != want[5] {
+		t.Errorf("got indices %v, want %v", b.indices, want)
+	}
+	if b.colors[0] != (mgl32.Vec4{1, 0, 0, 1}) || b.colors[3] != (mgl32.Vec4{1, 1, 1, 1}) {
+		t.Errorf("got colors %v, want red and then white", b.colors)
+	}
+	// Missing values are filled in so every vertex has them.
+	if len(b.tangents) != 6 || len(b.textureCoords) != 6 {
+		t.Errorf("got %d tangents and %d texture coordinates, want 6 of each", len(b.tangents), len(b.textureCoords))
+	}
+}
+
+func TestBatchInstancesSplits(t *testing.T) {
+	// Without indices, each vertex is drawn once in order.
+	data := &mesh.Data{Vertices: make([]mgl32.Vec3, 30000)}
+	instances := make([]Instance, 5)
+	for i := range instances {
+		instances[i] = testInstance(mgl32.Vec3{}, mgl32.Vec3{1, 1, 1}, mgl32.QuatIdent(), nil)
+	}
+	batches, err := batchInstances(data, gl.POINTS, instances, mgl32.QuatIdent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two copies fit in each batch of up to 65536 vertices.
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(batches))
+	}
+	if n := len(batches[2].vertices); n != 30000 {
+		t.Errorf("got %d vertices in the last batch, want 30000", n)
+	}
+	if last := batches[1].indices[len(batches[1].indices)-1]; last != 59999 {
+		t.Errorf("got last index %d, want 59999", last)
+	}
+}
+
+func TestBatchInstancesErrors(t *testing.T) {
+	instances := []Instance{testInstance(mgl32.Vec3{}, mgl32.Vec3{1, 1, 1}, mgl32.QuatIdent(), nil)}
+	triangle := &mesh.Data{Vertices: []mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}}
+	if _, err := batchInstances(nil, gl.TRIANGLES, instances, mgl32.QuatIdent()); err == nil {
+		t.Error("got no error for a mesh with no data")
+	}
+	if _, err := batchInstances(triangle, gl.TRIANGLE_STRIP, instances, mgl32.QuatIdent()); err == nil {
+		t.Error("got no error for a triangle strip")
+	}
+	if _, err := batchInstances(&mesh.Data{Vertices: make([]mgl32.Vec3, maxBatchVertices+1)}, gl.POINTS, instances, mgl32.QuatIdent()); err == nil {
+		t.Error("got no error for a mesh with too many vertices")
+	}
+}
+
+func TestInstanceBounds(t *testing.T) {
+	instances := []Instance{
+		testInstance(mgl32.Vec3{-10, 0, 0}, mgl32.Vec3{1, 1, 1}, mgl32.QuatIdent(), nil),
+		testInstance(mgl32.Vec3{10, 0, 0}, mgl32.Vec3{2, 2, 2}, mgl32.QuatIdent(), nil),
+	}
+	center, radius := instanceBounds(instances, 3)
+	for _, inst := range instances {
+		r := 3 * maxScale(inst.Scale)
+		if d := inst.Position.Sub(center).Len() + r; d > radius+1e-4 {
+			t.Errorf("instance at %v reaches %v from the center, outside the radius %v", inst.Position, d, radius)
+		}
+	}
+	if center, radius := instanceBounds(nil, 1); center != (mgl32.Vec3{}) || radius != 0 {
+		t.Errorf("got %v and %v for no instances, want zeros", center, radius)
+	}
+}
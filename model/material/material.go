@@ -0,0 +1,224 @@
+// Package material describes how the surface of a mesh looks: its colors, how shiny it is, whether it's affected by
+// lights, and how it blends with what's behind it.
+package material
+
+import (
+	"image/color"
+	"log"
+
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/shader"
+)
+
+// BlendMode is how a material's colors combine with what's already been drawn behind it.
+type BlendMode int
+
+const (
+	// AlphaBlend mixes colors based on their alpha, so translucent parts show what's behind them. This is the default.
+	AlphaBlend BlendMode = iota
+	// Opaque ignores alpha and covers whatever is behind it.
+	Opaque
+	// Additive adds colors to what's behind them, which is good for glowing things like fire and lasers.
+	Additive
+	// Multiply multiplies colors with what's behind them, which is good for shadows and tinted glass.
+	Multiply
+)
+
+// Material describes the surface of a mesh. Create one using New unless you know what you're doing, since a zero
+// Opacity is invisible.
+//
+// Sample usage:
+//   gold := material.New()
+//   gold.Color = &color.NRGBA{255, 200, 60, 255}
+//   gold.Specular = &color.NRGBA{255, 240, 200, 255}
+//   gold.Shininess = 64
+//   coin := model.Model{Mesh: mesh.NewCircle(nil, gl.Texture{}), Entity: entity.Default()}
+//   coin.Material = gold
+type Material struct {
+	// Color is the diffuse color, which tints the texture. Nil uses the mesh's Color.
+	Color *color.NRGBA
+	// Texture is the diffuse texture. If it isn't valid, the mesh's texture is used.
+	Texture gl.Texture
+
+	// Specular is the color of highlights from lights. Nil means no highlights.
+	Specular *color.NRGBA
+	// Shininess is how tight the highlights are. Larger values make smaller, sharper highlights, like polished metal.
+	// Smaller values make broad highlights, like plastic. Common values are between 8 and 256.
+	Shininess float32
+	// SpecularMap varies highlights across the surface. Its color multiplies Specular, and its alpha multiplies
+	// Shininess, so it works as a gloss map too. It's optional.
+	SpecularMap gl.Texture
+
+	// NormalMap adds detail to the lighting of a surface without more vertices, by bending its normals. It's optional,
+	// and only works for meshes with tangents, which meshes loaded from files have. See mesh.GenerateTangents.
+	NormalMap gl.Texture
+
+	// Emissive is light given off by the material itself, so it's visible even in the dark. Nil means none.
+	// This doesn't light anything else. Attach a light from the light package for that.
+	Emissive *color.NRGBA
+
+	// Opacity multiplies the alpha of the color and texture. 0 is invisible and 1 is fully opaque.
+	Opacity float32
+
+	// Unlit materials ignore all lights and show their color and texture as they are. This is good for text,
+	// user interfaces, and 2D games.
+	Unlit bool
+
+	// DoubleSided materials are drawn from both sides. Normally the back sides of triangles aren't drawn since they're
+	// usually hidden inside a closed mesh, but flat meshes like leaves and paper need both sides.
+	DoubleSided bool
+
+	// Blend is how the material combines with what's behind it.
+	Blend BlendMode
+
+	// Translucent marks materials with textures that are partly see through, which can't be detected from the
+	// material's colors. See Transparent.
+	Translucent bool
+
+	// Program draws meshes with this material instead of the model shader, for custom effects. The material's
+	// properties are sent to it using the same uniform names as the model shader. See shader.Program.
+	Program *shader.Program
+}
+
+// New creates an opaque material that's lit by lights and has no highlights.
+func New() *Material {
+	return &Material{
+		Opacity:   1,
+		Shininess: 32,
+	}
+}
+
+// NewUnlit creates a material that isn't affected by lights.
+func NewUnlit() *Material {
+	m := New()
+	m.Unlit = true
+	return m
+}
+
+// Default is used for meshes without a material. It shouldn't be modified.
+var Default = New()
+
+// Transparent returns whether things behind the material show through it, so it needs to be drawn after everything
+// behind it. That's true for materials that add or multiply colors, and for blended materials that are Translucent
+// or that have an alpha or opacity below 1. meshColor is used if the material doesn't set a color.
+func (m *Material) Transparent(meshColor *color.NRGBA) bool {
+	switch m.Blend {
+	case Opaque:
+		return false
+	case Additive, Multiply:
+		return true
+	}
+	c := m.Color
+	if c == nil {
+		c = meshColor
+	}
+	return m.Translucent || m.Opacity < 1 || (c != nil && c.A < 255)
+}
+
+// current is the blending and culling state that was most recently set, so it's only changed when necessary.
+// These match the defaults set in view.Initialize.
+var current = struct {
+	blend       BlendMode
+	doubleSided bool
+}{AlphaBlend, false}
+
+// Apply sends the material to the model shader and sets up blending and culling, so it's used for the following
+// draw calls. Meshes that don't set a color or texture in the material provide them.
+// Models call this when they're rendered, so it only needs to be called directly when drawing without a model.
+func (m *Material) Apply(meshColor *color.NRGBA, meshTexture gl.Texture) {
+	diffuse := m.Color
+	if diffuse == nil {
+		diffuse = meshColor
+	}
+	texture := m.Texture
+	if !texture.Valid() {
+		texture = meshTexture
+	}
+	shader.Model.SetColor(diffuse)
+	shader.Model.SetTexture(texture)
+	shader.Model.SetSpecular(m.Specular, m.Shininess)
+	shader.Model.SetSpecularMap(m.SpecularMap)
+	shader.Model.SetNormalMap(m.NormalMap)
+	shader.Model.SetEmissive(m.Emissive)
+	shader.Model.SetOpacity(m.Opacity)
+	shader.Model.SetUnlit(m.Unlit)
+	m.applyState()
+}
+
+// ApplyProgram is like Apply, but sends the material to a custom program rather than the model shader. Properties
+// are only sent if the program has a uniform for them. Models with a program call this when they're rendered.
+func (m *Material) ApplyProgram(p *shader.Program, meshColor *color.NRGBA, meshTexture gl.Texture) {
+	diffuse := m.Color
+	if diffuse == nil {
+		diffuse = meshColor
+	}
+	texture := m.Texture
+	if !texture.Valid() {
+		texture = meshTexture
+	}
+	p.SetColor(diffuse)
+	p.SetTexture(texture)
+	p.SetSpecularMap(m.SpecularMap)
+	p.SetNormalMap(m.NormalMap)
+	set := func(name string, value interface{}) {
+		if err := p.SetIfActive(name, value); err != nil {
+			log.Println(err)
+		}
+	}
+	set("uSpecularColor", orBlack(m.Specular))
+	set("uShininess", m.Shininess)
+	set("uEmissive", orBlack(m.Emissive))
+	set("uOpacity", m.Opacity)
+	set("uUnlit", m.Unlit)
+	m.applyState()
+}
+
+// orBlack returns black for nil colors, which is what the model shader uses for unset specular and emissive colors.
+func orBlack(c *color.NRGBA) *color.NRGBA {
+	if c == nil {
+		return &color.NRGBA{0, 0, 0, 255}
+	}
+	return c
+}
+
+// applyState sets up blending and culling for the material.
+func (m *Material) applyState() {
+	if m.DoubleSided != current.doubleSided {
+		if m.DoubleSided {
+			gl.Disable(gl.CULL_FACE)
+		} else {
+			gl.Enable(gl.CULL_FACE)
+		}
+		current.doubleSided = m.DoubleSided
+	}
+	if m.Blend != current.blend {
+		setBlend(m.Blend)
+		current.blend = m.Blend
+	}
+}
+
+func setBlend(mode BlendMode) {
+	enabled, src, dst := blendState(mode)
+	if !enabled {
+		gl.Disable(gl.BLEND)
+		return
+	}
+	gl.Enable(gl.BLEND)
+	gl.BlendFunc(src, dst)
+}
+
+// blendState returns whether blending is enabled for a BlendMode, and the source and destination blend factors.
+func blendState(mode BlendMode) (enabled bool, src, dst gl.Enum) {
+	switch mode {
+	case Opaque:
+		return false, gl.ONE, gl.ZERO
+	case Additive:
+		return true, gl.SRC_ALPHA, gl.ONE
+	case Multiply:
+		// The result is the source color times the destination color. Nothing else is added, or white would tint
+		// things instead of leaving them unchanged.
+		return true, gl.DST_COLOR, gl.ZERO
+	default:
+		return true, gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA
+	}
+}
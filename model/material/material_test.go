@@ -0,0 +1,26 @@
+package material
+
+import (
+	"testing"
+
+	"github.com/goxjs/gl"
+)
+
+func TestBlendState(t *testing.T) {
+	tests := []struct {
+		mode     BlendMode
+		enabled  bool
+		src, dst gl.Enum
+	}{
+		{AlphaBlend, true, gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA},
+		{Opaque, false, gl.ONE, gl.ZERO},
+		{Additive, true, gl.SRC_ALPHA, gl.ONE},
+		{Multiply, true, gl.DST_COLOR, gl.ZERO},
+	}
+	for _, test := range tests {
+		enabled, src, dst := blendState(test.mode)
+		if enabled != test.enabled || src != test.src || dst != test.dst {
+			t.Errorf("blendState(%d) = %v, %#x, %#x, want %v, %#x, %#x", test.mode, enabled, src, dst, test.enabled, test.src, test.dst)
+		}
+	}
+}
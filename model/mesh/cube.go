@@ -134,7 +134,9 @@ func initializeCube() Mesh {
 	normalBuffer := glutil.LoadBufferFloat32(normals)
 	textureCoordBuffer := glutil.LoadBufferFloat32(textureCoordinates)
 
-	return NewMesh(vertexBuffer, indexBuffer, normalBuffer, gl.TRIANGLES, 36, nil, emptyTexture, textureCoordBuffer)
+	m := NewMesh(vertexBuffer, indexBuffer, normalBuffer, gl.TRIANGLES, 36, nil, emptyTexture, textureCoordBuffer)
+	m.SetData(&Data{Vertices: vec3s(vertices), Normals: vec3s(normals), TextureCoords: vec2s(textureCoordinates), Indices: indices})
+	return m
 }
 
 // NewCube returns a Mesh of a unit cube (all sides length 1) centered at the origin.
@@ -0,0 +1,69 @@
+package mesh
+
+import "github.com/go-gl/mathgl/mgl32"
+
+// Data is a copy of a mesh's vertices that's kept in memory. WebGL can't read buffers back from the GPU, so this is
+// needed by anything that works with the vertices themselves, like batching many meshes into one.
+type Data struct {
+	Vertices []mgl32.Vec3
+	// Normals, TextureCoords, and Tangents have one value for each vertex, or are empty if the mesh doesn't have them.
+	Normals       []mgl32.Vec3
+	TextureCoords []mgl32.Vec2
+	Tangents      []mgl32.Vec4
+	// Indices are the order to draw the vertices in. If empty, they're drawn in order.
+	Indices []uint16
+
+	// radius caches the distance to the furthest vertex, once measured is set. See Mesh.Radius.
+	radius   float32
+	measured bool
+}
+
+// Data returns the copy of the mesh's vertices in memory, or nil if it doesn't have one. Meshes made from arrays and
+// most built in meshes have one.
+func (m *Mesh) Data() *Data {
+	return m.data
+}
+
+// SetData sets the copy of the mesh's vertices in memory. It doesn't change the buffers on the GPU, so it must match
+// them.
+func (m *Mesh) SetData(data *Data) {
+	m.data = data
+}
+
+// Radius returns the distance from the origin to the mesh's furthest vertex, so the mesh fits within a sphere of that
+// radius. It's measured from Data the first time it's needed, so changing Data's vertices afterward doesn't change it.
+// Meshes without Data are assumed to fit within a radius of 1, which is true of the built in meshes and meshes loaded
+// with normalization.
+func (m *Mesh) Radius() float32 {
+	if m.data == nil {
+		return 1
+	}
+	if !m.data.measured {
+		var max float32
+		for _, v := range m.data.Vertices {
+			if l := v.Len(); l > max {
+				max = l
+			}
+		}
+		m.data.radius, m.data.measured = max, true
+	}
+	return m.data.radius
+}
+
+// vec3s converts a flat list of XYZ values into vectors.
+func vec3s(values []float32) []mgl32.Vec3 {
+	v := make([]mgl32.Vec3, len(values)/3)
+	for i := range v {
+		v[i] = mgl32.Vec3{values[3*i], values[3*i+1], values[3*i+2]}
+	}
+	return v
+}
+
+// vec2s converts a flat list of XY values into vectors.
+func vec2s(values []float32) []mgl32.Vec2 {
+	v := make([]mgl32.Vec2, len(values)/2)
+	for i := range v {
+		v[i] = mgl32.Vec2{values[2*i], values[2*i+1]}
+	}
+	return v
+}
@@ -25,7 +25,9 @@ func initializeIcosahedron() Mesh {
 	//texCoords := circleTexCoords(numCircleSegments)
 	//texCoordsVBO := glutil.LoadBufferVec2(texCoords)
 
-	return NewMesh(vertexVBO, gl.Buffer{}, normalVBO, gl.TRIANGLES, 20*3, nil, gl.Texture{}, gl.Buffer{})
+	m := NewMesh(vertexVBO, gl.Buffer{}, normalVBO, gl.TRIANGLES, 20*3, nil, gl.Texture{}, gl.Buffer{})
+	m.SetData(&Data{Vertices: vertices, Normals: normals})
+	return m
 }
 
 // NewIcosahedron returns a mesh for a 20 sided figure.
@@ -130,7 +132,9 @@ func NewSubdividedIcosahedron(divisions int, col *color.NRGBA, texture gl.Textur
 			//texCoords := circleTexCoords(numCircleSegments)
 			//texCoordsVBO := glutil.LoadBufferVec2(texCoords)
 
-			subdividedIcosahedron[i] = NewMesh(vertexVBO, gl.Buffer{}, normalVBO, gl.TRIANGLES, len(vertices), nil, gl.Texture{}, gl.Buffer{})
+			m := NewMesh(vertexVBO, gl.Buffer{}, normalVBO, gl.TRIANGLES, len(vertices), nil, gl.Texture{}, gl.Buffer{})
+			m.SetData(&Data{Vertices: vertices, Normals: normals})
+			subdividedIcosahedron[i] = m
 		}
 		// Divide each face into four faces and continue.
 		newFaces := make([][3]mgl32.Vec3, 0, 4*len(faces))
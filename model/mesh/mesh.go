@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/model/material"
 	"github.com/omustardo/gome/util/glutil"
 )
 
@@ -33,7 +34,6 @@ var (
 func Initialize() {
 	fmt.Println("Generating Meshes...")
 	initializeEmptyTexture()
-	initializeEmptyBuffer()
 
 	rect = initializeRect()
 	cube = initializeCube()
@@ -47,8 +47,9 @@ func Initialize() {
 
 type Mesh struct {
 	// Buffers are all private in order to set valid defaults for provided buffers that haven't been initialized.
-	// For example, if the provided texture buffer is empty (no values provided), the setters replaces it with a default
-	// texture containing all 0 values.
+	// For example, if the provided texture is empty (no values provided), the setter replaces it with a default
+	// white texture. Normals, tangents, and texture coordinates that aren't provided are left invalid, and models
+	// use a constant zero for them instead.
 	// The purpose of these defaults is so the same shader can be used regardless of a few missing fields.
 
 	// References to buffers on the GPU.
@@ -56,6 +57,10 @@ type Mesh struct {
 	vertexIndices gl.Buffer
 
 	normals gl.Buffer
+	// tangents are used for normal mapping. See GenerateTangents.
+	tangents gl.Buffer
+	// data is a copy of the vertices in memory. It's nil if they're only on the GPU.
+	data *Data
 
 	texture       gl.Texture
 	textureCoords gl.Buffer
@@ -74,6 +79,11 @@ type Mesh struct {
 	// I recommend just accessing the RGBA fields directly.
 	Color *color.NRGBA
 
+	// Material describes how the surface looks, like how shiny it is and whether it's affected by lights.
+	// It's optional. Leaving it nil uses material.Default, with the mesh's Color and texture.
+	// Colors and textures set in the material take precedence over the mesh's.
+	Material *material.Material
+
 	// BaseRotation is a rotation applied to the mesh.
 	// This is intended to be used to orient the mesh so Up toward {0,1,0}, and Forward is towards {1,0,0}, since not all
 	// meshes will be created in this orientation. Note that if you don't want to modify the default rotation, this must
@@ -87,18 +97,31 @@ type Mesh struct {
 }
 
 // NewMeshFromArrays copies the input vertices, normals, and texture coordinates into buffers on the GPU.
+// If there's a normal and texture coordinate for every vertex, tangents are generated so the mesh supports normal maps.
 func NewMeshFromArrays(vertices, normals []mgl32.Vec3, textureCoords []mgl32.Vec2) (Mesh, error) {
-	var vertexBuffer, uvBuffer, normalBuffer gl.Buffer
+	var vertexBuffer, uvBuffer, normalBuffer, tangentBuffer gl.Buffer
 
 	vertexBuffer = glutil.LoadBufferVec3(vertices)
-	normalBuffer = glutil.LoadBufferVec3(normals)
-	uvBuffer = glutil.LoadBufferVec2(textureCoords)
+	// Missing normals and texture coordinates are left invalid, so they're zero for every vertex.
+	if len(normals) > 0 {
+		normalBuffer = glutil.LoadBufferVec3(normals)
+	}
+	if len(textureCoords) > 0 {
+		uvBuffer = glutil.LoadBufferVec2(textureCoords)
+	}
+	tangents := GenerateTangents(vertices, normals, textureCoords)
+	if tangents != nil {
+		tangentBuffer = glutil.LoadBufferVec4(tangents)
+	}
 
 	if glError := gl.GetError(); glError != 0 {
 		return Mesh{}, fmt.Errorf("gl.GetError: %v", glError)
 	}
 
-	return NewMesh(vertexBuffer, gl.Buffer{}, normalBuffer, gl.TRIANGLES, len(vertices), nil, gl.Texture{}, uvBuffer), nil
+	m := NewMesh(vertexBuffer, gl.Buffer{}, normalBuffer, gl.TRIANGLES, len(vertices), nil, gl.Texture{}, uvBuffer)
+	m.SetTangentVBO(tangentBuffer)
+	m.SetData(&Data{Vertices: vertices, Normals: normals, TextureCoords: textureCoords, Tangents: tangents})
+	return m, nil
 }
 
 // NewMesh combines the input buffers and rendering information into a Mesh struct.
@@ -118,6 +141,7 @@ func NewMesh(vertices, vertexIndices, normals gl.Buffer, vboMode gl.Enum, itemCo
 		BaseRotation:  mgl32.QuatIdent(),
 	}
 	m.SetNormalVBO(normals)
+	m.SetTangentVBO(gl.Buffer{})
 	m.SetTexture(texture)
 	m.SetTextureCoords(textureCoords)
 	return m
@@ -129,14 +153,24 @@ func (m *Mesh) VertexVBO() gl.Buffer {
 func (m *Mesh) VertexIndices() gl.Buffer {
 	return m.vertexIndices
 }
+// NormalVBO returns the buffer of normals, which has three floats per vertex. It isn't valid if the mesh doesn't have
+// normals.
 func (m *Mesh) NormalVBO() gl.Buffer {
 	return m.normals
 }
 func (m *Mesh) SetNormalVBO(normals gl.Buffer) {
 	m.normals = normals
-	if !m.normals.Valid() {
-		m.normals = emptyBuffer
-	}
+}
+
+// TangentVBO returns the buffer of tangents, which has four floats per vertex. It isn't valid if the mesh doesn't have
+// tangents. Meshes without tangents use zeros, which means normal maps have no effect on them.
+func (m *Mesh) TangentVBO() gl.Buffer {
+	return m.tangents
+}
+
+// SetTangentVBO sets the buffer of tangents used for normal mapping. See GenerateTangents.
+func (m *Mesh) SetTangentVBO(tangents gl.Buffer) {
+	m.tangents = tangents
 }
 
 // SetVBOMode allows changing what mode a mesh is rendered in. It's recommended not to use this on the built in meshes
@@ -160,34 +194,20 @@ func (m *Mesh) SetTexture(texture gl.Texture) {
 		m.texture = emptyTexture
 	}
 }
+// TextureCoords returns the buffer of texture coordinates, which has two floats per vertex. It isn't valid if the mesh
+// doesn't have texture coordinates.
 func (m *Mesh) TextureCoords() gl.Buffer {
 	return m.textureCoords
 }
 func (m *Mesh) SetTextureCoords(coords gl.Buffer) {
 	m.textureCoords = coords
-	if !m.textureCoords.Valid() {
-		m.textureCoords = emptyBuffer
-	}
 }
 
-const (
-	emptyBufferSize = 1024 * 1024 * 3
-)
-
 var (
-	// TODO: Using these "empty" default buffers is ugly. I'm not sure how to do better though. If this is the way to go
-	// make sure to document the size limitations as any meshes with undefined features that are larger than the size
-	// limits will likely be hard to detect and diagnose.
-
 	// EmptyTexture is a texture buffer filled with just four bytes: [255, 255, 255, 255]
 	// It is meant to be used as in meshes that don't contain another texture.
+	// Meshes without texture coordinates use zeros, which all reference its single pixel.
 	emptyTexture gl.Texture
-
-	// EmptyNormals is a buffer on the GPU containing many zeros. This is used as a default for meshes that don't have the
-	// relevant values defined, but still need to use a buffer since the shader requires having some values.
-	// When used as a normal buffer, it means the vertices have no normal, which doesn't make logical sense, but seems to work in practice.
-	// When used as a texture coordinate buffer in combination with emptyTexture, the zero values are used as coordinates and all reference a single pixel in the texture.
-	emptyBuffer gl.Buffer
 )
 
 func initializeEmptyTexture() {
@@ -198,11 +218,6 @@ func initializeEmptyTexture() {
 	}
 }
 
-func initializeEmptyBuffer() {
-	data := make([]float32, emptyBufferSize) // large array of 0 values
-	emptyBuffer = glutil.LoadBufferFloat32(data)
-}
-
 // subdivideTriangle takes a triangle as input and returns the four triangles created by subdividing it.
 func subdivideTriangle(tri [3]mgl32.Vec3) [][3]mgl32.Vec3 {
 	// Get the three midpoints
@@ -23,31 +23,37 @@ func NewRectOutline(col *color.NRGBA) Mesh {
 func initializeRect() Mesh {
 	// Store basic rectangle vertices in a buffer.
 	lower, upper := float32(-0.5), float32(0.5)
-	vertexVBO := glutil.LoadBufferFloat32([]float32{
+	vertices := []float32{
 		lower, lower, 0,
 		upper, lower, 0,
 		upper, upper, 0,
 		lower, upper, 0,
-	})
+	}
+	vertexVBO := glutil.LoadBufferFloat32(vertices)
 
-	indexBuffer := glutil.LoadIndexBuffer([]uint16{0, 1, 2, 0, 2, 3})
+	indices := []uint16{0, 1, 2, 0, 2, 3}
+	indexBuffer := glutil.LoadIndexBuffer(indices)
 
 	// Normals for a 2D object extend perpendicular to the plane it lives on.
-	normalVBO := glutil.LoadBufferFloat32([]float32{
+	normals := []float32{
 		0, 0, 1,
 		0, 0, 1,
 		0, 0, 1,
 		0, 0, 1,
-	})
+	}
+	normalVBO := glutil.LoadBufferFloat32(normals)
 
-	textureCoordBuffer := glutil.LoadBufferFloat32([]float32{
+	textureCoords := []float32{
 		0.0, 0.0,
 		1.0, 0.0,
 		1.0, 1.0,
 		0.0, 1.0,
-	})
+	}
+	textureCoordBuffer := glutil.LoadBufferFloat32(textureCoords)
 
-	return NewMesh(vertexVBO, indexBuffer, normalVBO, gl.TRIANGLES, 6, nil, gl.Texture{}, textureCoordBuffer)
+	m := NewMesh(vertexVBO, indexBuffer, normalVBO, gl.TRIANGLES, 6, nil, gl.Texture{}, textureCoordBuffer)
+	m.SetData(&Data{Vertices: vec3s(vertices), Normals: vec3s(normals), TextureCoords: vec2s(textureCoords), Indices: indices})
+	return m
 }
 
 func initializeWireframeRect() Mesh {
@@ -30,7 +30,9 @@ func initializeSpheres(maxDetail int) {
 			//texCoordsVBO := glutil.LoadBufferVec2(texCoords)
 
 			// Use vertexVBO as the normalVBO to smooth out polygon edges.
-			spheres[i] = NewMesh(vertexVBO, gl.Buffer{}, vertexVBO, gl.TRIANGLES, len(vertices), nil, gl.Texture{}, gl.Buffer{})
+			m := NewMesh(vertexVBO, gl.Buffer{}, vertexVBO, gl.TRIANGLES, len(vertices), nil, gl.Texture{}, gl.Buffer{})
+			m.SetData(&Data{Vertices: vertices, Normals: vertices})
+			spheres[i] = m
 		}
 		// Divide each face into four faces and continue.
 		newFaces := make([][3]mgl32.Vec3, 0, 4*len(faces))
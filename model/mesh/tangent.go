@@ -0,0 +1,129 @@
+package mesh
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// GenerateTangents returns a tangent for each vertex of a list of triangles, which is needed for normal mapping.
+// Every three vertices make up a triangle, and normals and textureCoords must have one value per vertex.
+//
+// The tangents follow the same conventions as MikkTSpace, which is what most tools bake normal maps with:
+// XYZ points along increasing U of the texture, perpendicular to the normal, and W is +1 or -1 to give the handedness.
+// The bitangent, which points along increasing V, is W * cross(normal, tangent).
+// Like MikkTSpace, each triangle's contribution is weighted by its angle at the vertex, and vertices with the same
+// position, normal, and texture coordinate share their tangent so lighting is smooth across triangles. Vertices where
+// the texture is mirrored aren't shared, so mirrored seams stay sharp.
+//
+// Returns nil if the input lengths don't match.
+func GenerateTangents(vertices, normals []mgl32.Vec3, textureCoords []mgl32.Vec2) []mgl32.Vec4 {
+	if len(vertices)%3 != 0 || len(normals) != len(vertices) || len(textureCoords) != len(vertices) {
+		return nil
+	}
+
+	// Vertices that are the same, including which way their texture faces, are grouped to share a tangent.
+	type key struct {
+		position, normal mgl32.Vec3
+		uv               mgl32.Vec2
+		mirrored         bool
+	}
+	type sum struct {
+		tangent, bitangent mgl32.Vec3
+	}
+	groups := make(map[key]*sum)
+	vertexGroups := make([]*sum, len(vertices))
+
+	for tri := 0; tri < len(vertices); tri += 3 {
+		p := vertices[tri : tri+3]
+		uv := textureCoords[tri : tri+3]
+		e1, e2 := p[1].Sub(p[0]), p[2].Sub(p[0])
+		du1, dv1 := uv[1].X()-uv[0].X(), uv[1].Y()-uv[0].Y()
+		du2, dv2 := uv[2].X()-uv[0].X(), uv[2].Y()-uv[0].Y()
+
+		// Solve for the directions of increasing U and V on the triangle's surface.
+		var tangent, bitangent mgl32.Vec3
+		r := du1*dv2 - du2*dv1
+		if r != 0 {
+			tangent = e1.Mul(dv2).Sub(e2.Mul(dv1)).Mul(1 / r)
+			bitangent = e2.Mul(du1).Sub(e1.Mul(du2)).Mul(1 / r)
+		}
+
+		for corner := 0; corner < 3; corner++ {
+			i := tri + corner
+			k := key{vertices[i], normals[i], textureCoords[i], r < 0}
+			s, ok := groups[k]
+			if !ok {
+				s = &sum{}
+				groups[k] = s
+			}
+			vertexGroups[i] = s
+
+			// Weight by the angle of the triangle at this corner, so splitting a face into more triangles doesn't change the result.
+			a, b := p[(corner+1)%3].Sub(p[corner]), p[(corner+2)%3].Sub(p[corner])
+			weight := angleBetween(a, b)
+			s.tangent = s.tangent.Add(normalizeOrZero(tangent).Mul(weight))
+			s.bitangent = s.bitangent.Add(normalizeOrZero(bitangent).Mul(weight))
+		}
+	}
+
+	tangents := make([]mgl32.Vec4, len(vertices))
+	for i, s := range vertexGroups {
+		n := normalizeOrZero(normals[i])
+		// Make the tangent perpendicular to the normal.
+		t := alongSurface(s.tangent, n)
+		if t.Len() == 0 {
+			// The tangent points straight out of the surface, so use the bitangent to find it instead.
+			t = alongSurface(s.bitangent, n).Cross(n)
+		}
+		if t.Len() == 0 {
+			// The texture coordinates don't give a direction, so any direction along the surface will do.
+			t = perpendicular(n)
+		}
+		w := float32(1)
+		if n.Cross(t).Dot(s.bitangent) < 0 {
+			w = -1
+		}
+		tangents[i] = t.Vec4(w)
+	}
+	return tangents
+}
+
+// alongSurface returns the unit length part of v that's perpendicular to the normal n, or zero if v is too close to
+// being parallel to n to tell.
+func alongSurface(v, n mgl32.Vec3) mgl32.Vec3 {
+	flat := v.Sub(n.Mul(n.Dot(v)))
+	if flat.Len() <= 1e-4*v.Len() {
+		return mgl32.Vec3{}
+	}
+	return normalizeOrZero(flat)
+}
+
+// angleBetween returns the angle between two vectors in radians, or 0 if either has no length.
+func angleBetween(a, b mgl32.Vec3) float32 {
+	if a.Len() == 0 || b.Len() == 0 {
+		return 0
+	}
+	return float32(math.Acos(float64(mgl32.Clamp(a.Normalize().Dot(b.Normalize()), -1, 1))))
+}
+
+// normalizeOrZero normalizes a vector, or returns zero for vectors too short to normalize.
+func normalizeOrZero(v mgl32.Vec3) mgl32.Vec3 {
+	if l := v.Len(); l > 1e-12 {
+		return v.Mul(1 / l)
+	}
+	return mgl32.Vec3{}
+}
+
+// perpendicular returns a unit vector perpendicular to v. If v has no length, it returns the X axis.
+func perpendicular(v mgl32.Vec3) mgl32.Vec3 {
+	// Cross with whichever axis is least aligned with v, so the result is never too short.
+	axis := mgl32.Vec3{1, 0, 0}
+	if math.Abs(float64(v.X())) > 0.9 {
+		axis = mgl32.Vec3{0, 1, 0}
+	}
+	if p := normalizeOrZero(v.Cross(axis)); p.Len() > 0 {
+		return p
+	}
+	return mgl32.Vec3{1, 0, 0}
+}
@@ -0,0 +1,90 @@
+package mesh
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// quad returns two triangles making a unit square in the XY plane facing +Z, with the provided texture coordinates
+// for its bottom left, bottom right, top right, and top left corners.
+func quad(uvs [4]mgl32.Vec2) (vertices, normals []mgl32.Vec3, textureCoords []mgl32.Vec2) {
+	corners := [4]mgl32.Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	for _, i := range []int{0, 1, 2, 0, 2, 3} {
+		vertices = append(vertices, corners[i])
+		normals = append(normals, mgl32.Vec3{0, 0, 1})
+		textureCoords = append(textureCoords, uvs[i])
+	}
+	return vertices, normals, textureCoords
+}
+
+func TestGenerateTangents(t *testing.T) {
+	tests := []struct {
+		name string
+		uvs  [4]mgl32.Vec2
+		want mgl32.Vec4
+	}{
+		{"standard", [4]mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, mgl32.Vec4{1, 0, 0, 1}},
+		// U increases downward, and V increases to the right.
+		{"rotated", [4]mgl32.Vec2{{1, 0}, {1, 1}, {0, 1}, {0, 0}}, mgl32.Vec4{0, -1, 0, 1}},
+		// Flipping the texture horizontally reverses both the tangent and the handedness.
+		{"mirrored", [4]mgl32.Vec2{{1, 0}, {0, 0}, {0, 1}, {1, 1}}, mgl32.Vec4{-1, 0, 0, -1}},
+	}
+	for _, tt := range tests {
+		vertices, normals, uvs := quad(tt.uvs)
+		tangents := GenerateTangents(vertices, normals, uvs)
+		if len(tangents) != len(vertices) {
+			t.Fatalf("%s: got %d tangents for %d vertices", tt.name, len(tangents), len(vertices))
+		}
+		for i, got := range tangents {
+			if got.Sub(tt.want).Len() > 1e-5 {
+				t.Errorf("%s: got tangent %v for vertex %d, want %v", tt.name, got, i, tt.want)
+			}
+		}
+	}
+}
+
+func TestGenerateTangentsSmoothed(t *testing.T) {
+	// A strip that bends up along the line X=1, with the texture running across the bend. The vertices on the bend have
+	// normals between the two triangles' normals, so lighting is smooth, and they share a tangent.
+	flat, tilted := mgl32.Vec3{0, 0, 1}, mgl32.Vec3{-1, 0, 1}.Normalize()
+	bend := flat.Add(tilted).Normalize()
+	vertices := []mgl32.Vec3{
+		{0, 0, 0}, {1, 0, 0}, {1, 1, 0},
+		{1, 0, 0}, {2, 0, 1}, {1, 1, 0},
+	}
+	normals := []mgl32.Vec3{flat, bend, bend, bend, tilted, bend}
+	uvs := []mgl32.Vec2{{0, 0}, {1, 0}, {1, 1}, {1, 0}, {2, 0}, {1, 1}}
+	tangents := GenerateTangents(vertices, normals, uvs)
+
+	if tangents[1] != tangents[3] || tangents[2] != tangents[5] {
+		t.Errorf("got tangents %v and %v for the same vertex, want them shared", tangents[1], tangents[3])
+	}
+	for i, tangent := range tangents {
+		if dot := tangent.Vec3().Dot(normals[i]); math.Abs(float64(dot)) > 1e-5 {
+			t.Errorf("got tangent %v for vertex %d, which isn't perpendicular to its normal %v", tangent, i, normals[i])
+		}
+		if l := tangent.Vec3().Len(); math.Abs(float64(l-1)) > 1e-5 {
+			t.Errorf("got tangent %v for vertex %d with length %v, want 1", tangent, i, l)
+		}
+		// U increases along +X on both triangles, and V along +Y.
+		if tangent.X() <= 0 || tangent.W() != 1 {
+			t.Errorf("got tangent %v for vertex %d, want it pointing along +X with positive handedness", tangent, i)
+		}
+	}
+}
+
+func TestGenerateTangentsDegenerate(t *testing.T) {
+	// Every corner has the same texture coordinate, so there's no direction for the tangent to point.
+	vertices, normals, uvs := quad([4]mgl32.Vec2{})
+	for i, tangent := range GenerateTangents(vertices, normals, uvs) {
+		if tangent.Vec3().Dot(normals[i]) != 0 || math.Abs(float64(tangent.Vec3().Len()-1)) > 1e-5 {
+			t.Errorf("got tangent %v for vertex %d, want any unit vector along the surface", tangent, i)
+		}
+	}
+
+	if got := GenerateTangents(vertices, normals, uvs[:3]); got != nil {
+		t.Errorf("got %v with mismatched inputs, want nil", got)
+	}
+}
@@ -3,10 +3,13 @@ package model
 import (
 	"image/color"
 	"log"
+	"math"
 
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/goxjs/gl"
 	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/light"
+	"github.com/omustardo/gome/model/material"
 	"github.com/omustardo/gome/model/mesh"
 	"github.com/omustardo/gome/shader"
 )
@@ -19,6 +22,11 @@ type Model struct {
 	// Hidden determines whether the model will be rendered or not. False by default.
 	Hidden bool
 
+	// Program draws the model instead of the model shader, for custom effects. It overrides the material's Program.
+	// Custom programs get the same lights as the model shader, but aren't shadowed. They aren't used for shadow maps,
+	// so the model still casts a plain shadow. See shader.Program.
+	Program *shader.Program
+
 	mesh.Mesh
 	entity.Entity
 }
@@ -40,30 +48,120 @@ func (m *Model) Render() {
 		return
 	}
 
+	mat := m.Mesh.Material
+	if mat == nil {
+		mat = material.Default
+	}
+	if program := m.program(mat); program != nil && !shader.Model.DepthOnly() {
+		m.renderProgram(program, mat)
+		return
+	}
+
+	light.Lights.Apply(m.BoundingSphere())
 	shader.Model.SetTranslationMatrix(m.Position.X(), m.Position.Y(), m.Position.Z())
 	shader.Model.SetRotationMatrixQ(m.Rotation.Mul(m.Mesh.BaseRotation))
 	shader.Model.SetScaleMatrix(m.Scale.X(), m.Scale.Y(), m.Scale.Z())
-	shader.Model.SetColor(m.Mesh.Color)
-	shader.Model.SetTexture(m.Mesh.Texture())
+	mat.Apply(m.Mesh.Color, m.Mesh.Texture())
+
+	bindAttributes(m.Mesh.VertexVBO(), m.Mesh.NormalVBO(), m.Mesh.TangentVBO(), m.Mesh.TextureCoords())
+	draw(m.VBOMode(), m.ItemCount(), m.Mesh.VertexIndices())
+}
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, m.Mesh.VertexVBO())
+// bindAttributes points the model shader's per-vertex attributes at the provided buffers.
+func bindAttributes(vertices, normals, tangents, textureCoords gl.Buffer) {
+	gl.BindBuffer(gl.ARRAY_BUFFER, vertices)
 	gl.EnableVertexAttribArray(shader.Model.VertexPositionAttrib) // TODO: Can these VertexAttribArrays be enabled a single time in shader initialization and then just always used?
 	gl.VertexAttribPointer(shader.Model.VertexPositionAttrib, 3, gl.FLOAT, false, 0, 0)
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, m.Mesh.NormalVBO())
-	gl.EnableVertexAttribArray(shader.Model.NormalAttrib)
-	gl.VertexAttribPointer(shader.Model.NormalAttrib, 3, gl.FLOAT, false, 0, 0)
+	bindAttribute(shader.Model.NormalAttrib, normals, 3)
+	bindAttribute(shader.Model.TangentAttrib, tangents, 4)
+	bindAttribute(shader.Model.TextureCoordAttrib, textureCoords, 2)
+}
 
-	gl.BindBuffer(gl.ARRAY_BUFFER, m.Mesh.TextureCoords())
-	gl.EnableVertexAttribArray(shader.Model.TextureCoordAttrib)
-	gl.VertexAttribPointer(shader.Model.TextureCoordAttrib, 2, gl.FLOAT, false, 0, 0)
+// bindAttribute points a per-vertex attribute at a buffer with size floats per vertex. Meshes don't always have
+// normals, tangents, or texture coordinates, so if the buffer isn't valid every vertex uses zero instead.
+func bindAttribute(a gl.Attrib, buffer gl.Buffer, size int) {
+	if !buffer.Valid() {
+		gl.DisableVertexAttribArray(a)
+		gl.VertexAttrib4f(a, 0, 0, 0, 0)
+		return
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, buffer)
+	gl.EnableVertexAttribArray(a)
+	gl.VertexAttribPointer(a, size, gl.FLOAT, false, 0, 0)
+}
 
-	if m.VertexIndices().Valid() {
-		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, m.Mesh.VertexIndices())
-		gl.DrawElements(m.VBOMode(), m.ItemCount(), gl.UNSIGNED_SHORT, 0)
+// program returns the custom program the model is drawn with, or nil for the model shader.
+func (m *Model) program(mat *material.Material) *shader.Program {
+	if m.Program != nil {
+		return m.Program
+	}
+	return mat.Program
+}
+
+// renderProgram draws the model with a custom program. It gets the same camera as the model shader, which is set
+// with shader.Model.SetMVPMatrix.
+func (m *Model) renderProgram(p *shader.Program, mat *material.Material) {
+	pMatrix, mvMatrix := shader.Model.MVPMatrix()
+	p.SetMVPMatrix(pMatrix, mvMatrix)
+	p.SetTransform(m.Position, m.Rotation.Mul(m.Mesh.BaseRotation), m.Scale)
+	mat.ApplyProgram(p, m.Mesh.Color, m.Mesh.Texture())
+	center, radius := m.BoundingSphere()
+	light.Lights.ApplyProgram(p, center, radius)
+
+	bindProgramAttributes(p, m.Mesh.VertexVBO(), m.Mesh.NormalVBO(), m.Mesh.TangentVBO(), m.Mesh.TextureCoords())
+	draw(m.VBOMode(), m.ItemCount(), m.Mesh.VertexIndices())
+	// The program's attribute locations may be ones that the model shader leaves disabled, like its per-instance
+	// attributes, so don't leave them pointing at this mesh.
+	for _, a := range p.Attributes() {
+		gl.DisableVertexAttribArray(a.Location)
+	}
+}
+
+// bindProgramAttributes points the standard attributes of a custom program at the provided buffers. Other attributes
+// are disabled, so they use a constant value.
+func bindProgramAttributes(p *shader.Program, vertices, normals, tangents, textureCoords gl.Buffer) {
+	for _, a := range p.Attributes() {
+		switch a.Name {
+		case "aVertexPosition":
+			bindAttribute(a.Location, vertices, 3)
+		case "aNormal":
+			bindAttribute(a.Location, normals, 3)
+		case "aTangent":
+			bindAttribute(a.Location, tangents, 4)
+		case "aTextureCoord":
+			bindAttribute(a.Location, textureCoords, 2)
+		default:
+			gl.DisableVertexAttribArray(a.Location)
+		}
+	}
+}
+
+// draw draws count vertices from the bound attributes, in the order given by indices if they're valid.
+func draw(mode gl.Enum, count int, indices gl.Buffer) {
+	if indices.Valid() {
+		gl.BindBuffer(gl.ELEMENT_ARRAY_BUFFER, indices)
+		gl.DrawElements(mode, count, gl.UNSIGNED_SHORT, 0)
 	} else {
-		gl.DrawArrays(m.VBOMode(), 0, m.ItemCount())
+		gl.DrawArrays(mode, 0, count)
+	}
+}
+
+// BoundingSphere returns a sphere that contains the model. It's centered on the model's position, and sized by the
+// mesh's Radius and the model's largest scale.
+func (m *Model) BoundingSphere() (center mgl32.Vec3, radius float32) {
+	return m.Position, m.Mesh.Radius() * maxScale(m.Scale)
+}
+
+// maxScale returns the largest scale along any axis, ignoring whether it's flipped.
+func maxScale(scale mgl32.Vec3) float32 {
+	var max float32
+	for i := 0; i < 3; i++ {
+		if s := float32(math.Abs(float64(scale[i]))); s > max {
+			max = s
+		}
 	}
+	return max
 }
 
 const axisLength = 1e6
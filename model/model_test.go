@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/model/mesh"
+)
+
+func TestBoundingSphere(t *testing.T) {
+	m := &Model{Entity: entity.Entity{Position: mgl32.Vec3{1, 2, 3}, Scale: mgl32.Vec3{2, -4, 1}}}
+	// Without a copy of the vertices, the mesh is assumed to fit within a radius of 1.
+	if center, radius := m.BoundingSphere(); center != m.Position || radius != 4 {
+		t.Errorf("got center %v and radius %v, want %v and 4", center, radius, m.Position)
+	}
+
+	// A mesh that wasn't normalized is measured.
+	m.Mesh.SetData(&mesh.Data{Vertices: []mgl32.Vec3{{0, 0, 1}, {30, 0, -40}, {-10, 0, 0}}})
+	if _, radius := m.BoundingSphere(); radius != 200 {
+		t.Errorf("got radius %v, want 200", radius)
+	}
+}
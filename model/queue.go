@@ -0,0 +1,263 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/model/material"
+	"github.com/omustardo/gome/shader"
+)
+
+// LayerOptions changes how a layer in a Queue is drawn. The zero value draws with depth testing, like models drawn
+// directly.
+type LayerOptions struct {
+	// DisableDepthTest draws the layer without depth testing, so models cover whatever was drawn before them.
+	// Models in the layer are drawn in the order they were submitted. This is usually what 2D games want, since
+	// they're ordered by layer rather than by depth.
+	DisableDepthTest bool
+	// ClearDepth clears the depth buffer before drawing the layer, so it's drawn over all earlier layers while still
+	// using depth within the layer. This is good for things like a 3D user interface drawn on top of the world.
+	ClearDepth bool
+}
+
+// Queue collects models to draw each frame, and then draws them in an order that looks right and is fast to draw.
+//
+// Each model is submitted to a layer. Layers are drawn in increasing order, so higher layers are drawn over lower
+// ones. Within a layer, opaque models are drawn first, grouped by their programs, textures, meshes, and materials so
+// the GPU changes state as little as possible, and then from front to back so hidden parts are skipped early.
+// Transparent models are drawn after them from back to front, so they blend with everything behind them.
+// See material.Material.Transparent for which models are transparent.
+//
+// If Skybox is set, it's drawn with the first layer, after its opaque models and before its transparent ones.
+//
+// Sample usage:
+//   queue := model.NewQueue()
+//   queue.SetLayer(hudLayer, model.LayerOptions{ClearDepth: true})
+//   for { // game loop
+//     queue.Submit(0, terrain, player)
+//     queue.Submit(0, glassWindows...)
+//     queue.Submit(hudLayer, compass)
+//     queue.Flush(cam.ModelView())
+//   }
+type Queue struct {
+	// Skybox is drawn behind everything in the first layer. It can be nil.
+	Skybox *Skybox
+
+	layers map[int]LayerOptions
+	items  []queued
+}
+
+// queued is a single submitted model.
+type queued struct {
+	model *Model
+	layer int
+}
+
+// NewQueue creates an empty Queue where every layer uses the default LayerOptions.
+func NewQueue() *Queue {
+	return &Queue{
+		layers: make(map[int]LayerOptions),
+	}
+}
+
+// SetLayer sets how a layer is drawn. It applies until it's set again.
+func (q *Queue) SetLayer(layer int, opts LayerOptions) {
+	q.layers[layer] = opts
+}
+
+// Layer returns how a layer is drawn.
+func (q *Queue) Layer(layer int) LayerOptions {
+	return q.layers[layer]
+}
+
+// Submit adds models to be drawn in a layer. Nil models are ignored.
+func (q *Queue) Submit(layer int, models ...*Model) {
+	for _, m := range models {
+		if m == nil {
+			continue
+		}
+		q.items = append(q.items, queued{model: m, layer: layer})
+	}
+}
+
+// Len returns the number of models that have been submitted.
+func (q *Queue) Len() int {
+	return len(q.items)
+}
+
+// Reset removes all submitted models.
+func (q *Queue) Reset() {
+	q.items = q.items[:0]
+}
+
+// Flush draws every submitted model and then removes them, ready for the next frame.
+func (q *Queue) Flush(mvMatrix mgl32.Mat4) {
+	q.Render(mvMatrix)
+	q.Reset()
+}
+
+// Render draws every submitted model, using the camera's ModelView matrix to find how far away each one is.
+// The submitted models are kept, so they can be drawn again. For example, to draw a shadow map and then the scene.
+// Models are drawn with the model shader's current projection and ModelView matrices.
+func (q *Queue) Render(mvMatrix mgl32.Mat4) {
+	passes := q.order(mvMatrix)
+	sky := q.Skybox
+	for _, p := range passes {
+		// The sky only shows where nothing has been drawn with depth. Layers without depth testing don't write any,
+		// so the sky goes before them rather than covering them.
+		if sky != nil && (p.transparent || p.layer != passes[0].layer || p.opts.DisableDepthTest) {
+			sky.Render()
+			sky = nil
+		}
+		if p.opts.ClearDepth && p.first {
+			gl.Clear(gl.DEPTH_BUFFER_BIT)
+		}
+		if p.opts.DisableDepthTest {
+			gl.Disable(gl.DEPTH_TEST)
+		} else {
+			gl.Enable(gl.DEPTH_TEST)
+		}
+		// Transparent models are still hidden behind opaque ones, but they shouldn't hide each other since they're
+		// drawn in order from back to front.
+		gl.DepthMask(!p.transparent)
+		for _, m := range p.models {
+			m.Render()
+		}
+	}
+	if sky != nil {
+		sky.Render()
+	}
+	// Go back to the defaults set in view.Initialize.
+	gl.Enable(gl.DEPTH_TEST)
+	gl.DepthMask(true)
+}
+
+// pass is a group of models that are drawn with the same depth settings.
+type pass struct {
+	opts  LayerOptions
+	layer int
+	// first is true for the first pass of a layer.
+	first       bool
+	transparent bool
+	models      []*Model
+}
+
+// order sorts the submitted models into passes, in the order they should be drawn.
+func (q *Queue) order(mvMatrix mgl32.Mat4) []pass {
+	items := append([]queued(nil), q.items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].layer < items[j].layer
+	})
+
+	// Give each program, texture, mesh, and material an ID in the order they're first seen, so draws that share them
+	// can be grouped together. Textures and buffers can't be compared with less than, since they're JavaScript
+	// objects in the browser. A nil program is the model shader.
+	programs := make(map[*shader.Program]int)
+	textures := make(map[gl.Texture]int)
+	meshes := make(map[gl.Buffer]int)
+	materials := make(map[*material.Material]int)
+
+	var passes []pass
+	for start := 0; start < len(items); {
+		end := start
+		for end < len(items) && items[end].layer == items[start].layer {
+			end++
+		}
+		layer := items[start:end]
+		layerIndex := items[start].layer
+		opts := q.layers[layerIndex]
+		start = end
+
+		if opts.DisableDepthTest {
+			models := make([]*Model, len(layer))
+			for i, item := range layer {
+				models[i] = item.model
+			}
+			passes = append(passes, pass{opts: opts, layer: layerIndex, first: true, models: models})
+			continue
+		}
+
+		type sortable struct {
+			model                            *Model
+			depth                            float32
+			program, texture, mesh, material int
+		}
+		var opaque, transparent []sortable
+		for _, item := range layer {
+			m := item.model
+			mat := m.Mesh.Material
+			if mat == nil {
+				mat = material.Default
+			}
+			program := m.program(mat)
+			if _, ok := programs[program]; !ok {
+				programs[program] = len(programs)
+			}
+			texture := mat.Texture
+			if !texture.Valid() {
+				texture = m.Mesh.Texture()
+			}
+			if _, ok := textures[texture]; !ok {
+				textures[texture] = len(textures)
+			}
+			if _, ok := meshes[m.Mesh.VertexVBO()]; !ok {
+				meshes[m.Mesh.VertexVBO()] = len(meshes)
+			}
+			if _, ok := materials[mat]; !ok {
+				materials[mat] = len(materials)
+			}
+			s := sortable{
+				model:    m,
+				depth:    -mvMatrix.Mul4x1(m.Position.Vec4(1)).Z(),
+				program:  programs[program],
+				texture:  textures[texture],
+				mesh:     meshes[m.Mesh.VertexVBO()],
+				material: materials[mat],
+			}
+			if mat.Transparent(m.Mesh.Color) {
+				transparent = append(transparent, s)
+			} else {
+				opaque = append(opaque, s)
+			}
+		}
+
+		sort.SliceStable(opaque, func(i, j int) bool {
+			a, b := opaque[i], opaque[j]
+			// Switching programs costs the most, since every uniform has to be sent again.
+			if a.program != b.program {
+				return a.program < b.program
+			}
+			if a.texture != b.texture {
+				return a.texture < b.texture
+			}
+			if a.mesh != b.mesh {
+				return a.mesh < b.mesh
+			}
+			if a.material != b.material {
+				return a.material < b.material
+			}
+			return a.depth < b.depth
+		})
+		sort.SliceStable(transparent, func(i, j int) bool {
+			return transparent[i].depth > transparent[j].depth
+		})
+
+		first := true
+		for _, group := range []struct {
+			sorted      []sortable
+			transparent bool
+		}{{opaque, false}, {transparent, true}} {
+			if len(group.sorted) == 0 {
+				continue
+			}
+			models := make([]*Model, len(group.sorted))
+			for i, s := range group.sorted {
+				models[i] = s.model
+			}
+			passes = append(passes, pass{opts: opts, layer: layerIndex, first: first, transparent: group.transparent, models: models})
+			first = false
+		}
+	}
+	return passes
+}
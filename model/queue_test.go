@@ -0,0 +1,146 @@
+package model
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/core/entity"
+	"github.com/omustardo/gome/model/material"
+	"github.com/omustardo/gome/model/mesh"
+	"github.com/omustardo/gome/shader"
+)
+
+// newQueueModel creates a model with the provided material, at a distance in front of a camera with an identity
+// ModelView matrix.
+func newQueueModel(tag string, mat *material.Material, depth float32) *Model {
+	return &Model{
+		Tag:    tag,
+		Mesh:   mesh.Mesh{Material: mat},
+		Entity: entity.Entity{Position: mgl32.Vec3{0, 0, -depth}},
+	}
+}
+
+func tags(p pass) []string {
+	var t []string
+	for _, m := range p.models {
+		t = append(t, m.Tag)
+	}
+	return t
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQueueOrder(t *testing.T) {
+	red, blue := material.New(), material.New()
+	red.Color, blue.Color = &color.NRGBA{255, 0, 0, 255}, &color.NRGBA{0, 0, 255, 255}
+	glass := material.New()
+	glass.Opacity = 0.5
+	glow := material.New()
+	glow.Blend = material.Additive
+
+	q := NewQueue()
+	q.Submit(1, newQueueModel("hud", red, 1))
+	q.Submit(0,
+		newQueueModel("far red", red, 50),
+		newQueueModel("near glass", glass, 5),
+		newQueueModel("blue", blue, 20),
+		newQueueModel("near red", red, 10),
+		newQueueModel("far glow", glow, 30),
+		nil,
+	)
+	if q.Len() != 6 {
+		t.Errorf("got %d models in the queue, want 6", q.Len())
+	}
+
+	passes := q.order(mgl32.Ident4())
+	want := []struct {
+		tags        []string
+		first       bool
+		transparent bool
+	}{
+		// Opaque models are grouped by material, and then drawn front to back.
+		{[]string{"near red", "far red", "blue"}, true, false},
+		// Transparent models are drawn back to front.
+		{[]string{"far glow", "near glass"}, false, true},
+		// Higher layers are drawn last.
+		{[]string{"hud"}, true, false},
+	}
+	if len(passes) != len(want) {
+		t.Fatalf("got %d passes, want %d", len(passes), len(want))
+	}
+	for i, w := range want {
+		p := passes[i]
+		if !equalTags(tags(p), w.tags) || p.first != w.first || p.transparent != w.transparent {
+			t.Errorf("pass %d: got models %v, first=%v, transparent=%v. want %v, %v, %v", i, tags(p), p.first, p.transparent, w.tags, w.first, w.transparent)
+		}
+	}
+
+	q.Reset()
+	if q.Len() != 0 || len(q.order(mgl32.Ident4())) != 0 {
+		t.Errorf("got %d models after Reset, want 0", q.Len())
+	}
+}
+
+func TestQueueGroupsPrograms(t *testing.T) {
+	toon, water := &shader.Program{Name: "toon"}, &shader.Program{Name: "water"}
+	toonMaterial := material.New()
+	toonMaterial.Program = toon
+	plain := material.New()
+
+	waterModel := newQueueModel("water", plain, 30)
+	waterModel.Program = water // Overrides the material's program.
+	q := NewQueue()
+	q.Submit(0,
+		newQueueModel("toon 1", toonMaterial, 10),
+		newQueueModel("plain 1", plain, 20),
+		waterModel,
+		newQueueModel("toon 2", toonMaterial, 40),
+		newQueueModel("plain 2", plain, 50),
+	)
+
+	passes := q.order(mgl32.Ident4())
+	if len(passes) != 1 {
+		t.Fatalf("got %d passes, want 1", len(passes))
+	}
+	// Models with the same program are drawn together, in the order each program is first seen.
+	if got, want := tags(passes[0]), []string{"toon 1", "toon 2", "plain 1", "plain 2", "water"}; !equalTags(got, want) {
+		t.Errorf("got models %v, want %v", got, want)
+	}
+}
+
+func TestQueueWithoutDepthTest(t *testing.T) {
+	glass := material.New()
+	glass.Opacity = 0.5
+
+	q := NewQueue()
+	q.SetLayer(2, LayerOptions{DisableDepthTest: true})
+	// Without depth testing, 2D layers are drawn in the order they're submitted, regardless of depth or transparency.
+	q.Submit(2,
+		newQueueModel("background", nil, 10),
+		newQueueModel("window", glass, 50),
+		newQueueModel("player", nil, 100),
+	)
+	q.Submit(-1, newQueueModel("sky", nil, 1))
+
+	passes := q.order(mgl32.Ident4())
+	if len(passes) != 2 {
+		t.Fatalf("got %d passes, want 2", len(passes))
+	}
+	if got, want := tags(passes[0]), []string{"sky"}; !equalTags(got, want) || passes[0].opts.DisableDepthTest {
+		t.Errorf("got first pass %v with options %+v, want %v with depth testing", got, passes[0].opts, want)
+	}
+	if got, want := tags(passes[1]), []string{"background", "window", "player"}; !equalTags(got, want) || !passes[1].opts.DisableDepthTest {
+		t.Errorf("got second pass %v with options %+v, want %v without depth testing", got, passes[1].opts, want)
+	}
+}
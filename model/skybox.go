@@ -0,0 +1,78 @@
+package model
+
+import (
+	"log"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/model/mesh"
+	"github.com/omustardo/gome/shader"
+	"github.com/omustardo/gome/util/glutil"
+)
+
+// Skybox draws a cubemap around the camera, as a background that's infinitely far away. It turns with the camera, but
+// never gets closer no matter how far the camera moves.
+//
+// It should be drawn after opaque models, so it's only drawn where nothing else is, and before transparent ones,
+// so they blend with it. A Queue with its Skybox set does this.
+//
+// Sample usage:
+//   sky, err := asset.LoadCubemapCross("assets/sky.png")
+//   if err != nil {
+//     log.Fatal(err)
+//   }
+//   queue := model.NewQueue()
+//   queue.Skybox = model.NewSkybox(sky)
+type Skybox struct {
+	Cubemap *glutil.Cubemap
+	// Rotation turns the sky. Cubemaps have +Y up, so skies for worlds with +Z up should be turned 90 degrees around
+	// the X axis.
+	Rotation mgl32.Quat
+	Hidden   bool
+
+	cube mesh.Mesh
+}
+
+// NewSkybox creates an unrotated Skybox.
+func NewSkybox(cubemap *glutil.Cubemap) *Skybox {
+	return &Skybox{
+		Cubemap:  cubemap,
+		Rotation: mgl32.QuatIdent(),
+		cube:     mesh.NewCube(nil, gl.Texture{}),
+	}
+}
+
+// Render draws the sky from the point of view most recently set with shader.Model.SetMVPMatrix.
+// Nothing is drawn while drawing shadow maps, since the sky doesn't cast shadows.
+func (s *Skybox) Render() {
+	if s == nil || s.Hidden || shader.Model.DepthOnly() {
+		return
+	}
+	if s.Cubemap == nil || !s.Cubemap.Texture.Valid() {
+		log.Println("Attempted to draw a skybox with no cubemap")
+		return
+	}
+	pMatrix, mvMatrix := shader.Model.MVPMatrix()
+	shader.Skybox.SetMVPMatrix(pMatrix, mvMatrix, s.Rotation)
+	shader.Skybox.SetCubemap(s.Cubemap.Texture)
+
+	// The sky is at the far plane, which passes a depth test of less or equal against a cleared depth buffer. It
+	// doesn't write depth, so transparent models drawn afterward aren't hidden behind it.
+	// The cube is seen from inside, so culling is turned off rather than drawing nothing.
+	cull := gl.IsEnabled(gl.CULL_FACE)
+	gl.Disable(gl.CULL_FACE)
+	gl.DepthFunc(gl.LEQUAL)
+	gl.DepthMask(false)
+
+	gl.BindBuffer(gl.ARRAY_BUFFER, s.cube.VertexVBO())
+	gl.EnableVertexAttribArray(shader.Skybox.PositionAttrib)
+	gl.VertexAttribPointer(shader.Skybox.PositionAttrib, 3, gl.FLOAT, false, 0, 0)
+	draw(s.cube.VBOMode(), s.cube.ItemCount(), s.cube.VertexIndices())
+
+	// Go back to the defaults set in view.Initialize.
+	gl.DepthMask(true)
+	gl.DepthFunc(gl.LESS)
+	if cull {
+		gl.Enable(gl.CULL_FACE)
+	}
+}
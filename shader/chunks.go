@@ -0,0 +1,33 @@
+package shader
+
+// Built in chunks of shader source, which any shader can include. See RegisterChunk.
+const (
+	// quaternionChunk is "gome/quaternion.glsl".
+	quaternionChunk = `
+// rotate rotates a vector by a unit quaternion.
+vec3 rotate(vec4 q, vec3 v) {
+	return v + 2.0 * cross(q.xyz, cross(q.xyz, v) + q.w * v);
+}
+`
+	// depthChunk is "gome/depth.glsl".
+	depthChunk = `
+// packDepth spreads a depth from 0 to 1 across 24 bits of color, for when depth textures aren't supported.
+vec3 packDepth(float depth) {
+	// Exactly 1 would wrap around to 0.
+	vec3 enc = fract(min(depth, 0.99999) * vec3(1.0, 255.0, 65025.0));
+	return enc - enc.yzz * vec3(1.0 / 255.0, 1.0 / 255.0, 0.0);
+}
+
+// unpackDepth turns a color made by packDepth back into a depth.
+float unpackDepth(vec3 color) {
+	return dot(color, vec3(1.0, 1.0 / 255.0, 1.0 / 65025.0));
+}
+`
+	// colorChunk is "gome/color.glsl".
+	colorChunk = `
+// luminance is how bright a color looks. Eyes are most sensitive to green and least to blue.
+float luminance(vec3 color) {
+	return dot(color, vec3(0.2126, 0.7152, 0.0722));
+}
+`
+)
@@ -3,69 +3,334 @@ package shader
 import (
 	"errors"
 	"fmt"
+	"math"
+	"strconv"
 
 	"image/color"
 
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/goxjs/gl"
-	"github.com/goxjs/gl/glutil"
+	"github.com/omustardo/gome/util/glutil"
 )
 
+// MaxLights is the most lights that can affect a single draw call. Each light uses three uniform vectors in the
+// fragment shader. WebGL only guarantees 16 of those, but nearly every implementation supports many more.
+const MaxLights = 8
+
+// MaxShadowCascades is the most shadow maps that a directional light can split the camera's view into. Each one uses
+// four uniform vectors in the fragment shader.
+const MaxShadowCascades = 4
+
+// MaxShadowFilterRadius is the largest radius, in texels, that shadow edges can be softened by.
+const MaxShadowFilterRadius = 2
+
 const (
 	modelVertexSource = `
 attribute vec3 aVertexPosition;
 attribute vec3 aNormal;
+attribute vec4 aTangent; // xyz is the direction of increasing U. w is +1 or -1, the handedness of the bitangent.
 attribute vec2 aTextureCoord;
 
+// When drawing instances, each one has its own transform and color. Rotation is a quaternion.
+attribute vec3 aInstancePosition;
+attribute vec4 aInstanceRotation;
+attribute vec3 aInstanceScale;
+attribute vec4 aInstanceColor;
+uniform bool uInstanced;
+
 uniform mat4 uTranslationMatrix;
 uniform mat4 uRotationMatrix;
 uniform mat4 uScaleMatrix;
+// uNormalMatrix is the inverse transpose of the rotation and scale, which keeps normals perpendicular to their
+// surfaces when a model is scaled unevenly.
+uniform mat3 uNormalMatrix;
 
 uniform mat4 uMVMatrix;
 uniform mat4 uPMatrix;
 
-uniform vec3 uDiffuseLightDirection;
-uniform vec3 uDiffuseLightColor;
-
-varying vec3 vLighting;
+varying vec3 vWorldPosition;
+varying vec3 vNormal;
+varying vec4 vTangent;
 varying vec2 vTextureCoord;
+// vViewDepth is the distance in front of the camera, which picks the shadow cascade.
+varying float vViewDepth;
+varying vec4 vColor;
+
+#include "gome/quaternion.glsl"
 
 void main() {
 	// === Texture ===
 	vTextureCoord = aTextureCoord;
 
+	// === Instance ===
+	// Instances are transformed first, and then the whole group is transformed by the model matrices.
+	vec3 position = aVertexPosition;
+	vec3 normal = aNormal;
+	vec3 tangent = aTangent.xyz;
+	vColor = vec4(1.0);
+	if (uInstanced) {
+		position = rotate(aInstanceRotation, position * aInstanceScale) + aInstancePosition;
+		// Dividing by the scale keeps normals perpendicular to their surfaces, like the normal matrix. Flattened
+		// directions are treated as unscaled.
+		normal = rotate(aInstanceRotation, normal / mix(aInstanceScale, vec3(1.0), vec3(equal(aInstanceScale, vec3(0.0)))));
+		tangent = rotate(aInstanceRotation, tangent * aInstanceScale);
+		vColor = aInstanceColor;
+	}
+
 	// === Position ===
-	vec4 worldPosition = uTranslationMatrix * uRotationMatrix * uScaleMatrix * vec4(aVertexPosition, 1.0);
-	gl_Position = uPMatrix * uMVMatrix * worldPosition;
+	vec4 worldPosition = uTranslationMatrix * uRotationMatrix * uScaleMatrix * vec4(position, 1.0);
+	vec4 viewPosition = uMVMatrix * worldPosition;
+	gl_Position = uPMatrix * viewPosition;
+	vViewDepth = -viewPosition.z;
 
 	// === Lighting ===
-	// Put normals into world space. Don't translate as that would affect the direction of the normal.
-	vec4 worldNormal = normalize(uRotationMatrix * uScaleMatrix * vec4(aNormal, 1.0));
-	// TODO: If lighting starts looking odd with meshes that get scaled, look at: http://web.archive.org/web/20120228095346/http://www.arcsynthesis.org/gltut/Illumination/Tut09%20Normal%20Transformation.html
-
-	float intensity = max(dot(worldNormal.xyz, uDiffuseLightDirection.xyz), 0.0);
-	vLighting = uDiffuseLightColor * intensity;
+	// Lighting is done per fragment in world space, so pass along the world position and normal.
+	vWorldPosition = worldPosition.xyz;
+	vNormal = uNormalMatrix * normal;
+	// Tangents lie along the surface, so they're transformed like positions rather than like normals.
+	vTangent = vec4((uRotationMatrix * uScaleMatrix * vec4(tangent, 0.0)).xyz, aTangent.w);
 }
 `
+	// MAX_LIGHTS, MAX_SHADOW_CASCADES, and MAX_SHADOW_FILTER_RADIUS are defined in setupModelShader.
 	modelFragmentSource = `
-#ifdef GL_ES
-precision lowp float;
-#endif
+#include "gome/depth.glsl"
 
 uniform sampler2D uSampler;
+// uNormalMap holds tangent space normals, with X right along U, Y up along V, and Z out of the surface.
+uniform sampler2D uNormalMap;
+// uSpecularMap's rgb multiplies the specular color, and its alpha multiplies the shininess.
+uniform sampler2D uSpecularMap;
 uniform vec4 uColor;
 uniform vec3 uAmbientLight;
 
-varying vec3 vLighting;
+// Material properties. Diffuse color and texture are uColor and uSampler.
+uniform vec3 uSpecularColor;
+uniform float uShininess;
+uniform vec3 uEmissive;
+uniform float uOpacity;
+uniform bool uUnlit;
+
+uniform vec3 uCameraPosition;
+
+// Lights are packed into three vectors each:
+// uLightPosition: xyz is the position of the light. w is its range, or 0 for directional lights.
+// uLightDirection: xyz is the direction the light shines. w is the cosine of a spotlight's outer cone angle.
+// uLightColor: rgb is the color of the light, multiplied by its intensity. w is the cosine of a spotlight's inner cone angle.
+uniform int uLightCount;
+uniform vec4 uLightPosition[MAX_LIGHTS];
+uniform vec4 uLightDirection[MAX_LIGHTS];
+uniform vec4 uLightColor[MAX_LIGHTS];
+
+// uDepthOnly is set while drawing into a shadow map. Instead of a color, each fragment outputs its depth.
+uniform bool uDepthOnly;
+
+// Shadows are cast by a single light, uShadowLight, which is -1 if there are none. The camera's view is split into
+// uShadowCascades slices by distance, each ending at the matching component of uShadowSplits. Each slice has its own
+// tile in uShadowMap, side by side, and uShadowMatrix transforms world positions into that tile's texture coordinates.
+uniform sampler2D uShadowMap;
+uniform bool uShadowPacked; // Depth is packed into rgb by packDepth, rather than uShadowMap being a depth texture.
+uniform int uShadowLight;
+uniform int uShadowCascades;
+uniform mat4 uShadowMatrix[MAX_SHADOW_CASCADES];
+uniform vec4 uShadowSplits;
+uniform vec2 uShadowTexelSize;
+uniform float uShadowBias;
+uniform float uShadowSlopeBias;
+uniform int uShadowFilterRadius;
+
+varying vec3 vWorldPosition;
+varying vec3 vNormal;
+varying vec4 vTangent;
 varying vec2 vTextureCoord;
+varying float vViewDepth;
+varying vec4 vColor;
+
+float shadowDepth(vec2 uv) {
+	vec4 texel = texture2D(uShadowMap, uv);
+	if (uShadowPacked) {
+		return unpackDepth(texel.rgb);
+	}
+	return texel.r;
+}
+
+// shadow returns how much of the shadow casting light reaches this fragment, from 0 in full shadow to 1 fully lit.
+float shadow(float lambert) {
+	int cascade = -1;
+	vec4 coord = vec4(0.0);
+	for (int i = 0; i < MAX_SHADOW_CASCADES; i++) {
+		if (i >= uShadowCascades) {
+			break;
+		}
+		if (cascade < 0 && vViewDepth <= uShadowSplits[i]) {
+			cascade = i;
+			coord = uShadowMatrix[i] * vec4(vWorldPosition, 1.0);
+		}
+	}
+	// Past the last cascade, and past the far side of the shadow map, nothing is shadowed.
+	if (cascade < 0 || coord.z >= 1.0) {
+		return 1.0;
+	}
+	// Surfaces at a steep angle to the light need more bias to avoid shadowing themselves.
+	float depth = coord.z - uShadowBias - uShadowSlopeBias * (1.0 - lambert);
+
+	// Keep samples within this cascade's tile, so they don't read its neighbors.
+	float tileWidth = 1.0 / float(uShadowCascades);
+	float minX = float(cascade) * tileWidth + uShadowTexelSize.x * 0.5;
+	float maxX = float(cascade + 1) * tileWidth - uShadowTexelSize.x * 0.5;
+
+	// Percentage closer filtering: the fraction of nearby texels that are lit softens the edges of shadows.
+	float lit = 0.0;
+	float count = 0.0;
+	for (int x = -MAX_SHADOW_FILTER_RADIUS; x <= MAX_SHADOW_FILTER_RADIUS; x++) {
+		for (int y = -MAX_SHADOW_FILTER_RADIUS; y <= MAX_SHADOW_FILTER_RADIUS; y++) {
+			if (abs(float(x)) > float(uShadowFilterRadius) || abs(float(y)) > float(uShadowFilterRadius)) {
+				continue;
+			}
+			vec2 uv = coord.xy + vec2(float(x), float(y)) * uShadowTexelSize;
+			uv.x = clamp(uv.x, minX, maxX);
+			if (depth <= shadowDepth(uv)) {
+				lit += 1.0;
+			}
+			count += 1.0;
+		}
+	}
+	return lit / count;
+}
 
 void main(void) {
-	// gl_FragColor = vec4(uAmbientLight + vLighting, 1.0) * uColor;
-	gl_FragColor = texture2D(uSampler, vTextureCoord) * vec4(uAmbientLight + vLighting, 1.0) * uColor;
+	vec4 base = texture2D(uSampler, vTextureCoord) * uColor * vColor;
+	if (uDepthOnly) {
+		// Mostly transparent parts, like the gaps between leaves, don't cast shadows.
+		if (base.a * uOpacity < 0.5) {
+			discard;
+		}
+		gl_FragColor = vec4(packDepth(gl_FragCoord.z), 1.0);
+		return;
+	}
+	if (uUnlit) {
+		gl_FragColor = vec4(base.rgb + uEmissive, base.a * uOpacity);
+		return;
+	}
+
+	// Meshes without normals get zeros, which means they're only lit by ambient light.
+	vec3 normal = vec3(0.0);
+	if (length(vNormal) > 0.0) {
+		normal = normalize(vNormal);
+	}
+	// The back of a double sided surface faces the other way.
+	if (!gl_FrontFacing) {
+		normal = -normal;
+	}
+	// Bend the normal using the normal map. Meshes without tangents can't be normal mapped, so they're left alone.
+	vec3 tangent = vTangent.xyz - normal * dot(normal, vTangent.xyz);
+	if (length(tangent) > 0.0 && length(normal) > 0.0) {
+		tangent = normalize(tangent);
+		vec3 bitangent = vTangent.w * cross(normal, tangent);
+		vec3 mapped = texture2D(uNormalMap, vTextureCoord).xyz * 2.0 - 1.0;
+		normal = normalize(mapped.x * tangent + mapped.y * bitangent + mapped.z * normal);
+	}
+	vec4 specularSample = texture2D(uSpecularMap, vTextureCoord);
+	float shininess = max(uShininess * specularSample.a, 1.0);
+	vec3 toCamera = normalize(uCameraPosition - vWorldPosition);
+
+	// Blinn-Phong lighting: diffuse light depends on how directly the light hits the surface, and specular highlights
+	// depend on how close the surface is to reflecting the light toward the camera.
+	vec3 diffuse = uAmbientLight;
+	vec3 specular = vec3(0.0);
+	for (int i = 0; i < MAX_LIGHTS; i++) {
+		if (i >= uLightCount) {
+			break;
+		}
+		vec4 position = uLightPosition[i];
+		vec4 direction = uLightDirection[i];
+		vec3 toLight = -direction.xyz;
+		float attenuation = 1.0;
+		if (position.w > 0.0) {
+			vec3 offset = position.xyz - vWorldPosition;
+			float dist = length(offset);
+			toLight = offset / max(dist, 0.0001);
+			// Fade smoothly to nothing at the edge of the light's range.
+			float falloff = clamp(1.0 - dist / position.w, 0.0, 1.0);
+			attenuation = falloff * falloff;
+			// Point lights have cone angles that include everything, so this only affects spotlights.
+			attenuation *= smoothstep(direction.w, uLightColor[i].w, dot(-toLight, direction.xyz));
+		}
+		float lambert = max(dot(normal, toLight), 0.0);
+		if (i == uShadowLight && lambert > 0.0) {
+			attenuation *= shadow(lambert);
+		}
+		vec3 light = uLightColor[i].rgb * attenuation;
+		diffuse += light * lambert;
+		if (lambert > 0.0 && uShininess > 0.0) {
+			vec3 halfway = normalize(toLight + toCamera);
+			specular += light * pow(max(dot(normal, halfway), 0.0), shininess);
+		}
+	}
+	gl_FragColor = vec4(base.rgb * diffuse + specular * uSpecularColor * specularSample.rgb + uEmissive, base.a * uOpacity);
 }
 `
 )
 
+// Texture units used by the model shader. Each texture used in a draw call is bound to its own unit.
+const (
+	DiffuseTextureUnit = iota
+	NormalMapTextureUnit
+	SpecularMapTextureUnit
+	ShadowMapTextureUnit
+)
+
+// LightType is the kind of light in a Light.
+type LightType int
+
+const (
+	// DirectionalLight shines in the same direction everywhere, like the sun.
+	DirectionalLight LightType = iota
+	// PointLight shines in all directions from a position, like a light bulb.
+	PointLight
+	// SpotLight shines in a cone from a position, like a flashlight.
+	SpotLight
+)
+
+// Light is the data for a single light, as it's sent to the model shader. Most code should use the light package
+// rather than creating these directly.
+type Light struct {
+	Type LightType
+	// Position is where point lights and spotlights are. It's ignored for directional lights.
+	Position mgl32.Vec3
+	// Direction is the direction that directional lights and spotlights shine. It's ignored for point lights.
+	Direction mgl32.Vec3
+	// Color is the light's color, already multiplied by its intensity. Components can be over 1 for bright lights.
+	Color mgl32.Vec3
+	// Range is how far point lights and spotlights reach. They fade out smoothly until this distance.
+	Range float32
+	// InnerAngle and OuterAngle are the angles in radians from the center of a spotlight to the edges of its cone.
+	// It's fully lit within InnerAngle, and fades out until OuterAngle.
+	InnerAngle, OuterAngle float32
+	// Shadowed lights are blocked by the shadow map set by SetShadow. Only the first shadowed light is used.
+	Shadowed bool
+}
+
+// Shadow is a shadow map, as it's sent to the model shader. Most code should use the light package rather than
+// creating these directly.
+type Shadow struct {
+	// Texture holds the depth of the closest thing to the light, for each cascade side by side.
+	Texture gl.Texture
+	// Packed is true if depths are packed into the color of Texture, rather than it being a depth texture.
+	Packed bool
+	// Matrices transform world positions into texture coordinates in each cascade's part of Texture, with depth in Z.
+	// There can be up to MaxShadowCascades of them.
+	Matrices []mgl32.Mat4
+	// Splits are the distances in front of the camera where each cascade ends. There must be one for each matrix.
+	Splits []float32
+	// TexelSize is the size of one texel of Texture, in texture coordinates.
+	TexelSize mgl32.Vec2
+	// Bias is subtracted from depths before comparing them, so surfaces don't shadow themselves. SlopeBias is added
+	// to it for surfaces at an angle to the light.
+	Bias, SlopeBias float32
+	// FilterRadius softens the edges of shadows by this many texels, up to MaxShadowFilterRadius.
+	FilterRadius int
+}
+
 type model struct {
 	Program gl.Program
 
@@ -73,20 +338,70 @@ type model struct {
 	rotationMatrixUniform    gl.Uniform
 	scaleMatrixUniform       gl.Uniform
 
-	mvMatrixUniform gl.Uniform
-	pMatrixUniform  gl.Uniform
+	mvMatrixUniform       gl.Uniform
+	pMatrixUniform        gl.Uniform
+	cameraPositionUniform gl.Uniform
+	// pMatrix and mvMatrix are the most recent values passed to SetMVPMatrix. They're kept so things drawn by other
+	// programs, like skyboxes, can be drawn from the same point of view.
+	pMatrix, mvMatrix mgl32.Mat4
+
+	normalMatrixUniform gl.Uniform
+	// rotation and scale are the most recent rotation and scale matrices. The normal matrix is made from them.
+	rotation, scale mgl32.Mat3
 
 	colorUniform        gl.Uniform
 	ambientLightUniform gl.Uniform
-
-	diffuseLightDirectionUniform gl.Uniform
-	diffuseLightColorUniform     gl.Uniform
+	// ambientLight is the most recent value passed to SetAmbientLight. It's kept so temporary changes can be undone.
+	ambientLight *color.NRGBA
+
+	specularColorUniform gl.Uniform
+	shininessUniform     gl.Uniform
+	emissiveUniform      gl.Uniform
+	opacityUniform       gl.Uniform
+	unlitUniform         gl.Uniform
+
+	lightCountUniform     gl.Uniform
+	lightPositionUniform  gl.Uniform
+	lightDirectionUniform gl.Uniform
+	lightColorUniform     gl.Uniform
+	// lights are the lights most recently passed to SetLights. Most draw calls use the same lights as the one before,
+	// so keeping them avoids uploading them again.
+	lights []Light
+	// lightData holds the position, direction, and color uniform arrays, so they aren't allocated every time.
+	lightData [3][]float32
+
+	depthOnlyUniform gl.Uniform
+	depthOnly        bool
+
+	shadowMapUniform          gl.Uniform
+	shadowPackedUniform       gl.Uniform
+	shadowLightUniform        gl.Uniform
+	shadowCascadesUniform     gl.Uniform
+	shadowMatrixUniform       gl.Uniform
+	shadowSplitsUniform       gl.Uniform
+	shadowTexelSizeUniform    gl.Uniform
+	shadowBiasUniform         gl.Uniform
+	shadowSlopeBiasUniform    gl.Uniform
+	shadowFilterRadiusUniform gl.Uniform
 
 	VertexPositionAttrib gl.Attrib
 	NormalAttrib         gl.Attrib
+	TangentAttrib        gl.Attrib
+
+	// Instance attributes are only used while drawing instances. See SetInstanced.
+	InstancePositionAttrib gl.Attrib
+	InstanceRotationAttrib gl.Attrib
+	InstanceScaleAttrib    gl.Attrib
+	InstanceColorAttrib    gl.Attrib
+	instancedUniform       gl.Uniform
 
 	samplerUniform     gl.Uniform
 	TextureCoordAttrib gl.Attrib
+
+	normalMapUniform   gl.Uniform
+	specularMapUniform gl.Uniform
+	// white and flatNormal are used when no texture is provided. They don't change the color or normal of a surface.
+	white, flatNormal gl.Texture
 }
 
 func setupModelShader() error {
@@ -94,7 +409,13 @@ func setupModelShader() error {
 		return errors.New("Model Shader already initialized")
 	}
 
-	program, err := glutil.CreateProgram(modelVertexSource, modelFragmentSource)
+	// Instance attributes are disabled when they aren't used, so it matters that CompileProgram makes the vertex
+	// position, which is always used, attribute 0.
+	program, err := CompileProgram("model", modelVertexSource, modelFragmentSource, map[string]string{
+		"MAX_LIGHTS":               strconv.Itoa(MaxLights),
+		"MAX_SHADOW_CASCADES":      strconv.Itoa(MaxShadowCascades),
+		"MAX_SHADOW_FILTER_RADIUS": strconv.Itoa(MaxShadowFilterRadius),
+	})
 	if err != nil {
 		return err
 	}
@@ -112,21 +433,69 @@ func setupModelShader() error {
 		pMatrixUniform:  gl.GetUniformLocation(program, "uPMatrix"),
 		mvMatrixUniform: gl.GetUniformLocation(program, "uMVMatrix"),
 
+		cameraPositionUniform: gl.GetUniformLocation(program, "uCameraPosition"),
+
 		translationMatrixUniform: gl.GetUniformLocation(program, "uTranslationMatrix"),
 		rotationMatrixUniform:    gl.GetUniformLocation(program, "uRotationMatrix"),
 		scaleMatrixUniform:       gl.GetUniformLocation(program, "uScaleMatrix"),
+		normalMatrixUniform:      gl.GetUniformLocation(program, "uNormalMatrix"),
 
 		colorUniform:        gl.GetUniformLocation(program, "uColor"),
 		ambientLightUniform: gl.GetUniformLocation(program, "uAmbientLight"),
 
-		diffuseLightDirectionUniform: gl.GetUniformLocation(program, "uDiffuseLightDirection"),
-		diffuseLightColorUniform:     gl.GetUniformLocation(program, "uDiffuseLightColor"),
+		specularColorUniform: gl.GetUniformLocation(program, "uSpecularColor"),
+		shininessUniform:     gl.GetUniformLocation(program, "uShininess"),
+		emissiveUniform:      gl.GetUniformLocation(program, "uEmissive"),
+		opacityUniform:       gl.GetUniformLocation(program, "uOpacity"),
+		unlitUniform:         gl.GetUniformLocation(program, "uUnlit"),
+
+		lightCountUniform:     gl.GetUniformLocation(program, "uLightCount"),
+		lightPositionUniform:  gl.GetUniformLocation(program, "uLightPosition"),
+		lightDirectionUniform: gl.GetUniformLocation(program, "uLightDirection"),
+		lightColorUniform:     gl.GetUniformLocation(program, "uLightColor"),
+
+		depthOnlyUniform: gl.GetUniformLocation(program, "uDepthOnly"),
+
+		shadowMapUniform:          gl.GetUniformLocation(program, "uShadowMap"),
+		shadowPackedUniform:       gl.GetUniformLocation(program, "uShadowPacked"),
+		shadowLightUniform:        gl.GetUniformLocation(program, "uShadowLight"),
+		shadowCascadesUniform:     gl.GetUniformLocation(program, "uShadowCascades"),
+		shadowMatrixUniform:       gl.GetUniformLocation(program, "uShadowMatrix"),
+		shadowSplitsUniform:       gl.GetUniformLocation(program, "uShadowSplits"),
+		shadowTexelSizeUniform:    gl.GetUniformLocation(program, "uShadowTexelSize"),
+		shadowBiasUniform:         gl.GetUniformLocation(program, "uShadowBias"),
+		shadowSlopeBiasUniform:    gl.GetUniformLocation(program, "uShadowSlopeBias"),
+		shadowFilterRadiusUniform: gl.GetUniformLocation(program, "uShadowFilterRadius"),
 
 		VertexPositionAttrib: gl.GetAttribLocation(program, "aVertexPosition"),
 		NormalAttrib:         gl.GetAttribLocation(program, "aNormal"),
+		TangentAttrib:        gl.GetAttribLocation(program, "aTangent"),
+
+		InstancePositionAttrib: gl.GetAttribLocation(program, "aInstancePosition"),
+		InstanceRotationAttrib: gl.GetAttribLocation(program, "aInstanceRotation"),
+		InstanceScaleAttrib:    gl.GetAttribLocation(program, "aInstanceScale"),
+		InstanceColorAttrib:    gl.GetAttribLocation(program, "aInstanceColor"),
+		instancedUniform:       gl.GetUniformLocation(program, "uInstanced"),
 
 		samplerUniform:     gl.GetUniformLocation(program, "uSampler"),
 		TextureCoordAttrib: gl.GetAttribLocation(program, "aTextureCoord"),
+
+		normalMapUniform:   gl.GetUniformLocation(program, "uNormalMap"),
+		specularMapUniform: gl.GetUniformLocation(program, "uSpecularMap"),
+	}
+	// Each sampler reads from its own texture unit. This never changes, so it only needs to be set once.
+	gl.Uniform1i(Model.samplerUniform, DiffuseTextureUnit)
+	gl.Uniform1i(Model.normalMapUniform, NormalMapTextureUnit)
+	gl.Uniform1i(Model.specularMapUniform, SpecularMapTextureUnit)
+	gl.Uniform1i(Model.shadowMapUniform, ShadowMapTextureUnit)
+	gl.Uniform1i(Model.shadowLightUniform, -1)
+
+	if Model.white, err = glutil.LoadTextureData(1, 1, []uint8{255, 255, 255, 255}); err != nil {
+		return err
+	}
+	// A normal pointing straight out of the surface, scaled from [-1,1] to [0,255].
+	if Model.flatNormal, err = glutil.LoadTextureData(1, 1, []uint8{128, 128, 255, 255}); err != nil {
+		return err
 	}
 	return nil
 }
@@ -134,7 +503,14 @@ func setupModelShader() error {
 func (s *model) SetDefaults() {
 	UseProgram(s.Program)
 	s.SetColor(nil)
-	s.SetDiffuse(mgl32.Vec3{0, 0, 0}, &color.NRGBA{})
+	s.SetSpecular(nil, 0)
+	s.SetEmissive(nil)
+	s.SetOpacity(1)
+	s.SetUnlit(false)
+	s.SetLights(nil)
+	s.SetShadow(nil)
+	s.SetDepthOnly(false)
+	s.SetInstanced(false)
 	s.SetTranslationMatrix(0, 0, 0)
 	s.SetRotationMatrix(0, 0, 0)
 	s.SetScaleMatrix(1, 1, 1)
@@ -149,21 +525,131 @@ func (s *model) SetColor(color *color.NRGBA) {
 	gl.Uniform4f(s.colorUniform, float32(color.R)/255.0, float32(color.G)/255.0, float32(color.B)/255.0, float32(color.A)/255.0)
 }
 
-// SetDiffuse sets directional lighting. If color is nil, it's set to white. Alpha value is ignored.
-func (s *model) SetDiffuse(dir mgl32.Vec3, color *color.NRGBA) {
+// SetSpecular sets the color and shininess of highlights. If color is nil, there are no highlights.
+// Alpha value is ignored.
+func (s *model) SetSpecular(color *color.NRGBA, shininess float32) {
+	UseProgram(s.Program)
+	if color == nil {
+		gl.Uniform3f(s.specularColorUniform, 0, 0, 0)
+	} else {
+		gl.Uniform3f(s.specularColorUniform, float32(color.R)/255.0, float32(color.G)/255.0, float32(color.B)/255.0)
+	}
+	gl.Uniform1f(s.shininessUniform, shininess)
+}
+
+// SetEmissive sets light given off by the surface itself. If color is nil, there is none. Alpha value is ignored.
+func (s *model) SetEmissive(color *color.NRGBA) {
 	UseProgram(s.Program)
 	if color == nil {
-		gl.Uniform3f(s.diffuseLightColorUniform, 1, 1, 1)
+		gl.Uniform3f(s.emissiveUniform, 0, 0, 0)
+		return
+	}
+	gl.Uniform3f(s.emissiveUniform, float32(color.R)/255.0, float32(color.G)/255.0, float32(color.B)/255.0)
+}
+
+// SetOpacity multiplies the alpha of everything drawn. 0 is invisible and 1 is fully opaque.
+func (s *model) SetOpacity(opacity float32) {
+	UseProgram(s.Program)
+	gl.Uniform1f(s.opacityUniform, opacity)
+}
+
+// SetUnlit sets whether lighting is ignored, so colors and textures are drawn as they are.
+func (s *model) SetUnlit(unlit bool) {
+	UseProgram(s.Program)
+	if unlit {
+		gl.Uniform1i(s.unlitUniform, 1)
 	} else {
-		gl.Uniform3f(s.diffuseLightColorUniform, float32(color.R)/255.0, float32(color.G)/255.0, float32(color.B)/255.0)
+		gl.Uniform1i(s.unlitUniform, 0)
 	}
-	dir = dir.Normalize()
-	gl.Uniform3f(s.diffuseLightDirectionUniform, dir[0], dir[1], dir[2])
+}
+
+// SetLights sets the lights that affect the following draw calls. Only the first MaxLights are used.
+// Generally this is called by the light package, which picks the most relevant lights for each model.
+func (s *model) SetLights(lights []Light) {
+	if len(lights) > MaxLights {
+		lights = lights[:MaxLights]
+	}
+	// Uniforms start at zero, so there's nothing to do for no lights until some have been set.
+	if lightsEqual(s.lights, lights) {
+		return
+	}
+	s.lights = append(s.lights[:0], lights...)
+
+	UseProgram(s.Program)
+	gl.Uniform1i(s.lightCountUniform, len(lights))
+	if len(lights) == 0 {
+		gl.Uniform1i(s.shadowLightUniform, -1)
+		return
+	}
+	position, direction, color := packLights(lights, s.lightData[0][:0], s.lightData[1][:0], s.lightData[2][:0])
+	gl.Uniform4fv(s.lightPositionUniform, position)
+	gl.Uniform4fv(s.lightDirectionUniform, direction)
+	gl.Uniform4fv(s.lightColorUniform, color)
+	s.lightData = [3][]float32{position, direction, color}
+
+	shadowLight := -1
+	for i, l := range lights {
+		if l.Shadowed {
+			shadowLight = i
+			break
+		}
+	}
+	gl.Uniform1i(s.shadowLightUniform, shadowLight)
+}
+
+// packLights appends the uLightPosition, uLightDirection, and uLightColor uniform values of each light, which have four
+// floats each. See the model shader's fragment source for what they mean.
+func packLights(lights []Light, position, direction, color []float32) ([]float32, []float32, []float32) {
+	for _, l := range lights {
+		// Point lights are spotlights that shine everywhere. Their cone never cuts anything off.
+		cosInner, cosOuter := float32(-1), float32(-2)
+		rangeOrZero := l.Range
+		switch l.Type {
+		case DirectionalLight:
+			rangeOrZero = 0
+		case SpotLight:
+			cosInner, cosOuter = float32(math.Cos(float64(l.InnerAngle))), float32(math.Cos(float64(l.OuterAngle)))
+			// The shader fades between the two, which is undefined if they're the same.
+			if cosInner <= cosOuter {
+				cosInner = cosOuter + 1e-4
+			}
+		}
+		if l.Type != DirectionalLight && rangeOrZero <= 0 {
+			// A range of zero means the light doesn't reach anything.
+			rangeOrZero, l.Color = 1, mgl32.Vec3{}
+		}
+		dir := l.Direction
+		if dir.Len() > 0 {
+			dir = dir.Normalize()
+		}
+		position = append(position, l.Position[0], l.Position[1], l.Position[2], rangeOrZero)
+		direction = append(direction, dir[0], dir[1], dir[2], cosOuter)
+		color = append(color, l.Color[0], l.Color[1], l.Color[2], cosInner)
+	}
+	return position, direction, color
+}
+
+// Lights returns the lights most recently passed to SetLights.
+func (s *model) Lights() []Light {
+	return s.lights
+}
+
+func lightsEqual(a, b []Light) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // Alpha value is ignored since it doesn't make sense. Also why is there no color.RGB?
 func (s *model) SetAmbientLight(color *color.NRGBA) {
 	UseProgram(s.Program)
+	s.ambientLight = color
 	if color == nil {
 		gl.Uniform3f(s.ambientLightUniform, 0, 0, 0)
 		return
@@ -171,27 +657,124 @@ func (s *model) SetAmbientLight(color *color.NRGBA) {
 	gl.Uniform3f(s.ambientLightUniform, float32(color.R)/255.0, float32(color.G)/255.0, float32(color.B)/255.0)
 }
 
+// AmbientLight returns the value most recently passed to SetAmbientLight.
+func (s *model) AmbientLight() *color.NRGBA {
+	return s.ambientLight
+}
+
+// SetTexture sets the diffuse texture, which is the main color of a surface. If the texture isn't valid, plain white
+// is used.
 func (s *model) SetTexture(texture gl.Texture) {
-	gl.ActiveTexture(gl.TEXTURE0) // Determines where the BindTexture calls get bound. Necessary if using multiple textures at once. Good habit to get into using regardless.
-	gl.BindTexture(gl.TEXTURE_2D, texture)
-	gl.Uniform1i(s.samplerUniform, 0) // Unless you are using multiple textures, the second parameter to glUniform1i should always be 0. http://stackoverflow.com/questions/14022274/hardcoding-glsl-texture-sampler2d
+	bindTexture(DiffuseTextureUnit, texture, s.white)
+}
 
-	// If using multiple textures:
-	//// Attach Texture 0
-	//glActiveTexture(GL_TEXTURE0);
-	//glBindTexture(GL_TEXTURE_2D, _texture0);
-	//glUniform1i(_uSampler0, 0);
-	//
-	//// Attach Texture 1
-	//glActiveTexture(GL_TEXTURE1);
-	//glBindTexture(GL_TEXTURE_2D, _texture1);
-	//glUniform1i(_uSampler1, 1);
+// SetNormalMap sets the texture that adds detail to the lighting of a surface by bending its normals. It only has an
+// effect on meshes with tangents. If the texture isn't valid, normals are left alone.
+func (s *model) SetNormalMap(texture gl.Texture) {
+	bindTexture(NormalMapTextureUnit, texture, s.flatNormal)
+}
+
+// SetSpecularMap sets the texture that controls highlights across a surface. Its color multiplies the specular color,
+// and its alpha multiplies the shininess. If the texture isn't valid, highlights are the same everywhere.
+func (s *model) SetSpecularMap(texture gl.Texture) {
+	bindTexture(SpecularMapTextureUnit, texture, s.white)
+}
+
+// SetShadow sets the shadow map used by the shadowed light in SetLights. If shadow is nil, nothing is shadowed.
+// Generally this is called by the light package after drawing a shadow map.
+func (s *model) SetShadow(shadow *Shadow) {
+	UseProgram(s.Program)
+	if shadow == nil || len(shadow.Matrices) == 0 {
+		gl.Uniform1i(s.shadowCascadesUniform, 0)
+		bindTexture(ShadowMapTextureUnit, gl.Texture{}, s.white)
+		return
+	}
+	matrices, splits := shadow.Matrices, shadow.Splits
+	if len(matrices) > MaxShadowCascades {
+		matrices = matrices[:MaxShadowCascades]
+	}
+	if len(splits) < len(matrices) {
+		matrices = matrices[:len(splits)]
+	}
+	var packedMatrices []float32
+	var packedSplits [MaxShadowCascades]float32
+	for i, m := range matrices {
+		packedMatrices = append(packedMatrices, m[:]...)
+		packedSplits[i] = splits[i]
+	}
+	gl.Uniform1i(s.shadowCascadesUniform, len(matrices))
+	gl.UniformMatrix4fv(s.shadowMatrixUniform, packedMatrices)
+	gl.Uniform4f(s.shadowSplitsUniform, packedSplits[0], packedSplits[1], packedSplits[2], packedSplits[3])
+	gl.Uniform2f(s.shadowTexelSizeUniform, shadow.TexelSize[0], shadow.TexelSize[1])
+	gl.Uniform1f(s.shadowBiasUniform, shadow.Bias)
+	gl.Uniform1f(s.shadowSlopeBiasUniform, shadow.SlopeBias)
+	filterRadius := shadow.FilterRadius
+	if filterRadius < 0 {
+		filterRadius = 0
+	} else if filterRadius > MaxShadowFilterRadius {
+		filterRadius = MaxShadowFilterRadius
+	}
+	gl.Uniform1i(s.shadowFilterRadiusUniform, filterRadius)
+	if shadow.Packed {
+		gl.Uniform1i(s.shadowPackedUniform, 1)
+	} else {
+		gl.Uniform1i(s.shadowPackedUniform, 0)
+	}
+	bindTexture(ShadowMapTextureUnit, shadow.Texture, s.white)
+}
+
+// SetInstanced sets whether the following draw calls use the instance attributes, to draw many copies of a mesh that
+// each have their own transform and color. Instances are transformed before the translation, rotation, and scale
+// matrices, so those move the whole group.
+func (s *model) SetInstanced(instanced bool) {
+	UseProgram(s.Program)
+	if instanced {
+		gl.Uniform1i(s.instancedUniform, 1)
+	} else {
+		gl.Uniform1i(s.instancedUniform, 0)
+	}
+}
+
+// SetDepthOnly sets whether the following draw calls output their depth rather than their color, which is used to
+// draw shadow maps. Shadows aren't used while it's set, so a shadow map can't be read while it's being drawn.
+func (s *model) SetDepthOnly(depthOnly bool) {
+	UseProgram(s.Program)
+	s.depthOnly = depthOnly
+	if depthOnly {
+		gl.Uniform1i(s.depthOnlyUniform, 1)
+	} else {
+		gl.Uniform1i(s.depthOnlyUniform, 0)
+	}
+}
+
+// bindTexture binds a texture to a texture unit, or the fallback if the texture isn't valid.
+func bindTexture(unit int, texture, fallback gl.Texture) {
+	if !texture.Valid() {
+		texture = fallback
+	}
+	gl.ActiveTexture(gl.Enum(gl.TEXTURE0 + unit)) // Determines where the BindTexture call gets bound.
+	gl.BindTexture(gl.TEXTURE_2D, texture)
 }
 
 func (s *model) SetMVPMatrix(pMatrix, mvMatrix mgl32.Mat4) {
 	UseProgram(s.Program)
+	s.pMatrix, s.mvMatrix = pMatrix, mvMatrix
 	gl.UniformMatrix4fv(s.pMatrixUniform, pMatrix[:])
 	gl.UniformMatrix4fv(s.mvMatrixUniform, mvMatrix[:])
+	// Specular highlights depend on where the camera is, which is where the ModelView matrix moves the origin from.
+	cameraPosition := mvMatrix.Inv().Col(3)
+	gl.Uniform3f(s.cameraPositionUniform, cameraPosition[0], cameraPosition[1], cameraPosition[2])
+}
+
+// DepthOnly returns the value most recently passed to SetDepthOnly. Things drawn by other programs that shouldn't cast
+// shadows, like skyboxes, check it to skip drawing into shadow maps.
+func (s *model) DepthOnly() bool {
+	return s.depthOnly
+}
+
+// MVPMatrix returns the matrices most recently passed to SetMVPMatrix.
+func (s *model) MVPMatrix() (pMatrix, mvMatrix mgl32.Mat4) {
+	return s.pMatrix, s.mvMatrix
 }
 
 func (s *model) SetTranslationMatrix(x, y, z float32) {
@@ -205,16 +788,43 @@ func (s *model) SetRotationMatrix(x, y, z float32) {
 	UseProgram(s.Program)
 	rotationMatrix := mgl32.Rotate3DX(x).Mul3(mgl32.Rotate3DY(y)).Mul3(mgl32.Rotate3DZ(z)).Mat4()
 	gl.UniformMatrix4fv(s.rotationMatrixUniform, rotationMatrix[:])
+	s.rotation = rotationMatrix.Mat3()
+	s.setNormalMatrix()
 }
 
 func (s *model) SetRotationMatrixQ(q mgl32.Quat) {
 	UseProgram(s.Program)
 	rotationMatrix := q.Mat4()
 	gl.UniformMatrix4fv(s.rotationMatrixUniform, rotationMatrix[:])
+	s.rotation = rotationMatrix.Mat3()
+	s.setNormalMatrix()
 }
 
 func (s *model) SetScaleMatrix(x, y, z float32) {
 	UseProgram(s.Program)
 	scaleMatrix := mgl32.Scale3D(x, y, z)
 	gl.UniformMatrix4fv(s.scaleMatrixUniform, scaleMatrix[:])
+	s.scale = scaleMatrix.Mat3()
+	s.setNormalMatrix()
+}
+
+// setNormalMatrix uploads the matrix used to transform normals, based on the current rotation and scale.
+func (s *model) setNormalMatrix() {
+	normalMatrix := NormalMatrix(s.rotation, s.scale)
+	gl.UniformMatrix3fv(s.normalMatrixUniform, normalMatrix[:])
+}
+
+// NormalMatrix returns the inverse transpose of rotation * scale, which transforms normals so they stay perpendicular
+// to the surface when it's scaled unevenly. Directions with zero scale are treated as unscaled, since flattened
+// models don't have an inverse.
+// See http://web.archive.org/web/20120228095346/http://www.arcsynthesis.org/gltut/Illumination/Tut09%20Normal%20Transformation.html
+func NormalMatrix(rotation, scale mgl32.Mat3) mgl32.Mat3 {
+	// Rotation matrices are their own inverse transpose, so only the scale needs to be inverted.
+	inverseScale := mgl32.Ident3()
+	for i := 0; i < 3; i++ {
+		if s := scale.At(i, i); s != 0 {
+			inverseScale.Set(i, i, 1/s)
+		}
+	}
+	return rotation.Mul3(inverseScale)
 }
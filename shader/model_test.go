@@ -0,0 +1,64 @@
+package shader
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+func TestNormalMatrix(t *testing.T) {
+	// A slope on a model that's stretched along X. Stretching makes the slope shallower, so its normal tips toward Y.
+	rotation := mgl32.Rotate3DZ(mgl32.DegToRad(90))
+	scale := mgl32.Scale3D(4, 1, 1).Mat3()
+	edge := mgl32.Vec3{1, -1, 0}
+	normal := mgl32.Vec3{1, 1, 0}
+
+	transform := rotation.Mul3(scale)
+	normalMatrix := NormalMatrix(rotation, scale)
+	if got := transform.Mul3x1(edge).Dot(normalMatrix.Mul3x1(normal)); got > 1e-5 || got < -1e-5 {
+		t.Errorf("transformed normal isn't perpendicular to the transformed surface: dot product %v", got)
+	}
+
+	// Flattened models keep their normals.
+	flat := NormalMatrix(mgl32.Ident3(), mgl32.Scale3D(2, 2, 0).Mat3())
+	if got := flat.Mul3x1(mgl32.Vec3{0, 0, 1}); got != (mgl32.Vec3{0, 0, 1}) {
+		t.Errorf("got normal %v for a flattened model, want it unchanged", got)
+	}
+}
+
+func TestPackLights(t *testing.T) {
+	lights := []Light{
+		{Type: DirectionalLight, Direction: mgl32.Vec3{0, 0, -2}, Color: mgl32.Vec3{1, 1, 1}, Range: 5},
+		{Type: PointLight, Position: mgl32.Vec3{1, 2, 3}, Color: mgl32.Vec3{1, 0, 0}, Range: 10},
+		{Type: PointLight, Color: mgl32.Vec3{1, 1, 1}}, // No range, so it doesn't light anything.
+	}
+	position, direction, color := packLights(lights, nil, nil, nil)
+	wantPosition := []float32{0, 0, 0, 0, 1, 2, 3, 10, 0, 0, 0, 1}
+	wantDirection := []float32{0, 0, -1, -2, 0, 0, 0, -2, 0, 0, 0, -2}
+	wantColor := []float32{1, 1, 1, -1, 1, 0, 0, -1, 0, 0, 0, -1}
+	for _, test := range []struct {
+		name      string
+		got, want []float32
+	}{
+		{"position", position, wantPosition},
+		{"direction", direction, wantDirection},
+		{"color", color, wantColor},
+	} {
+		if len(test.got) != len(test.want) {
+			t.Errorf("got %d %s values, want %d", len(test.got), test.name, len(test.want))
+			continue
+		}
+		for i := range test.want {
+			if test.got[i] != test.want[i] {
+				t.Errorf("got %s %v, want %v", test.name, test.got, test.want)
+				break
+			}
+		}
+	}
+
+	// Existing buffers are reused.
+	buffer := make([]float32, 0, 12)
+	if position, _, _ := packLights(lights, buffer, nil, nil); &position[0] != &buffer[:1][0] {
+		t.Error("got a new position buffer, want the provided one reused")
+	}
+}
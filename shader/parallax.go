@@ -6,7 +6,6 @@ import (
 
 	"github.com/go-gl/mathgl/mgl32"
 	"github.com/goxjs/gl"
-	"github.com/goxjs/gl/glutil"
 )
 
 const (
@@ -50,9 +49,6 @@ void main() {
 }
 `
 	parallaxFragmentSource = `
-#ifdef GL_ES
-precision highp float; // set floating point precision. Required for WebGL.
-#endif
 varying vec3 vColor;
 void main() {
 	gl_FragColor = vec4(vColor, 1.0);
@@ -80,7 +76,7 @@ func setupParallaxShader() error {
 		return errors.New("Parallax Shader already initialized")
 	}
 
-	program, err := glutil.CreateProgram(parallaxVertexSource, parallaxFragmentSource)
+	program, err := CompileProgram("parallax", parallaxVertexSource, parallaxFragmentSource, nil)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,374 @@
+package shader
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goxjs/gl"
+)
+
+// Shader source is run through a small preprocessor before it's compiled. It:
+//   * Replaces #include "name" with a chunk registered with RegisterChunk, or with a file loaded by IncludeLoader.
+//     Each chunk is only included once per shader, so chunks can include what they need without worrying about
+//     being included twice. Includes are handled before anything else, so they aren't affected by #ifdef.
+//   * Starts the source with the #version for the platform: GLSL ES 1.00 for WebGL, and GLSL 1.20 for desktops.
+//     Sources with their own #version keep it.
+//   * Adds #define lines for the defines passed to CompileProgram or Source.Variant.
+//   * Sets the precision of floats in fragment shaders on WebGL, so they don't need the "#ifdef GL_ES" block that
+//     they otherwise do. It's highp where that's supported, and mediump otherwise.
+// Compile errors name the file and line that the error is on before preprocessing, like "model.frag:12".
+
+// IncludeLoader loads files for #include directives that don't name a registered chunk. gome.Initialize sets it to
+// asset.LoadFile, so shaders can include asset files by their path.
+var IncludeLoader func(path string) ([]byte, error)
+
+// chunks holds shader source that can be included by name. The built in chunks are named "gome/...".
+var chunks = map[string]string{
+	"gome/quaternion.glsl": quaternionChunk,
+	"gome/depth.glsl":      depthChunk,
+	"gome/color.glsl":      colorChunk,
+}
+
+// RegisterChunk makes source available to shaders with #include "name". Registering a name again replaces it,
+// though programs that were already compiled aren't changed.
+func RegisterChunk(name, source string) {
+	chunks[name] = source
+}
+
+// CompileProgram preprocesses, compiles and links a program. Compile errors name the vertex shader name+".vert" and
+// the fragment shader name+".frag". Defines are added to both shaders, with empty values defining a name without one.
+// It's up to the caller to delete the program using gl.DeleteProgram when it's no longer needed.
+//
+// Attribute 0 is always aVertexPosition if the vertex shader has it, since some desktop drivers don't draw anything
+// unless attribute 0 is enabled.
+func CompileProgram(name, vertexSource, fragmentSource string, defines map[string]string) (gl.Program, error) {
+	return compileProgram(name, name+".vert", vertexSource, name+".frag", fragmentSource, defines)
+}
+
+func compileProgram(name, vertexFile, vertexSource, fragmentFile, fragmentSource string, defines map[string]string) (gl.Program, error) {
+	vertex, err := preprocess(vertexFile, vertexSource, "", defines)
+	if err != nil {
+		return gl.Program{}, fmt.Errorf("%s shader: %v", name, err)
+	}
+	fragment, err := preprocess(fragmentFile, fragmentSource, fragmentPrecision, defines)
+	if err != nil {
+		return gl.Program{}, fmt.Errorf("%s shader: %v", name, err)
+	}
+
+	vs, err := compileShader(gl.VERTEX_SHADER, vertex)
+	if err != nil {
+		return gl.Program{}, fmt.Errorf("%s shader: %v", name, err)
+	}
+	defer gl.DeleteShader(vs)
+	fs, err := compileShader(gl.FRAGMENT_SHADER, fragment)
+	if err != nil {
+		return gl.Program{}, fmt.Errorf("%s shader: %v", name, err)
+	}
+	defer gl.DeleteShader(fs)
+
+	program := gl.CreateProgram()
+	gl.AttachShader(program, vs)
+	gl.AttachShader(program, fs)
+	gl.BindAttribLocation(program, gl.Attrib{}, "aVertexPosition")
+	gl.LinkProgram(program)
+	if gl.GetProgrami(program, gl.LINK_STATUS) != gl.TRUE {
+		defer gl.DeleteProgram(program)
+		return gl.Program{}, fmt.Errorf("%s shader: gl link status: %s", name, gl.GetProgramInfoLog(program))
+	}
+	return program, nil
+}
+
+func compileShader(shaderType gl.Enum, src *processed) (gl.Shader, error) {
+	s := gl.CreateShader(shaderType)
+	gl.ShaderSource(s, src.source)
+	gl.CompileShader(s)
+	if gl.GetShaderi(s, gl.COMPILE_STATUS) != gl.TRUE {
+		defer gl.DeleteShader(s)
+		return gl.Shader{}, fmt.Errorf("compile error in %s:\n%s", src.file, src.mapErrors(gl.GetShaderInfoLog(s)))
+	}
+	return s, nil
+}
+
+// location is a line of a file, counting from 1.
+type location struct {
+	file string
+	line int
+}
+
+func (l location) String() string {
+	return l.file + ":" + strconv.Itoa(l.line)
+}
+
+// processed is preprocessed shader source.
+type processed struct {
+	// file is the main file, which the source started as.
+	file   string
+	source string
+	// lines holds where each line of source came from.
+	lines []location
+}
+
+// headerFile is the file name of lines added by the preprocessor.
+const headerFile = "header"
+
+// preprocess handles includes, adds headers, defines and precision, and keeps track of where each line came from. See
+// the top of this file.
+func preprocess(file, source, precision string, defines map[string]string) (*processed, error) {
+	p := &preprocessor{
+		included: make(map[string]bool),
+		codeAt:   -1,
+	}
+	// The version has to be the first line, so take it out of the source if it's there.
+	version, versionLocation := versionDirective, location{headerFile, 1}
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "#version") {
+			version, versionLocation = strings.TrimSpace(line), location{file, i + 1}
+			lines[i] = ""
+			break
+		}
+	}
+	p.write(version, versionLocation)
+	for _, d := range sortedDefines(defines) {
+		p.write("#define "+d, location{headerFile, len(p.lines) + 1})
+	}
+
+	p.included[file] = true
+	if err := p.process(file, lines, nil); err != nil {
+		return nil, err
+	}
+	p.insertPrecision(precision)
+	return &processed{file: file, source: strings.Join(p.text, "\n") + "\n", lines: p.lines}, nil
+}
+
+// sortedDefines returns "NAME VALUE" for each define, sorted by name so the same defines always make the same source.
+func sortedDefines(defines map[string]string) []string {
+	var lines []string
+	for name, value := range defines {
+		lines = append(lines, strings.TrimSpace(name+" "+value))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+type preprocessor struct {
+	text  []string
+	lines []location
+	// included holds the files that have already been included, so they aren't included twice.
+	included map[string]bool
+
+	// depth is how many #if, #ifdef, and #ifndef blocks the current line is in, and outerIf is the index of the line
+	// that started the outermost one.
+	depth, outerIf int
+	// codeAt is the index of the line that the precision goes before, or -1 if there's no code yet. That's the first
+	// line that isn't blank, a comment, or a directive, so #extension directives can come first. If that line is
+	// inside an #if block, it's the start of the block instead, since the precision is needed whether or not the
+	// block is used.
+	codeAt int
+}
+
+func (p *preprocessor) write(line string, loc location) {
+	p.text = append(p.text, line)
+	p.lines = append(p.lines, loc)
+}
+
+// insertPrecision adds the lines of precision before codeAt, or at the end if there's no code.
+func (p *preprocessor) insertPrecision(precision string) {
+	if precision == "" {
+		return
+	}
+	at := p.codeAt
+	if at < 0 {
+		at = len(p.text)
+	}
+	var text []string
+	for _, line := range strings.Split(precision, "\n") {
+		if line != "" {
+			text = append(text, line)
+		}
+	}
+	lines := make([]location, len(text))
+	for i := range lines {
+		lines[i] = location{headerFile, at + i + 1}
+	}
+	p.text = append(p.text[:at], append(text, p.text[at:]...)...)
+	p.lines = append(p.lines[:at], append(lines, p.lines[at:]...)...)
+}
+
+// track keeps count of the #if blocks that a line is in, and records where the code starts.
+func (p *preprocessor) track(trimmed string) {
+	switch {
+	case strings.HasPrefix(trimmed, "#if"):
+		if p.depth == 0 {
+			p.outerIf = len(p.text)
+		}
+		p.depth++
+	case strings.HasPrefix(trimmed, "#endif"):
+		if p.depth > 0 {
+			p.depth--
+		}
+	case p.codeAt < 0 && trimmed != "" && !strings.HasPrefix(trimmed, "//") && !strings.HasPrefix(trimmed, "#"):
+		p.codeAt = len(p.text)
+		if p.depth > 0 {
+			p.codeAt = p.outerIf
+		}
+	}
+}
+
+// process writes the lines of a file, replacing includes with the files they include. stack holds the files that are
+// being included, to catch files that include themselves.
+func (p *preprocessor) process(file string, lines []string, stack []string) error {
+	stack = append(stack, file)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#include") {
+			p.track(trimmed)
+			p.write(line, location{file, i + 1})
+			continue
+		}
+
+		name, err := includeName(trimmed)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", file, i+1, err)
+		}
+		for _, f := range stack {
+			if f == name {
+				return fmt.Errorf("%s:%d: %s includes itself: %s", file, i+1, name, strings.Join(append(stack, name), " -> "))
+			}
+		}
+		// Keep the line count the same, so the lines after this one still match up.
+		p.write("", location{file, i + 1})
+		if p.included[name] {
+			continue
+		}
+		p.included[name] = true
+		source, err := loadInclude(name)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %v", file, i+1, err)
+		}
+		if err := p.process(name, strings.Split(source, "\n"), stack); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includeName returns the name in an #include "name" or #include <name> directive.
+func includeName(directive string) (string, error) {
+	arg := strings.TrimSpace(strings.TrimPrefix(directive, "#include"))
+	if len(arg) > 2 && (arg[0] == '"' && arg[len(arg)-1] == '"' || arg[0] == '<' && arg[len(arg)-1] == '>') {
+		return arg[1 : len(arg)-1], nil
+	}
+	return "", fmt.Errorf(`bad include %q. want #include "name"`, directive)
+}
+
+// loadInclude returns the registered chunk with the given name, or loads it with IncludeLoader.
+func loadInclude(name string) (string, error) {
+	if source, ok := chunks[name]; ok {
+		return source, nil
+	}
+	if IncludeLoader == nil {
+		return "", fmt.Errorf("can't include %s: there's no chunk with that name, and no IncludeLoader to load files", name)
+	}
+	data, err := IncludeLoader(name)
+	if err != nil {
+		return "", fmt.Errorf("can't include %s: %v", name, err)
+	}
+	return string(data), nil
+}
+
+// errorLocation matches the source locations in shader info logs. Drivers put them at the start of each message, in
+// a few formats. Source string 0 is the only one, since preprocessed source is passed as a single string.
+//   ERROR: 0:12: 'x' : undeclared identifier   (ANGLE, which is used by most browsers, and Apple)
+//   0:12(5): error: `x' undeclared            (Mesa)
+//   0(12) : error C1008: undefined variable "x" (Nvidia)
+var errorLocation = regexp.MustCompile(`(?m)^(\s*(?:ERROR: |WARNING: )?)0(?::(\d+)|\((\d+)\))`)
+
+// mapErrors replaces the lines in a shader info log with where they were before preprocessing.
+func (p *processed) mapErrors(log string) string {
+	return errorLocation.ReplaceAllStringFunc(log, func(match string) string {
+		groups := errorLocation.FindStringSubmatch(match)
+		line, err := strconv.Atoi(groups[2] + groups[3])
+		if err != nil || line < 1 || line > len(p.lines) {
+			return match
+		}
+		return groups[1] + p.lines[line-1].String()
+	})
+}
+
+// Source is the source of a shader program that's compiled into variants with different defines, like one with
+// shadows and one without. Each variant is compiled the first time it's used, and kept for later.
+//
+// Sample usage:
+//   water := shader.NewSource("water", waterVertexSource, waterFragmentSource)
+//   calm, err := water.Variant(nil)
+//   ...
+//   stormy, err := water.Variant(map[string]string{"WAVES": "8", "FOAM": ""})
+type Source struct {
+	Name                         string
+	vertexFile, fragmentFile     string
+	vertexSource, fragmentSource string
+	variants                     map[string]*Program
+}
+
+// NewSource creates a Source from the source of a vertex and fragment shader. Nothing is compiled until Variant is
+// called, so errors in the source aren't found until then.
+func NewSource(name, vertexSource, fragmentSource string) *Source {
+	return &Source{
+		Name:           name,
+		vertexFile:     name + ".vert",
+		fragmentFile:   name + ".frag",
+		vertexSource:   vertexSource,
+		fragmentSource: fragmentSource,
+		variants:       make(map[string]*Program),
+	}
+}
+
+// LoadSource creates a Source from vertex and fragment shader files, which are loaded with IncludeLoader.
+// Compile errors name the files by their paths.
+func LoadSource(name, vertexPath, fragmentPath string) (*Source, error) {
+	if IncludeLoader == nil {
+		return nil, fmt.Errorf("%s shader: can't load shader files without an IncludeLoader", name)
+	}
+	vertexSource, err := IncludeLoader(vertexPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s shader: %v", name, err)
+	}
+	fragmentSource, err := IncludeLoader(fragmentPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s shader: %v", name, err)
+	}
+	s := NewSource(name, string(vertexSource), string(fragmentSource))
+	s.vertexFile, s.fragmentFile = vertexPath, fragmentPath
+	return s, nil
+}
+
+// Variant returns the program compiled with the provided defines. Empty values define a name without one, for use
+// with #ifdef. The same set of defines always returns the same program, which is only compiled the first time.
+func (s *Source) Variant(defines map[string]string) (*Program, error) {
+	key := strings.Join(sortedDefines(defines), ",")
+	if p, ok := s.variants[key]; ok {
+		return p, nil
+	}
+	name := s.Name
+	if key != "" {
+		name += "[" + key + "]"
+	}
+	p, err := newProgram(name, s.vertexFile, s.vertexSource, s.fragmentFile, s.fragmentSource, defines)
+	if err != nil {
+		return nil, err
+	}
+	s.variants[key] = p
+	return p, nil
+}
+
+// Delete deletes every variant that's been compiled.
+func (s *Source) Delete() {
+	for key, p := range s.variants {
+		p.Delete()
+		delete(s.variants, key)
+	}
+}
@@ -0,0 +1,9 @@
+// +build !js
+
+package shader
+
+// Desktop shaders are GLSL 1.20, which is what OpenGL 2.1 supports. It doesn't have precision qualifiers.
+const (
+	versionDirective  = "#version 120"
+	fragmentPrecision = ""
+)
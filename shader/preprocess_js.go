@@ -0,0 +1,14 @@
+// +build js
+
+package shader
+
+// WebGL shaders are GLSL ES 1.00, where fragment shaders have no default precision for floats. Shadows compare depths
+// that need more precision than mediump has, so use highp where it's supported.
+const (
+	versionDirective  = "#version 100"
+	fragmentPrecision = `#ifdef GL_FRAGMENT_PRECISION_HIGH
+precision highp float;
+#else
+precision mediump float;
+#endif`
+)
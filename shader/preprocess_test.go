@@ -0,0 +1,143 @@
+package shader
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPreprocess(t *testing.T) {
+	RegisterChunk("test/a.glsl", "#include \"test/b.glsl\"\nfloat a() { return b(); }")
+	RegisterChunk("test/b.glsl", "float b() { return 1.0; }")
+	defer delete(chunks, "test/a.glsl")
+	defer delete(chunks, "test/b.glsl")
+	source := "// Comment\n#include \"test/a.glsl\"\n#include \"test/b.glsl\"\nvoid main() {\n}"
+
+	got, err := preprocess("test.vert", source, "", map[string]string{"B": "2", "A": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		line string
+		loc  location
+	}{
+		{versionDirective, location{headerFile, 1}},
+		{"#define A", location{headerFile, 2}},
+		{"#define B 2", location{headerFile, 3}},
+		{"// Comment", location{"test.vert", 1}},
+		{"", location{"test.vert", 2}},
+		{"", location{"test/a.glsl", 1}},
+		{"float b() { return 1.0; }", location{"test/b.glsl", 1}},
+		{"float a() { return b(); }", location{"test/a.glsl", 2}},
+		{"", location{"test.vert", 3}}, // b.glsl was already included.
+		{"void main() {", location{"test.vert", 4}},
+		{"}", location{"test.vert", 5}},
+	}
+	lines := strings.Split(strings.TrimSuffix(got.source, "\n"), "\n")
+	if len(lines) != len(want) || len(got.lines) != len(want) {
+		t.Fatalf("got %d lines with %d locations, want %d:\n%s", len(lines), len(got.lines), len(want), got.source)
+	}
+	for i, w := range want {
+		if lines[i] != w.line || got.lines[i] != w.loc {
+			t.Errorf("line %d: got %q from %v, want %q from %v", i+1, lines[i], got.lines[i], w.line, w.loc)
+		}
+	}
+}
+
+// testPrecision stands in for fragmentPrecision, which is empty on desktops.
+const testPrecision = "#ifdef GL_FRAGMENT_PRECISION_HIGH\nprecision highp float;\n#endif"
+
+func TestPreprocessVersionAndPrecision(t *testing.T) {
+	source := "\n#version 100\n#extension GL_OES_standard_derivatives : enable\n// Comment\nvoid main() {}"
+	got, err := preprocess("test.frag", source, testPrecision, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(got.source, "\n")
+	if lines[0] != "#version 100" || got.lines[0] != (location{"test.frag", 2}) {
+		t.Errorf("got first line %q from %v, want the source's #version", lines[0], got.lines[0])
+	}
+	if strings.Count(got.source, "#version") != 1 {
+		t.Errorf("got more than one #version:\n%s", got.source)
+	}
+	// Precision must come after extensions, but before any code.
+	extension := strings.Index(got.source, "#extension")
+	main := strings.Index(got.source, "void main")
+	if precision := strings.Index(got.source, testPrecision); precision < extension || precision > main {
+		t.Errorf("got precision in the wrong place:\n%s", got.source)
+	}
+	if len(got.lines) != strings.Count(got.source, "\n") {
+		t.Errorf("got %d locations for %d lines", len(got.lines), strings.Count(got.source, "\n"))
+	}
+}
+
+func TestPreprocessPrecisionOutsideConditionals(t *testing.T) {
+	// The first code is in an #ifdef, which would hide the precision if it went right before it.
+	source := "#extension GL_OES_standard_derivatives : enable\n#ifdef SHADOWS\n#if MAX > 1\nuniform sampler2D uShadowMap;\n#endif\n#endif\nuniform vec3 uColor;"
+	got, err := preprocess("test.frag", source, testPrecision, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		versionDirective,
+		"#extension GL_OES_standard_derivatives : enable",
+		testPrecision,
+		"#ifdef SHADOWS",
+		"#if MAX > 1",
+		"uniform sampler2D uShadowMap;",
+		"#endif",
+		"#endif",
+		"uniform vec3 uColor;",
+	}, "\n") + "\n"
+	if got.source != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got.source, want)
+	}
+	if got.lines[5] != (location{"test.frag", 2}) {
+		t.Errorf("got the #ifdef from %v, want test.frag:2", got.lines[5])
+	}
+}
+
+func TestPreprocessErrors(t *testing.T) {
+	RegisterChunk("test/loop.glsl", "#include \"test/loop2.glsl\"")
+	RegisterChunk("test/loop2.glsl", "#include \"test/loop.glsl\"")
+	defer delete(chunks, "test/loop.glsl")
+	defer delete(chunks, "test/loop2.glsl")
+	IncludeLoader = func(path string) ([]byte, error) {
+		return nil, errors.New("not found")
+	}
+	defer func() { IncludeLoader = nil }()
+
+	for _, source := range []string{
+		`#include "test/loop.glsl"`,
+		`#include "missing.glsl"`,
+		`#include missing.glsl`,
+		`#include ""`,
+	} {
+		if _, err := preprocess("test.vert", source, "", nil); err == nil {
+			t.Errorf("got no error for %q", source)
+		}
+	}
+}
+
+func TestMapErrors(t *testing.T) {
+	p := &processed{lines: []location{
+		{headerFile, 1},
+		{"test.frag", 1},
+		{"gome/depth.glsl", 7},
+	}}
+	tests := []struct {
+		log, want string
+	}{
+		{"ERROR: 0:3: 'x' : undeclared identifier", "ERROR: gome/depth.glsl:7: 'x' : undeclared identifier"},
+		{"0:2(5): error: `x' undeclared", "test.frag:1(5): error: `x' undeclared"},
+		{"0(3) : error C1008: undefined variable \"x\"", "gome/depth.glsl:7 : error C1008: undefined variable \"x\""},
+		{"ERROR: 0:2: a\nERROR: 0:3: b", "ERROR: test.frag:1: a\nERROR: gome/depth.glsl:7: b"},
+		{"ERROR: 0:9: past the end", "ERROR: 0:9: past the end"},
+		{"ERROR: 2 compilation errors.", "ERROR: 2 compilation errors."},
+	}
+	for _, test := range tests {
+		if got := p.mapErrors(test.log); got != test.want {
+			t.Errorf("mapErrors(%q) = %q, want %q", test.log, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,553 @@
+package shader
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+)
+
+// Program is a user defined shader program. Unlike the built in shaders, its uniforms and attributes don't need to be
+// looked up by hand. They're found when it's created, and uniforms can be set by name.
+//
+// Models and materials with a Program are drawn with it rather than the Model shader. They still get the same
+// standard uniforms and attributes as the Model shader, as long as the program declares them with the same names:
+//   uniform mat4 uPMatrix, uMVMatrix;
+//   uniform mat4 uTranslationMatrix, uRotationMatrix, uScaleMatrix;
+//   uniform mat3 uNormalMatrix;
+//   uniform vec3 uCameraPosition;
+//   uniform vec4 uColor;
+//   uniform sampler2D uSampler, uNormalMap, uSpecularMap;
+//   uniform vec3 uSpecularColor, uEmissive;
+//   uniform float uShininess, uOpacity;
+//   uniform bool uUnlit;
+//   attribute vec3 aVertexPosition, aNormal;
+//   attribute vec4 aTangent;
+//   attribute vec2 aTextureCoord;
+// Anything it doesn't declare is skipped. Lights are sent the same way too, in arrays of any size up to MaxLights:
+//   uniform int uLightCount;
+//   uniform vec4 uLightPosition[4], uLightDirection[4], uLightColor[4];
+//   uniform vec3 uAmbientLight;
+// See the Model shader's source for how to read them. Shadows are only drawn by the Model shader, so they aren't sent
+// to custom programs.
+//
+// Sample usage:
+//   toon, err := shader.Register("toon", toonVertexSource, toonFragmentSource)
+//   if err != nil {
+//     log.Fatal(err)
+//   }
+//   if err := toon.Set("uBands", float32(4)); err != nil {
+//     log.Println(err)
+//   }
+//   m.Program = toon // m is a model.Model, or use a material.Material's Program.
+type Program struct {
+	// Name identifies the program in errors and in Lookup.
+	Name    string
+	Program gl.Program
+
+	uniforms   map[string]UniformInfo
+	attributes map[string]AttribInfo
+	// uniformList and attributeList are sorted by name. They're returned by Uniforms and Attributes, which are used
+	// for every draw.
+	uniformList   []UniformInfo
+	attributeList []AttribInfo
+	// elements caches the locations of individual array elements, like "uOffsets[2]".
+	elements map[string]gl.Uniform
+	// lightData holds the light uniform arrays, so they aren't allocated every time. See SetLights.
+	lightData [3][]float32
+}
+
+// UniformInfo describes an active uniform in a Program.
+type UniformInfo struct {
+	// Name is the uniform's name in the shader. Arrays are named without their "[0]" suffix.
+	Name string
+	// Type is the GLSL type, like gl.FLOAT_VEC3 or gl.SAMPLER_2D.
+	Type gl.Enum
+	// Size is the number of elements in an array, or 1 if it isn't an array.
+	Size     int
+	Location gl.Uniform
+}
+
+// AttribInfo describes an active attribute in a Program.
+type AttribInfo struct {
+	Name string
+	// Type is the GLSL type, like gl.FLOAT_VEC3.
+	Type     gl.Enum
+	Size     int
+	Location gl.Attrib
+}
+
+// programs holds the programs created with Register.
+var programs = make(map[string]*Program)
+
+// Register creates a program and saves it so it can be found with Lookup. Names must be unique.
+func Register(name, vertexSource, fragmentSource string) (*Program, error) {
+	if _, ok := programs[name]; ok {
+		return nil, fmt.Errorf("shader program %q is already registered", name)
+	}
+	p, err := NewProgram(name, vertexSource, fragmentSource)
+	if err != nil {
+		return nil, err
+	}
+	programs[name] = p
+	return p, nil
+}
+
+// Lookup returns the program registered with the given name.
+func Lookup(name string) (*Program, bool) {
+	p, ok := programs[name]
+	return p, ok
+}
+
+// NewProgram preprocesses, compiles and links a program from the provided source, and finds all of its active
+// uniforms and attributes. It isn't registered, so it can't be found with Lookup. See Register, and CompileProgram for
+// preprocessing.
+func NewProgram(name, vertexSource, fragmentSource string) (*Program, error) {
+	return newProgram(name, name+".vert", vertexSource, name+".frag", fragmentSource, nil)
+}
+
+func newProgram(name, vertexFile, vertexSource, fragmentFile, fragmentSource string, defines map[string]string) (*Program, error) {
+	program, err := compileProgram(name, vertexFile, vertexSource, fragmentFile, fragmentSource, defines)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Program{
+		Name:       name,
+		Program:    program,
+		uniforms:   make(map[string]UniformInfo),
+		attributes: make(map[string]AttribInfo),
+		elements:   make(map[string]gl.Uniform),
+	}
+	for i := 0; i < gl.GetProgrami(program, gl.ACTIVE_UNIFORMS); i++ {
+		uniformName, size, ty := gl.GetActiveUniform(program, uint32(i))
+		uniformName = strings.TrimSuffix(uniformName, "[0]")
+		p.uniforms[uniformName] = UniformInfo{
+			Name:     uniformName,
+			Type:     ty,
+			Size:     size,
+			Location: gl.GetUniformLocation(program, uniformName),
+		}
+	}
+	for i := 0; i < gl.GetProgrami(program, gl.ACTIVE_ATTRIBUTES); i++ {
+		attribName, size, ty := gl.GetActiveAttrib(program, uint32(i))
+		p.attributes[attribName] = AttribInfo{
+			Name:     attribName,
+			Type:     ty,
+			Size:     size,
+			Location: gl.GetAttribLocation(program, attribName),
+		}
+	}
+	for _, u := range p.uniforms {
+		p.uniformList = append(p.uniformList, u)
+	}
+	sort.Slice(p.uniformList, func(i, j int) bool { return p.uniformList[i].Name < p.uniformList[j].Name })
+	for _, a := range p.attributes {
+		p.attributeList = append(p.attributeList, a)
+	}
+	sort.Slice(p.attributeList, func(i, j int) bool { return p.attributeList[i].Name < p.attributeList[j].Name })
+	return p, nil
+}
+
+// Delete frees the program on the GPU and removes it from the registered programs.
+func (p *Program) Delete() {
+	if programs[p.Name] == p {
+		delete(programs, p.Name)
+	}
+	if activeProgram == p.Program {
+		activeProgram = gl.Program{}
+	}
+	gl.DeleteProgram(p.Program)
+	p.Program = gl.Program{}
+}
+
+// Uniforms returns all of the program's active uniforms, sorted by name. The slice is shared, so don't modify it.
+func (p *Program) Uniforms() []UniformInfo {
+	return p.uniformList
+}
+
+// Attributes returns all of the program's active attributes, sorted by name. The slice is shared, so don't modify it.
+func (p *Program) Attributes() []AttribInfo {
+	return p.attributeList
+}
+
+// Uniform returns the active uniform with the given name. Uniforms that are declared but never used are removed by
+// the shader compiler, so they aren't active.
+func (p *Program) Uniform(name string) (UniformInfo, bool) {
+	u, ok := p.uniforms[name]
+	return u, ok
+}
+
+// Attrib returns the active attribute with the given name.
+func (p *Program) Attrib(name string) (AttribInfo, bool) {
+	a, ok := p.attributes[name]
+	return a, ok
+}
+
+// Set sets a uniform by name. The value's type must match the uniform's type:
+//   float, vec2, vec3, vec4    float32, mgl32.Vec2, mgl32.Vec3, mgl32.Vec4
+//   mat2, mat3, mat4           mgl32.Mat2, mgl32.Mat3, mgl32.Mat4
+//   int, sampler2D/Cube        int or int32. Samplers are set to a texture unit. See SetSampler.
+//   bool                       bool
+//   vec3, vec4                 *color.NRGBA or color.NRGBA. Vec3 ignores alpha.
+// Arrays can be set all at once with a slice like []mgl32.Vec3 or []mgl32.Mat4, or one element at a time with names
+// like "uOffsets[2]".
+// []float32 and []int32 can set any float or int based uniform, as long as they have a whole number of elements.
+func (p *Program) Set(name string, value interface{}) error {
+	u, location, err := p.lookup(name)
+	if err != nil {
+		return err
+	}
+	UseProgram(p.Program)
+	return setUniform(location, u.Type, u.Size, value)
+}
+
+// SetIfActive is like Set, but does nothing if the uniform isn't active rather than returning an error.
+// It's useful for optional uniforms, which the shader compiler removes if the program doesn't use them.
+func (p *Program) SetIfActive(name string, value interface{}) error {
+	base := name
+	if b, _, ok := parseElement(name); ok {
+		base = b
+	}
+	if _, ok := p.uniforms[base]; !ok {
+		return nil
+	}
+	return p.Set(name, value)
+}
+
+// SetSampler binds a texture to a texture unit and points the named sampler uniform at it. Cube samplers bind
+// cubemaps. Units used by the standard textures are DiffuseTextureUnit, NormalMapTextureUnit and
+// SpecularMapTextureUnit, so other textures should start at ShadowMapTextureUnit.
+func (p *Program) SetSampler(name string, unit int, texture gl.Texture) error {
+	u, location, err := p.lookup(name)
+	if err != nil {
+		return err
+	}
+	target := gl.Enum(gl.TEXTURE_2D)
+	switch u.Type {
+	case gl.SAMPLER_2D:
+	case gl.SAMPLER_CUBE:
+		target = gl.TEXTURE_CUBE_MAP
+	default:
+		return fmt.Errorf("%s shader: uniform %s is a %s, not a sampler", p.Name, name, typeName(u.Type))
+	}
+	UseProgram(p.Program)
+	gl.ActiveTexture(gl.Enum(gl.TEXTURE0 + unit))
+	gl.BindTexture(target, texture)
+	gl.Uniform1i(location, unit)
+	return nil
+}
+
+// lookup finds a uniform by name, including elements of arrays like "uOffsets[2]". The returned info's Size is the
+// number of elements from that point to the end of the array.
+func (p *Program) lookup(name string) (UniformInfo, gl.Uniform, error) {
+	if u, ok := p.uniforms[name]; ok {
+		return u, u.Location, nil
+	}
+	base, index, ok := parseElement(name)
+	if !ok {
+		return UniformInfo{}, gl.Uniform{}, fmt.Errorf("%s shader: no active uniform named %s", p.Name, name)
+	}
+	u, ok := p.uniforms[base]
+	if !ok {
+		return UniformInfo{}, gl.Uniform{}, fmt.Errorf("%s shader: no active uniform named %s", p.Name, base)
+	}
+	if index >= u.Size {
+		return UniformInfo{}, gl.Uniform{}, fmt.Errorf("%s shader: %s is out of range. %s has %d elements", p.Name, name, base, u.Size)
+	}
+	location, ok := p.elements[name]
+	if !ok {
+		location = gl.GetUniformLocation(p.Program, name)
+		p.elements[name] = location
+	}
+	u.Size -= index
+	return u, location, nil
+}
+
+// parseElement splits a name like "uOffsets[2]" into "uOffsets" and 2.
+func parseElement(name string) (base string, index int, ok bool) {
+	open := strings.LastIndex(name, "[")
+	if open <= 0 || !strings.HasSuffix(name, "]") {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(name[open+1 : len(name)-1])
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return name[:open], index, true
+}
+
+// SetMVPMatrix sets uPMatrix, uMVMatrix and uCameraPosition, the same as the Model shader's SetMVPMatrix.
+func (p *Program) SetMVPMatrix(pMatrix, mvMatrix mgl32.Mat4) {
+	p.setStandard("uPMatrix", pMatrix)
+	p.setStandard("uMVMatrix", mvMatrix)
+	p.setStandard("uCameraPosition", mvMatrix.Inv().Col(3).Vec3())
+}
+
+// SetTransform sets the uTranslationMatrix, uRotationMatrix, uScaleMatrix and uNormalMatrix of a model.
+func (p *Program) SetTransform(translation mgl32.Vec3, rotation mgl32.Quat, scale mgl32.Vec3) {
+	rotationMatrix := rotation.Mat4()
+	scaleMatrix := mgl32.Scale3D(scale.X(), scale.Y(), scale.Z())
+	p.setStandard("uTranslationMatrix", mgl32.Translate3D(translation.X(), translation.Y(), translation.Z()))
+	p.setStandard("uRotationMatrix", rotationMatrix)
+	p.setStandard("uScaleMatrix", scaleMatrix)
+	if _, ok := p.uniforms["uNormalMatrix"]; ok {
+		p.setStandard("uNormalMatrix", NormalMatrix(rotationMatrix.Mat3(), scaleMatrix.Mat3()))
+	}
+}
+
+// SetColor sets uColor. Nil is white.
+func (p *Program) SetColor(c *color.NRGBA) {
+	if c == nil {
+		c = &color.NRGBA{255, 255, 255, 255}
+	}
+	p.setStandard("uColor", c)
+}
+
+// SetTexture sets uSampler, the diffuse texture, to DiffuseTextureUnit. If the texture isn't valid, plain white is
+// used instead.
+func (p *Program) SetTexture(texture gl.Texture) {
+	p.setStandardSampler("uSampler", DiffuseTextureUnit, texture, Model.white)
+}
+
+// SetNormalMap sets uNormalMap to NormalMapTextureUnit. If the texture isn't valid, a flat normal map is used instead.
+func (p *Program) SetNormalMap(texture gl.Texture) {
+	p.setStandardSampler("uNormalMap", NormalMapTextureUnit, texture, Model.flatNormal)
+}
+
+// SetSpecularMap sets uSpecularMap to SpecularMapTextureUnit. If the texture isn't valid, plain white is used instead.
+func (p *Program) SetSpecularMap(texture gl.Texture) {
+	p.setStandardSampler("uSpecularMap", SpecularMapTextureUnit, texture, Model.white)
+}
+
+// SetLights sets uLightCount, uLightPosition, uLightDirection, uLightColor and uAmbientLight, the same as the Model
+// shader's SetLights and SetAmbientLight. Lights past the size of the program's arrays are dropped. A nil ambient
+// light is black.
+func (p *Program) SetLights(lights []Light, ambient *color.NRGBA) {
+	if ambient == nil {
+		ambient = &color.NRGBA{}
+	}
+	p.setStandard("uAmbientLight", ambient)
+
+	if len(lights) > MaxLights {
+		lights = lights[:MaxLights]
+	}
+	for _, name := range []string{"uLightPosition", "uLightDirection", "uLightColor"} {
+		if u, ok := p.uniforms[name]; ok && u.Size < len(lights) {
+			lights = lights[:u.Size]
+		}
+	}
+	p.setStandard("uLightCount", len(lights))
+	if len(lights) == 0 {
+		return
+	}
+	position, direction, colors := packLights(lights, p.lightData[0][:0], p.lightData[1][:0], p.lightData[2][:0])
+	p.setStandard("uLightPosition", position)
+	p.setStandard("uLightDirection", direction)
+	p.setStandard("uLightColor", colors)
+	p.lightData = [3][]float32{position, direction, colors}
+}
+
+// setStandard sets one of the standard uniforms if the program uses it. Their types are documented on Program, so a
+// mismatch is a mistake in the program's source.
+func (p *Program) setStandard(name string, value interface{}) {
+	if err := p.SetIfActive(name, value); err != nil {
+		log.Println(err)
+	}
+}
+
+func (p *Program) setStandardSampler(name string, unit int, texture, fallback gl.Texture) {
+	if _, ok := p.uniforms[name]; !ok {
+		return
+	}
+	if !texture.Valid() {
+		texture = fallback
+	}
+	if err := p.SetSampler(name, unit, texture); err != nil {
+		log.Println(err)
+	}
+}
+
+// setUniform checks that value can be stored in a uniform of the given type and size, and sends it to the GPU.
+// The uniform's program must be active.
+func setUniform(location gl.Uniform, ty gl.Enum, size int, value interface{}) error {
+	floats, ints, err := uniformData(ty, size, value)
+	if err != nil {
+		return err
+	}
+	switch ty {
+	case gl.FLOAT:
+		gl.Uniform1fv(location, floats)
+	case gl.FLOAT_VEC2:
+		gl.Uniform2fv(location, floats)
+	case gl.FLOAT_VEC3:
+		gl.Uniform3fv(location, floats)
+	case gl.FLOAT_VEC4:
+		gl.Uniform4fv(location, floats)
+	case gl.FLOAT_MAT2:
+		gl.UniformMatrix2fv(location, floats)
+	case gl.FLOAT_MAT3:
+		gl.UniformMatrix3fv(location, floats)
+	case gl.FLOAT_MAT4:
+		gl.UniformMatrix4fv(location, floats)
+	case gl.INT, gl.BOOL, gl.SAMPLER_2D, gl.SAMPLER_CUBE:
+		gl.Uniform1iv(location, ints)
+	case gl.INT_VEC2, gl.BOOL_VEC2:
+		gl.Uniform2iv(location, ints)
+	case gl.INT_VEC3, gl.BOOL_VEC3:
+		gl.Uniform3iv(location, ints)
+	case gl.INT_VEC4, gl.BOOL_VEC4:
+		gl.Uniform4iv(location, ints)
+	}
+	return nil
+}
+
+// uniformType is what's needed to check values for a type of uniform.
+type uniformType struct {
+	name string
+	// components is how many floats or ints are in a single element.
+	components int
+	float      bool
+}
+
+var uniformTypes = map[gl.Enum]uniformType{
+	gl.FLOAT:        {"float", 1, true},
+	gl.FLOAT_VEC2:   {"vec2", 2, true},
+	gl.FLOAT_VEC3:   {"vec3", 3, true},
+	gl.FLOAT_VEC4:   {"vec4", 4, true},
+	gl.FLOAT_MAT2:   {"mat2", 4, true},
+	gl.FLOAT_MAT3:   {"mat3", 9, true},
+	gl.FLOAT_MAT4:   {"mat4", 16, true},
+	gl.INT:          {"int", 1, false},
+	gl.INT_VEC2:     {"ivec2", 2, false},
+	gl.INT_VEC3:     {"ivec3", 3, false},
+	gl.INT_VEC4:     {"ivec4", 4, false},
+	gl.BOOL:         {"bool", 1, false},
+	gl.BOOL_VEC2:    {"bvec2", 2, false},
+	gl.BOOL_VEC3:    {"bvec3", 3, false},
+	gl.BOOL_VEC4:    {"bvec4", 4, false},
+	gl.SAMPLER_2D:   {"sampler2D", 1, false},
+	gl.SAMPLER_CUBE: {"samplerCube", 1, false},
+}
+
+func typeName(ty gl.Enum) string {
+	if t, ok := uniformTypes[ty]; ok {
+		return t.name
+	}
+	return fmt.Sprintf("type 0x%X", uint32(ty))
+}
+
+// uniformData converts a value into the floats or ints to send to a uniform of the given type with size elements.
+// It returns an error if they don't match. See Program.Set for which Go types go with which GLSL types.
+func uniformData(ty gl.Enum, size int, value interface{}) (floats []float32, ints []int32, err error) {
+	t, ok := uniformTypes[ty]
+	if !ok {
+		return nil, nil, fmt.Errorf("uniforms of %s aren't supported", typeName(ty))
+	}
+	mismatch := func() error { return fmt.Errorf("can't set a %s uniform with a %T", t.name, value) }
+
+	// want is the only type the value can be used for. Slices of raw floats or ints leave it as 0, and can be used for
+	// any type of the same kind.
+	var want gl.Enum
+	isFloat := true
+	switch v := value.(type) {
+	case float32:
+		floats, want = []float32{v}, gl.FLOAT
+	case float64:
+		floats, want = []float32{float32(v)}, gl.FLOAT
+	case []float32:
+		floats = v
+	case mgl32.Vec2:
+		floats, want = v[:], gl.FLOAT_VEC2
+	case mgl32.Vec3:
+		floats, want = v[:], gl.FLOAT_VEC3
+	case mgl32.Vec4:
+		floats, want = v[:], gl.FLOAT_VEC4
+	case mgl32.Mat2:
+		floats, want = v[:], gl.FLOAT_MAT2
+	case mgl32.Mat3:
+		floats, want = v[:], gl.FLOAT_MAT3
+	case mgl32.Mat4:
+		floats, want = v[:], gl.FLOAT_MAT4
+	case []mgl32.Vec2:
+		for _, e := range v {
+			floats = append(floats, e[:]...)
+		}
+		want = gl.FLOAT_VEC2
+	case []mgl32.Vec3:
+		for _, e := range v {
+			floats = append(floats, e[:]...)
+		}
+		want = gl.FLOAT_VEC3
+	case []mgl32.Vec4:
+		for _, e := range v {
+			floats = append(floats, e[:]...)
+		}
+		want = gl.FLOAT_VEC4
+	case []mgl32.Mat4:
+		for _, e := range v {
+			floats = append(floats, e[:]...)
+		}
+		want = gl.FLOAT_MAT4
+	case color.NRGBA:
+		if floats, ok := colorData(ty, &v); ok {
+			return floats, nil, nil
+		}
+		return nil, nil, mismatch()
+	case *color.NRGBA:
+		if floats, ok := colorData(ty, v); ok {
+			return floats, nil, nil
+		}
+		return nil, nil, mismatch()
+	case int:
+		ints, want, isFloat = []int32{int32(v)}, gl.INT, false
+	case int32:
+		ints, want, isFloat = []int32{v}, gl.INT, false
+	case []int32:
+		ints, isFloat = v, false
+	case bool:
+		ints, want, isFloat = []int32{0}, gl.BOOL, false
+		if v {
+			ints[0] = 1
+		}
+	default:
+		return nil, nil, mismatch()
+	}
+
+	switch {
+	case want == gl.INT && !t.float && t.components == 1:
+		// Single ints also set bools and samplers.
+	case want != 0 && want != ty:
+		return nil, nil, mismatch()
+	case isFloat != t.float:
+		return nil, nil, mismatch()
+	}
+	n := len(floats) + len(ints)
+	if n == 0 || n%t.components != 0 {
+		return nil, nil, fmt.Errorf("can't set a %s uniform with %d values. it needs a multiple of %d", t.name, n, t.components)
+	}
+	if n/t.components > size {
+		return nil, nil, fmt.Errorf("can't set %d elements of a %s uniform that only has %d", n/t.components, t.name, size)
+	}
+	return floats, ints, nil
+}
+
+// colorData converts a color into a vec4, or a vec3 without alpha. It returns false for other types.
+func colorData(ty gl.Enum, c *color.NRGBA) ([]float32, bool) {
+	if c == nil {
+		return nil, false
+	}
+	floats := []float32{float32(c.R) / 255, float32(c.G) / 255, float32(c.B) / 255, float32(c.A) / 255}
+	switch ty {
+	case gl.FLOAT_VEC4:
+		return floats, true
+	case gl.FLOAT_VEC3:
+		return floats[:3], true
+	}
+	return nil, false
+}
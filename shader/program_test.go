@@ -0,0 +1,95 @@
+package shader
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+)
+
+func TestUniformData(t *testing.T) {
+	ident := mgl32.Ident4()
+	tests := []struct {
+		ty     gl.Enum
+		size   int
+		value  interface{}
+		floats []float32
+		ints   []int32
+	}{
+		{gl.FLOAT, 1, float32(2), []float32{2}, nil},
+		{gl.FLOAT_VEC3, 1, mgl32.Vec3{1, 2, 3}, []float32{1, 2, 3}, nil},
+		{gl.FLOAT_VEC3, 2, []mgl32.Vec3{{1, 2, 3}, {4, 5, 6}}, []float32{1, 2, 3, 4, 5, 6}, nil},
+		{gl.FLOAT_VEC2, 3, []float32{1, 2, 3, 4}, []float32{1, 2, 3, 4}, nil},
+		{gl.FLOAT_MAT4, 1, ident, ident[:], nil},
+		{gl.FLOAT_VEC4, 1, &color.NRGBA{255, 0, 255, 0}, []float32{1, 0, 1, 0}, nil},
+		{gl.FLOAT_VEC3, 1, color.NRGBA{0, 255, 0, 255}, []float32{0, 1, 0}, nil},
+		{gl.INT, 1, 7, nil, []int32{7}},
+		{gl.SAMPLER_2D, 1, int32(3), nil, []int32{3}},
+		{gl.BOOL, 1, true, nil, []int32{1}},
+		{gl.BOOL, 1, 0, nil, []int32{0}},
+		{gl.INT_VEC2, 1, []int32{1, 2}, nil, []int32{1, 2}},
+	}
+	for _, test := range tests {
+		floats, ints, err := uniformData(test.ty, test.size, test.value)
+		if err != nil {
+			t.Errorf("%s with %#v: %v", typeName(test.ty), test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(floats, test.floats) || !reflect.DeepEqual(ints, test.ints) {
+			t.Errorf("%s with %#v: got %v %v, want %v %v", typeName(test.ty), test.value, floats, ints, test.floats, test.ints)
+		}
+	}
+}
+
+func TestUniformDataMismatch(t *testing.T) {
+	tests := []struct {
+		ty    gl.Enum
+		size  int
+		value interface{}
+	}{
+		{gl.FLOAT, 1, 1},                             // An int for a float.
+		{gl.FLOAT_VEC3, 1, mgl32.Vec4{}},             // The wrong size of vector.
+		{gl.FLOAT_MAT3, 1, mgl32.Ident4()},           // The wrong size of matrix.
+		{gl.INT, 1, float32(1)},                      // A float for an int.
+		{gl.INT_VEC2, 1, 1},                          // A single int for a vector.
+		{gl.SAMPLER_2D, 1, true},                     // A bool for a sampler.
+		{gl.FLOAT_VEC2, 1, &color.NRGBA{}},           // A color for a vec2.
+		{gl.FLOAT_VEC3, 1, (*color.NRGBA)(nil)},      // A nil color.
+		{gl.FLOAT_VEC2, 1, []float32{1, 2, 3}},       // Not a whole number of elements.
+		{gl.FLOAT_VEC2, 1, []mgl32.Vec2{{}, {}}},     // Too many elements.
+		{gl.FLOAT, 1, []float32{}},                   // Nothing.
+		{gl.FLOAT, 1, "1"},                           // An unsupported Go type.
+		{gl.Enum(0x8B5D), 1, 1},                      // An unsupported GLSL type, sampler1D.
+		{gl.FLOAT_VEC4, 2, []mgl32.Vec3{{}, {}, {}}}, // The wrong type and too many elements.
+	}
+	for _, test := range tests {
+		if _, _, err := uniformData(test.ty, test.size, test.value); err == nil {
+			t.Errorf("%s of size %d with %#v: got no error", typeName(test.ty), test.size, test.value)
+		}
+	}
+}
+
+func TestParseElement(t *testing.T) {
+	tests := []struct {
+		name  string
+		base  string
+		index int
+		ok    bool
+	}{
+		{"uOffsets[2]", "uOffsets", 2, true},
+		{"uLights[10]", "uLights", 10, true},
+		{"uOffsets", "", 0, false},
+		{"uOffsets[]", "", 0, false},
+		{"uOffsets[-1]", "", 0, false},
+		{"uOffsets[x]", "", 0, false},
+		{"[2]", "", 0, false},
+	}
+	for _, test := range tests {
+		base, index, ok := parseElement(test.name)
+		if base != test.base || index != test.index || ok != test.ok {
+			t.Errorf("parseElement(%q) = %q, %d, %v, want %q, %d, %v", test.name, base, index, ok, test.base, test.index, test.ok)
+		}
+	}
+}
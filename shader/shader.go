@@ -14,12 +14,13 @@ import (
 //#version 120 // OpenGL 2.1.
 // or:
 //#version 100 // WebGL.
-// But since these shaders must work for both desktop and webgl we leave them off and expect those to be the defaults.
-// It's a bit risky, but probably fine.
+// But since these shaders must work for both desktop and webgl we leave them off, and CompileProgram adds the right
+// one for the platform. See preprocess.go.
 
 var (
 	Parallax *parallax
 	Model    *model
+	Skybox   *skybox
 
 	// activeProgram is the current active gl program.
 	// Keeping track of this locally allows calls to gl.UseProgram to be avoided if the given program is already active.
@@ -31,6 +32,7 @@ func Initialize() error {
 	errs := make(chan error, 10)
 	errs <- setupParallaxShader()
 	errs <- setupModelShader()
+	errs <- setupSkyboxShader()
 	close(errs)
 	for err := range errs {
 		if err != nil {
@@ -39,6 +41,7 @@ func Initialize() error {
 	}
 	Parallax.SetDefaults()
 	Model.SetDefaults()
+	Skybox.SetDefaults()
 	return nil
 }
 
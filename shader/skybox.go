@@ -0,0 +1,96 @@
+package shader
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+)
+
+const (
+	skyboxVertexSource = `
+attribute vec3 aPosition;
+
+uniform mat4 uPMatrix;
+// uViewMatrix is the camera's rotation, without its translation, so the sky never gets any closer.
+uniform mat4 uViewMatrix;
+
+varying vec3 vDirection;
+
+void main() {
+	vDirection = aPosition;
+	vec4 position = uPMatrix * uViewMatrix * vec4(aPosition, 1.0);
+	// Setting z to w puts the sky at the far plane after perspective division, so it's behind everything else.
+	gl_Position = position.xyww;
+}
+`
+	skyboxFragmentSource = `
+uniform samplerCube uCubemap;
+
+varying vec3 vDirection;
+
+void main() {
+	gl_FragColor = textureCube(uCubemap, vDirection);
+}
+`
+)
+
+type skybox struct {
+	Program gl.Program
+
+	pMatrixUniform    gl.Uniform
+	viewMatrixUniform gl.Uniform
+	cubemapUniform    gl.Uniform
+
+	PositionAttrib gl.Attrib
+}
+
+func setupSkyboxShader() error {
+	if Skybox != nil {
+		return errors.New("Skybox Shader already initialized")
+	}
+
+	program, err := CompileProgram("skybox", skyboxVertexSource, skyboxFragmentSource, nil)
+	if err != nil {
+		return err
+	}
+	gl.ValidateProgram(program)
+	if gl.GetProgrami(program, gl.VALIDATE_STATUS) != gl.TRUE {
+		return fmt.Errorf("skybox shader: gl validate status: %s", gl.GetProgramInfoLog(program))
+	}
+	UseProgram(program)
+
+	Skybox = &skybox{
+		Program: program,
+
+		pMatrixUniform:    gl.GetUniformLocation(program, "uPMatrix"),
+		viewMatrixUniform: gl.GetUniformLocation(program, "uViewMatrix"),
+		cubemapUniform:    gl.GetUniformLocation(program, "uCubemap"),
+
+		PositionAttrib: gl.GetAttribLocation(program, "aPosition"),
+	}
+	// The cubemap is always read from the first texture unit.
+	gl.Uniform1i(Skybox.cubemapUniform, 0)
+	return nil
+}
+
+func (s *skybox) SetDefaults() {
+	UseProgram(s.Program)
+}
+
+// SetMVPMatrix sets the camera's matrices. Only the rotation of the ModelView matrix is used, along with the provided
+// rotation of the sky.
+func (s *skybox) SetMVPMatrix(pMatrix, mvMatrix mgl32.Mat4, rotation mgl32.Quat) {
+	UseProgram(s.Program)
+	view := mvMatrix.Mat3().Mat4().Mul4(rotation.Mat4())
+	gl.UniformMatrix4fv(s.pMatrixUniform, pMatrix[:])
+	gl.UniformMatrix4fv(s.viewMatrixUniform, view[:])
+}
+
+// SetCubemap sets the cubemap that the sky is drawn from.
+func (s *skybox) SetCubemap(texture gl.Texture) {
+	UseProgram(s.Program)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, texture)
+}
@@ -0,0 +1,81 @@
+// ease provides easing functions, which control how an animation speeds up and slows down.
+// Each function takes the fraction of time that's passed, from 0 to 1, and returns the fraction of the animation
+// that should be complete. All of them return 0 for 0 and 1 for 1.
+// See http://easings.net for visual examples.
+//
+// Sample usage:
+//   t := elapsed.Seconds() / duration.Seconds()
+//   position := start.Add(end.Sub(start).Mul(ease.InOutQuad(float32(t))))
+package ease
+
+import "math"
+
+// Func is an easing function.
+type Func func(t float32) float32
+
+// Linear moves at a constant speed.
+func Linear(t float32) float32 {
+	return t
+}
+
+// InQuad starts slowly and speeds up.
+func InQuad(t float32) float32 {
+	return t * t
+}
+
+// OutQuad starts quickly and slows down.
+func OutQuad(t float32) float32 {
+	return t * (2 - t)
+}
+
+// InOutQuad starts slowly, speeds up, and then slows down at the end.
+func InOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return -1 + (4-2*t)*t
+}
+
+// InCubic starts slowly and speeds up, more sharply than InQuad.
+func InCubic(t float32) float32 {
+	return t * t * t
+}
+
+// OutCubic starts quickly and slows down, more sharply than OutQuad.
+func OutCubic(t float32) float32 {
+	t--
+	return t*t*t + 1
+}
+
+// InOutCubic starts slowly, speeds up, and then slows down at the end, more sharply than InOutQuad.
+func InOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	t = 2*t - 2
+	return t*t*t/2 + 1
+}
+
+// InOutSine is a gentle ease in and out that follows a cosine curve.
+func InOutSine(t float32) float32 {
+	return float32(-(math.Cos(math.Pi*float64(t)) - 1) / 2)
+}
+
+// SmoothStep is the classic 3t^2 - 2t^3 curve. It's similar to InOutQuad, but has no sudden change in acceleration
+// halfway through.
+func SmoothStep(t float32) float32 {
+	return t * t * (3 - 2*t)
+}
+
+// Clamped calls f with t limited to the range [0, 1]. If f is nil, Linear is used.
+func Clamped(f Func, t float32) float32 {
+	if t < 0 {
+		t = 0
+	} else if t > 1 {
+		t = 1
+	}
+	if f == nil {
+		return t
+	}
+	return f(t)
+}
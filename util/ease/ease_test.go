@@ -0,0 +1,57 @@
+package ease_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/omustardo/gome/util/ease"
+)
+
+func TestEndpoints(t *testing.T) {
+	funcs := map[string]ease.Func{
+		"Linear":     ease.Linear,
+		"InQuad":     ease.InQuad,
+		"OutQuad":    ease.OutQuad,
+		"InOutQuad":  ease.InOutQuad,
+		"InCubic":    ease.InCubic,
+		"OutCubic":   ease.OutCubic,
+		"InOutCubic": ease.InOutCubic,
+		"InOutSine":  ease.InOutSine,
+		"SmoothStep": ease.SmoothStep,
+	}
+	for name, f := range funcs {
+		if got := f(0); math.Abs(float64(got)) > 1e-6 {
+			t.Errorf("%s(0) = %v, want 0", name, got)
+		}
+		if got := f(1); math.Abs(float64(got-1)) > 1e-6 {
+			t.Errorf("%s(1) = %v, want 1", name, got)
+		}
+		// All of the functions only move forward.
+		prev := f(0)
+		for x := float32(0.01); x <= 1; x += 0.01 {
+			if got := f(x); got < prev-1e-6 {
+				t.Errorf("%s decreases from %v to %v at %v", name, prev, got, x)
+				break
+			} else {
+				prev = got
+			}
+		}
+	}
+}
+
+func TestClamped(t *testing.T) {
+	tests := []struct {
+		f       ease.Func
+		t, want float32
+	}{
+		{nil, 0.5, 0.5},
+		{nil, -1, 0},
+		{ease.InQuad, 2, 1},
+		{ease.InQuad, 0.5, 0.25},
+	}
+	for _, tt := range tests {
+		if got := ease.Clamped(tt.f, tt.t); got != tt.want {
+			t.Errorf("Clamped(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
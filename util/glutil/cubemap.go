@@ -0,0 +1,73 @@
+package glutil
+
+import (
+	"fmt"
+
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/util"
+)
+
+// The faces of a cubemap, in the order they're provided to LoadCubemapData. Each is named for the direction it faces
+// from the center of the cube.
+const (
+	CubemapPositiveX = iota
+	CubemapNegativeX
+	CubemapPositiveY
+	CubemapNegativeY
+	CubemapPositiveZ
+	CubemapNegativeZ
+)
+
+// Cubemap is a texture made of six square images, one on each side of a cube. Rather than a point on an image, it's
+// read with a direction from the center of the cube. This makes it good for things that surround a scene, like skies
+// and reflections of the environment.
+type Cubemap struct {
+	Texture gl.Texture
+	// Size is the width and height of each face, in pixels.
+	Size int
+}
+
+// LoadCubemapData takes raw RGBA image data for each face of a cube and puts it into a cubemap on the GPU.
+// Faces are in the order of the CubemapPositiveX through CubemapNegativeZ constants, and each one is size by size
+// pixels, starting with the top row as seen from the center of the cube with +Y up. The top and bottom faces, +Y and
+// -Y, have -Z and +Z up respectively.
+//
+// If size is a power of two, mipmaps are made so the cubemap looks smooth from far away. Otherwise it can only be read
+// at full size, which is fine for skies.
+// It's up to the caller to delete the cubemap using Delete when it's no longer needed.
+func LoadCubemapData(size int, faces [6][]uint8) (*Cubemap, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("cubemap size must be >0. got %d", size)
+	}
+	for i, face := range faces {
+		if len(face) != size*size*4 {
+			return nil, fmt.Errorf("cubemap face %d has %d bytes, want %d for %d by %d RGBA pixels", i, len(face), size*size*4, size, size)
+		}
+	}
+
+	texture := gl.CreateTexture()
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, texture)
+	for i, face := range faces {
+		gl.TexImage2D(gl.Enum(gl.TEXTURE_CUBE_MAP_POSITIVE_X+i), 0, size, size, gl.RGBA, gl.UNSIGNED_BYTE, face)
+	}
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	// Clamping keeps the edges of each face from blending with the opposite edge, which would leave visible seams.
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	if util.IsPowerOfTwo(size) {
+		gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
+		gl.GenerateMipmap(gl.TEXTURE_CUBE_MAP)
+	} else {
+		gl.TexParameteri(gl.TEXTURE_CUBE_MAP, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	}
+	gl.BindTexture(gl.TEXTURE_CUBE_MAP, gl.Texture{})
+	return &Cubemap{Texture: texture, Size: size}, nil
+}
+
+// Delete frees the cubemap's texture on the GPU.
+func (c *Cubemap) Delete() {
+	if c.Texture.Valid() {
+		gl.DeleteTexture(c.Texture)
+	}
+	c.Texture = gl.Texture{}
+}
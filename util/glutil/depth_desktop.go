@@ -0,0 +1,9 @@
+// +build !js
+
+package glutil
+
+// DepthTextureSupported returns whether depth buffers can be drawn into textures, which shadow maps use if they can.
+// Desktop OpenGL always supports them.
+func DepthTextureSupported() bool {
+	return true
+}
@@ -0,0 +1,9 @@
+// +build js
+
+package glutil
+
+// DepthTextureSupported returns whether depth buffers can be drawn into textures, which shadow maps use if they can.
+// WebGL 1 needs the WEBGL_depth_texture extension, which this enables if it's available.
+func DepthTextureSupported() bool {
+	return extension("WEBGL_depth_texture") != nil
+}
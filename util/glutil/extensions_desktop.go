@@ -0,0 +1,67 @@
+// +build !js
+
+package glutil
+
+import (
+	"strings"
+
+	// goxjs/gl doesn't support instancing or drawing into more than one texture, so those call go-gl directly.
+	// goxjs/gl is built on this same package on desktop, and its functions are loaded when glfw makes the window's
+	// context current, so both draw with the same context and state.
+	gogl "github.com/go-gl/gl/v2.1/gl"
+	"github.com/goxjs/gl"
+)
+
+// instancing caches whether instanced drawing is supported, since the list of extensions doesn't change.
+var instancing struct {
+	checked, supported bool
+}
+
+// InstancingSupported returns whether DrawArraysInstanced, DrawElementsInstanced, and VertexAttribDivisor can be
+// used. On desktop they need the ARB_instanced_arrays and ARB_draw_instanced extensions, which nearly every graphics
+// card has.
+func InstancingSupported() bool {
+	if !instancing.checked {
+		extensions := " " + gl.GetString(gl.EXTENSIONS) + " "
+		instancing.supported = strings.Contains(extensions, " GL_ARB_instanced_arrays ") && strings.Contains(extensions, " GL_ARB_draw_instanced ")
+		instancing.checked = true
+	}
+	return instancing.supported
+}
+
+// VertexAttribDivisor sets how many instances are drawn before an attribute moves to its next value. Zero, the
+// default, moves to the next value for every vertex. Only use it if InstancingSupported.
+func VertexAttribDivisor(a gl.Attrib, divisor int) {
+	gogl.VertexAttribDivisorARB(uint32(a.Value), uint32(divisor))
+}
+
+// DrawArraysInstanced is like gl.DrawArrays, but draws everything the provided number of times.
+// Only use it if InstancingSupported.
+func DrawArraysInstanced(mode gl.Enum, first, count, instances int) {
+	gogl.DrawArraysInstancedARB(uint32(mode), int32(first), int32(count), int32(instances))
+}
+
+// DrawElementsInstanced is like gl.DrawElements, but draws everything the provided number of times.
+// Only use it if InstancingSupported.
+func DrawElementsInstanced(mode gl.Enum, count int, ty gl.Enum, offset, instances int) {
+	gogl.DrawElementsInstancedARB(uint32(mode), int32(count), uint32(ty), gogl.PtrOffset(offset), int32(instances))
+}
+
+// MaxDrawBuffers returns how many textures a framebuffer can draw into at once.
+func MaxDrawBuffers() int {
+	n := gl.GetInteger(gogl.MAX_DRAW_BUFFERS)
+	if attachments := gl.GetInteger(gogl.MAX_COLOR_ATTACHMENTS); attachments < n {
+		n = attachments
+	}
+	return n
+}
+
+// DrawBuffers makes the bound framebuffer draw into its first n color attachments. Fragment shaders write to each one
+// with gl_FragData[i]. Only use it if n <= MaxDrawBuffers.
+func DrawBuffers(n int) {
+	buffers := make([]uint32, n)
+	for i := range buffers {
+		buffers[i] = gogl.COLOR_ATTACHMENT0 + uint32(i)
+	}
+	gogl.DrawBuffers(int32(n), &buffers[0])
+}
@@ -0,0 +1,105 @@
+// +build js
+
+package glutil
+
+import (
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/goxjs/gl"
+)
+
+// instancedArrays is the ANGLE_instanced_arrays extension, or nil if it isn't supported.
+var instancedArrays struct {
+	checked bool
+	ext     *js.Object
+}
+
+// drawBuffers is the WEBGL_draw_buffers extension, or nil if it isn't supported.
+var drawBuffers struct {
+	checked bool
+	ext     *js.Object
+}
+
+// context returns the WebGL context of the page's canvas, or nil if there isn't one. goxjs/gl doesn't expose
+// extensions, so they're enabled directly on the context.
+func context() *js.Object {
+	canvas := js.Global.Get("document").Call("querySelector", "canvas")
+	if canvas == nil || canvas == js.Undefined {
+		return nil
+	}
+	// getContext returns the context that already exists, rather than creating a new one.
+	for _, name := range []string{"webgl", "experimental-webgl"} {
+		if c := canvas.Call("getContext", name); c != nil && c != js.Undefined {
+			return c
+		}
+	}
+	return nil
+}
+
+// extension enables a WebGL extension and returns it, or returns nil if it isn't supported.
+func extension(name string) *js.Object {
+	c := context()
+	if c == nil {
+		return nil
+	}
+	ext := c.Call("getExtension", name)
+	if ext == nil || ext == js.Undefined {
+		return nil
+	}
+	return ext
+}
+
+// InstancingSupported returns whether DrawArraysInstanced, DrawElementsInstanced, and VertexAttribDivisor can be
+// used. WebGL 1 needs the ANGLE_instanced_arrays extension, which this enables if it's available.
+func InstancingSupported() bool {
+	if !instancedArrays.checked {
+		instancedArrays.ext = extension("ANGLE_instanced_arrays")
+		instancedArrays.checked = true
+	}
+	return instancedArrays.ext != nil
+}
+
+// VertexAttribDivisor sets how many instances are drawn before an attribute moves to its next value. Zero, the
+// default, moves to the next value for every vertex. Only use it if InstancingSupported.
+func VertexAttribDivisor(a gl.Attrib, divisor int) {
+	instancedArrays.ext.Call("vertexAttribDivisorANGLE", a.Value, divisor)
+}
+
+// DrawArraysInstanced is like gl.DrawArrays, but draws everything the provided number of times.
+// Only use it if InstancingSupported.
+func DrawArraysInstanced(mode gl.Enum, first, count, instances int) {
+	instancedArrays.ext.Call("drawArraysInstancedANGLE", int(mode), first, count, instances)
+}
+
+// DrawElementsInstanced is like gl.DrawElements, but draws everything the provided number of times.
+// Only use it if InstancingSupported.
+func DrawElementsInstanced(mode gl.Enum, count int, ty gl.Enum, offset, instances int) {
+	instancedArrays.ext.Call("drawElementsInstancedANGLE", int(mode), count, int(ty), offset, instances)
+}
+
+// MaxDrawBuffers returns how many textures a framebuffer can draw into at once. WebGL 1 can only draw into one without
+// the WEBGL_draw_buffers extension, which this enables if it's available.
+func MaxDrawBuffers() int {
+	if !drawBuffers.checked {
+		drawBuffers.ext = extension("WEBGL_draw_buffers")
+		drawBuffers.checked = true
+	}
+	if drawBuffers.ext == nil {
+		return 1
+	}
+	n := context().Call("getParameter", drawBuffers.ext.Get("MAX_DRAW_BUFFERS_WEBGL")).Int()
+	if attachments := context().Call("getParameter", drawBuffers.ext.Get("MAX_COLOR_ATTACHMENTS_WEBGL")).Int(); attachments < n {
+		n = attachments
+	}
+	return n
+}
+
+// DrawBuffers makes the bound framebuffer draw into its first n color attachments. Fragment shaders write to each one
+// with gl_FragData[i], which needs "#extension GL_EXT_draw_buffers : require" in WebGL 1.
+// Only use it if n <= MaxDrawBuffers.
+func DrawBuffers(n int) {
+	buffers := make([]interface{}, n)
+	for i := range buffers {
+		buffers[i] = int(gl.COLOR_ATTACHMENT0) + i
+	}
+	drawBuffers.ext.Call("drawBuffersWEBGL", buffers)
+}
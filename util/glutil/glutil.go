@@ -83,6 +83,14 @@ func LoadBufferVec3(data []mgl32.Vec3) gl.Buffer {
 	return LoadBuffer(bytecoder.Vec3(binary.LittleEndian, data...))
 }
 
+func LoadBufferVec4(data []mgl32.Vec4) gl.Buffer {
+	floats := make([]float32, 0, 4*len(data))
+	for _, v := range data {
+		floats = append(floats, v[:]...)
+	}
+	return LoadBufferFloat32(floats)
+}
+
 // LoadIndexBuffer takes a uint16 slice and stores the underlying data in an ELEMENT_ARRAY buffer on the GPU.
 func LoadIndexBuffer(data []uint16) gl.Buffer {
 	buf := gl.CreateBuffer()
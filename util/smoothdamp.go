@@ -0,0 +1,60 @@
+package util
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SmoothDamp gradually moves current toward target using a critically damped spring, so it approaches quickly but
+// never overshoots. It's based on Unity's Mathf.SmoothDamp: https://docs.unity3d.com/ScriptReference/Mathf.SmoothDamp.html
+//
+// velocity is the current rate of change and is modified by each call, so the same variable must be passed in every time.
+// smoothTime is approximately how many seconds it takes to reach the target. Smaller values are faster.
+// maxSpeed limits the rate of change in units per second. Use a value <= 0 to not limit the speed.
+// deltaTime is the number of seconds since the last call.
+//
+// The result is independent of frame rate, as long as maxSpeed isn't reached: calling this twice with a deltaTime of
+// 0.5 ends up in the same place as calling it once with a deltaTime of 1.
+func SmoothDamp(current, target float32, velocity *float32, smoothTime, maxSpeed, deltaTime float32) float32 {
+	c := mgl32.Vec3{current}
+	v := mgl32.Vec3{*velocity}
+	result := SmoothDampVec3(c, mgl32.Vec3{target}, &v, smoothTime, maxSpeed, deltaTime)
+	*velocity = v.X()
+	return result.X()
+}
+
+// SmoothDampVec3 gradually moves current toward target using a critically damped spring. See SmoothDamp.
+// maxSpeed limits the length of the velocity vector, rather than each dimension independently.
+func SmoothDampVec3(current, target mgl32.Vec3, velocity *mgl32.Vec3, smoothTime, maxSpeed, deltaTime float32) mgl32.Vec3 {
+	if deltaTime <= 0 {
+		return current
+	}
+	if smoothTime < 1e-4 {
+		smoothTime = 1e-4
+	}
+	omega := 2 / smoothTime
+	decay := float32(math.Exp(float64(-omega * deltaTime)))
+
+	originalTarget := target
+	change := current.Sub(target)
+	// Limiting how far away the target can be effectively limits the speed.
+	if maxSpeed > 0 {
+		if maxChange := maxSpeed * smoothTime; change.Len() > maxChange {
+			change = change.Normalize().Mul(maxChange)
+		}
+	}
+	target = current.Sub(change)
+
+	// Exact solution of a critically damped spring after deltaTime seconds.
+	temp := velocity.Add(change.Mul(omega)).Mul(deltaTime)
+	*velocity = velocity.Sub(temp.Mul(omega)).Mul(decay)
+	result := target.Add(change.Add(temp).Mul(decay))
+
+	// Prevent overshooting the original target.
+	if originalTarget.Sub(current).Dot(result.Sub(originalTarget)) > 0 {
+		result = originalTarget
+		*velocity = mgl32.Vec3{}
+	}
+	return result
+}
@@ -38,6 +38,16 @@ func ScaleQuatRotation(q mgl32.Quat, percent float32) mgl32.Quat {
 	return mgl32.QuatSlerp(mgl32.QuatIdent(), q, percent) // TODO: Confirm that this doesn't need to be normalized.
 }
 
+// QuatSlerpShortest spherically interpolates from q1 to q2, like mgl32.QuatSlerp, but always rotates the short way around.
+// q and -q represent the same orientation, so mgl32.QuatSlerp can end up turning almost a full circle to get
+// between two orientations that are actually close together.
+func QuatSlerpShortest(q1, q2 mgl32.Quat, amount float32) mgl32.Quat {
+	if q1.Dot(q2) < 0 {
+		q2 = q2.Scale(-1)
+	}
+	return mgl32.QuatSlerp(q1, q2, amount).Normalize()
+}
+
 func IsPowerOfTwo(n int) bool {
 	// http://www.graphics.stanford.edu/~seander/bithacks.html#DetermineIfPowerOf2
 	return (n > 0) && (n&(n-1)) == 0
@@ -61,3 +61,30 @@ func TestRoundUpToPowerOfTwo(t *testing.T) {
 		}
 	}
 }
+
+func TestSmoothDampFrameRateIndependent(t *testing.T) {
+	// One large step should end up in the same place as many small steps covering the same time.
+	var v1, v2 float32
+	got1 := SmoothDamp(0, 10, &v1, 0.5, 0, 1)
+	got2 := float32(0)
+	for i := 0; i < 100; i++ {
+		got2 = SmoothDamp(got2, 10, &v2, 0.5, 0, 0.01)
+	}
+	if diff := got1 - got2; diff > 1e-3 || diff < -1e-3 {
+		t.Errorf("got %v with 1 step and %v with 100 steps, want equal", got1, got2)
+	}
+	if got1 <= 0 || got1 >= 10 {
+		t.Errorf("got %v, want between 0 and 10", got1)
+	}
+}
+
+func TestSmoothDampMaxSpeed(t *testing.T) {
+	var v, current float32
+	for i := 0; i < 60; i++ {
+		next := SmoothDamp(current, 100, &v, 0.1, 5, 1.0/60)
+		if speed := (next - current) * 60; speed > 5+1e-3 {
+			t.Fatalf("step %d: moved at %v units per second, want at most 5", i, speed)
+		}
+		current = next
+	}
+}
@@ -0,0 +1,198 @@
+package postprocess
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/shader"
+	"github.com/omustardo/gome/view/rendertarget"
+)
+
+// MaxBlurRadius is the most pixels that Blur and Bloom spread each pixel across, in each direction.
+const MaxBlurRadius = 16
+
+const (
+	// blurSource blurs in a single direction. Blurring horizontally and then vertically is the same as blurring in
+	// both directions at once, but takes far fewer samples.
+	// MAX_BLUR_RADIUS is MaxBlurRadius.
+	blurSource = `
+uniform vec2 uDirection;
+uniform int uRadius;
+// uWeights holds how much each pixel contributes, starting with the center one. They add up to 1, counting every
+// weight after the first twice since they're used on both sides.
+uniform float uWeights[MAX_BLUR_RADIUS + 1];
+
+void main() {
+	vec4 sum = texture2D(uSource, vTextureCoord) * uWeights[0];
+	for (int i = 1; i <= MAX_BLUR_RADIUS; i++) {
+		if (i > uRadius) {
+			break;
+		}
+		vec2 offset = uDirection * uTexelSize * float(i);
+		sum += (texture2D(uSource, vTextureCoord + offset) + texture2D(uSource, vTextureCoord - offset)) * uWeights[i];
+	}
+	gl_FragColor = sum;
+}
+`
+	brightSource = `
+#include "gome/color.glsl"
+
+uniform float uThreshold;
+
+void main() {
+	vec3 color = texture2D(uSource, vTextureCoord).rgb;
+	float brightness = luminance(color);
+	// Keep only how far the pixel is above the threshold, so glows fade in smoothly.
+	gl_FragColor = vec4(color * max(brightness - uThreshold, 0.0) / max(brightness, 0.0001), 1.0);
+}
+`
+	bloomSource = `
+uniform sampler2D uBloom;
+uniform float uIntensity;
+
+void main() {
+	vec4 color = texture2D(uSource, vTextureCoord);
+	gl_FragColor = vec4(color.rgb + texture2D(uBloom, vTextureCoord).rgb * uIntensity, color.a);
+}
+`
+)
+
+// Blur is a gaussian blur, which softens the whole image.
+type Blur struct {
+	Toggle
+	// Radius is how many pixels each pixel is spread across, in each direction. Up to MaxBlurRadius.
+	Radius int
+
+	temp *rendertarget.RenderTarget
+}
+
+// NewBlur creates a Blur with the provided Radius.
+func NewBlur(radius int) *Blur {
+	return &Blur{Radius: radius}
+}
+
+func (e *Blur) Apply(input, output *rendertarget.RenderTarget) error {
+	width, height := input.Size()
+	if err := ensure(&e.temp, width, height); err != nil {
+		return err
+	}
+	return blur(input, e.temp, output, e.Radius)
+}
+
+func (e *Blur) Delete() {
+	if e.temp != nil {
+		e.temp.Delete()
+		e.temp = nil
+	}
+}
+
+// blur blurs the input horizontally into temp, and then vertically into the output.
+func blur(input, temp, output *rendertarget.RenderTarget, radius int) error {
+	if radius < 0 || radius > MaxBlurRadius {
+		return fmt.Errorf("blur radius must be in [0,%d]. got %d", MaxBlurRadius, radius)
+	}
+	s, err := cached("postprocess/blur", blurSource)
+	if err != nil {
+		return err
+	}
+	shader.UseProgram(s.Program)
+	gl.Uniform1i(s.Uniform("uRadius"), radius)
+	gl.Uniform1fv(s.Uniform("uWeights"), gaussianWeights(radius))
+	gl.Uniform2f(s.Uniform("uDirection"), 1, 0)
+	s.Draw(input, temp)
+	gl.Uniform2f(s.Uniform("uDirection"), 0, 1)
+	s.Draw(temp, output)
+	return nil
+}
+
+// gaussianWeights returns how much each pixel contributes to a blur of the provided radius, starting from the center.
+// The weights follow a normal distribution that fades to nearly nothing at the radius. They add up to one, counting
+// each weight after the first twice since they're used on both sides of the center.
+func gaussianWeights(radius int) []float32 {
+	weights := make([]float32, radius+1)
+	sigma := float64(radius) / 2
+	if sigma == 0 {
+		weights[0] = 1
+		return weights
+	}
+	var sum float64
+	for i := range weights {
+		w := math.Exp(-float64(i*i) / (2 * sigma * sigma))
+		weights[i] = float32(w)
+		if i == 0 {
+			sum += w
+		} else {
+			sum += 2 * w
+		}
+	}
+	for i := range weights {
+		weights[i] = float32(float64(weights[i]) / sum)
+	}
+	return weights
+}
+
+// Bloom makes bright parts of the image glow, spilling light onto their surroundings.
+type Bloom struct {
+	Toggle
+	// Threshold is how bright a pixel must be to glow, from 0 to 1.
+	Threshold float32
+	// Intensity multiplies the brightness of the glow.
+	Intensity float32
+	// Radius is how far the glow spreads, in pixels of a half size copy of the image. Up to MaxBlurRadius.
+	Radius int
+
+	// bright and temp are half the size of the input, which is faster and spreads the glow further.
+	bright, temp *rendertarget.RenderTarget
+}
+
+// NewBloom creates a Bloom that makes the brightest parts of the image glow.
+func NewBloom() *Bloom {
+	return &Bloom{
+		Threshold: 0.7,
+		Intensity: 1,
+		Radius:    8,
+	}
+}
+
+func (e *Bloom) Apply(input, output *rendertarget.RenderTarget) error {
+	width, height := input.Size()
+	width, height = (width+1)/2, (height+1)/2
+	if err := ensure(&e.bright, width, height); err != nil {
+		return err
+	}
+	if err := ensure(&e.temp, width, height); err != nil {
+		return err
+	}
+
+	bright, err := cached("postprocess/bright", brightSource)
+	if err != nil {
+		return err
+	}
+	shader.UseProgram(bright.Program)
+	gl.Uniform1f(bright.Uniform("uThreshold"), e.Threshold)
+	bright.Draw(input, e.bright)
+
+	if err := blur(e.bright, e.temp, e.bright, e.Radius); err != nil {
+		return err
+	}
+
+	combine, err := cached("postprocess/bloom", bloomSource)
+	if err != nil {
+		return err
+	}
+	shader.UseProgram(combine.Program)
+	gl.Uniform1i(combine.Uniform("uBloom"), 1)
+	gl.Uniform1f(combine.Uniform("uIntensity"), e.Intensity)
+	combine.Draw(input, output, e.bright.Texture())
+	return nil
+}
+
+func (e *Bloom) Delete() {
+	for _, t := range []*rendertarget.RenderTarget{e.bright, e.temp} {
+		if t != nil {
+			t.Delete()
+		}
+	}
+	e.bright, e.temp = nil, nil
+}
@@ -0,0 +1,241 @@
+package postprocess
+
+import (
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/shader"
+	"github.com/omustardo/gome/view/rendertarget"
+)
+
+const (
+	// FXAA 3.11 by Timothy Lottes, simplified to a single pass with no quality presets.
+	fxaaSource = `
+#define FXAA_REDUCE_MIN (1.0 / 128.0)
+#define FXAA_REDUCE_MUL (1.0 / 8.0)
+#define FXAA_SPAN_MAX 8.0
+
+void main() {
+	vec3 nw = texture2D(uSource, vTextureCoord + vec2(-1.0, -1.0) * uTexelSize).rgb;
+	vec3 ne = texture2D(uSource, vTextureCoord + vec2(1.0, -1.0) * uTexelSize).rgb;
+	vec3 sw = texture2D(uSource, vTextureCoord + vec2(-1.0, 1.0) * uTexelSize).rgb;
+	vec3 se = texture2D(uSource, vTextureCoord + vec2(1.0, 1.0) * uTexelSize).rgb;
+	vec4 center = texture2D(uSource, vTextureCoord);
+
+	vec3 toLuma = vec3(0.299, 0.587, 0.114);
+	float lumaNW = dot(nw, toLuma);
+	float lumaNE = dot(ne, toLuma);
+	float lumaSW = dot(sw, toLuma);
+	float lumaSE = dot(se, toLuma);
+	float lumaM = dot(center.rgb, toLuma);
+	float lumaMin = min(lumaM, min(min(lumaNW, lumaNE), min(lumaSW, lumaSE)));
+	float lumaMax = max(lumaM, max(max(lumaNW, lumaNE), max(lumaSW, lumaSE)));
+
+	// Blur along the edge, which runs perpendicular to the direction the brightness changes fastest.
+	vec2 dir = vec2(-((lumaNW + lumaNE) - (lumaSW + lumaSE)), (lumaNW + lumaSW) - (lumaNE + lumaSE));
+	float dirReduce = max((lumaNW + lumaNE + lumaSW + lumaSE) * (0.25 * FXAA_REDUCE_MUL), FXAA_REDUCE_MIN);
+	float rcpDirMin = 1.0 / (min(abs(dir.x), abs(dir.y)) + dirReduce);
+	dir = clamp(dir * rcpDirMin, vec2(-FXAA_SPAN_MAX), vec2(FXAA_SPAN_MAX)) * uTexelSize;
+
+	vec3 a = 0.5 * (
+		texture2D(uSource, vTextureCoord + dir * (1.0 / 3.0 - 0.5)).rgb +
+		texture2D(uSource, vTextureCoord + dir * (2.0 / 3.0 - 0.5)).rgb);
+	vec3 b = a * 0.5 + 0.25 * (
+		texture2D(uSource, vTextureCoord + dir * -0.5).rgb +
+		texture2D(uSource, vTextureCoord + dir * 0.5).rgb);
+	// If the wider blur reached past the edge into something else, use the narrower one.
+	float lumaB = dot(b, toLuma);
+	if (lumaB < lumaMin || lumaB > lumaMax) {
+		gl_FragColor = vec4(a, center.a);
+	} else {
+		gl_FragColor = vec4(b, center.a);
+	}
+}
+`
+	vignetteSource = `
+uniform float uRadius;
+uniform float uSoftness;
+uniform float uStrength;
+uniform vec3 uColor;
+
+void main() {
+	vec4 color = texture2D(uSource, vTextureCoord);
+	// Distance from the center, from 0 there to 1 in the corners.
+	float d = length(vTextureCoord - 0.5) * 1.41421356;
+	float shade = 1.0 - smoothstep(uRadius - uSoftness, uRadius, d);
+	gl_FragColor = vec4(mix(color.rgb, uColor, (1.0 - shade) * uStrength), color.a);
+}
+`
+	motionBlurSource = `
+uniform sampler2D uHistory;
+uniform float uAmount;
+
+void main() {
+	gl_FragColor = mix(texture2D(uSource, vTextureCoord), texture2D(uHistory, vTextureCoord), uAmount);
+}
+`
+)
+
+// FXAA smooths jagged edges. It's much cheaper than multisampling, but slightly blurs the whole image, so it's best
+// applied last, after effects that add detail.
+type FXAA struct {
+	Toggle
+}
+
+func NewFXAA() *FXAA {
+	return &FXAA{}
+}
+
+func (e *FXAA) Apply(input, output *rendertarget.RenderTarget) error {
+	s, err := cached("postprocess/fxaa", fxaaSource)
+	if err != nil {
+		return err
+	}
+	s.Draw(input, output)
+	return nil
+}
+
+// Vignette darkens the edges of the screen, drawing attention to the center.
+type Vignette struct {
+	Toggle
+	// Radius is where the edges are fully darkened, from 0 at the center of the screen to 1 in the corners.
+	Radius float32
+	// Softness is how far the darkening fades in toward the center from Radius.
+	Softness float32
+	// Strength is how much the edges are darkened, from 0 for not at all to 1 for entirely Color.
+	Strength float32
+	// Color is what the edges fade to, with each value from 0 to 1. Black by default.
+	Color [3]float32
+}
+
+// NewVignette creates a Vignette that gently darkens the corners.
+func NewVignette() *Vignette {
+	return &Vignette{
+		Radius:   1,
+		Softness: 0.6,
+		Strength: 0.6,
+	}
+}
+
+func (e *Vignette) Apply(input, output *rendertarget.RenderTarget) error {
+	s, err := cached("postprocess/vignette", vignetteSource)
+	if err != nil {
+		return err
+	}
+	shader.UseProgram(s.Program)
+	gl.Uniform1f(s.Uniform("uRadius"), e.Radius)
+	gl.Uniform1f(s.Uniform("uSoftness"), e.Softness)
+	gl.Uniform1f(s.Uniform("uStrength"), e.Strength)
+	gl.Uniform3f(s.Uniform("uColor"), e.Color[0], e.Color[1], e.Color[2])
+	s.Draw(input, output)
+	return nil
+}
+
+// MotionBlur blends each frame with the ones before it, so moving things leave trails.
+type MotionBlur struct {
+	Toggle
+	// Amount is how much of the previous frames remain, from 0 for none to 1 for the first frame forever.
+	Amount float32
+
+	// history holds the blended frames. Each frame reads one and draws into the other.
+	history [2]*rendertarget.RenderTarget
+	current int
+	// started is whether history holds a frame yet.
+	started bool
+}
+
+// NewMotionBlur creates a MotionBlur with the provided Amount.
+func NewMotionBlur(amount float32) *MotionBlur {
+	return &MotionBlur{Amount: amount}
+}
+
+func (e *MotionBlur) Apply(input, output *rendertarget.RenderTarget) error {
+	width, height := input.Size()
+	for i := range e.history {
+		if e.history[i] != nil {
+			if w, h := e.history[i].Size(); w != width || h != height {
+				e.started = false // Resizing clears the history.
+			}
+		}
+		if err := ensure(&e.history[i], width, height); err != nil {
+			return err
+		}
+	}
+	blend, err := cached("postprocess/motionblur", motionBlurSource)
+	if err != nil {
+		return err
+	}
+	copier, err := cached("postprocess/copy", copySource)
+	if err != nil {
+		return err
+	}
+
+	previous, next := e.history[e.current], e.history[1-e.current]
+	amount := e.Amount
+	if !e.started {
+		amount = 0
+	}
+	shader.UseProgram(blend.Program)
+	gl.Uniform1i(blend.Uniform("uHistory"), 1)
+	gl.Uniform1f(blend.Uniform("uAmount"), amount)
+	blend.Draw(input, next, previous.Texture())
+	copier.Draw(next, output)
+
+	e.current = 1 - e.current
+	e.started = true
+	return nil
+}
+
+func (e *MotionBlur) Delete() {
+	for i, t := range e.history {
+		if t != nil {
+			t.Delete()
+		}
+		e.history[i] = nil
+	}
+	e.started = false
+}
+
+// ShaderEffect is an effect made from a single fragment shader. See Shader for what the fragment shader can use.
+//
+// Sample usage:
+//   grayscale, err := postprocess.NewShaderEffect("grayscale", `
+//   uniform float uAmount;
+//   void main() {
+//     vec4 color = texture2D(uSource, vTextureCoord);
+//     float gray = dot(color.rgb, vec3(0.299, 0.587, 0.114));
+//     gl_FragColor = vec4(mix(color.rgb, vec3(gray), uAmount), color.a);
+//   }`, func(s *postprocess.Shader) {
+//     gl.Uniform1f(s.Uniform("uAmount"), 0.8)
+//   })
+type ShaderEffect struct {
+	Toggle
+	Shader *Shader
+	// SetUniforms is called before each draw, while the shader's program is in use, to set its uniforms.
+	// It can be nil.
+	SetUniforms func(s *Shader)
+}
+
+// NewShaderEffect compiles a fragment shader into an effect. The name identifies it in compile errors.
+func NewShaderEffect(name, fragmentSource string, setUniforms func(s *Shader)) (*ShaderEffect, error) {
+	s, err := NewShader(name, fragmentSource)
+	if err != nil {
+		return nil, err
+	}
+	return &ShaderEffect{
+		Shader:      s,
+		SetUniforms: setUniforms,
+	}, nil
+}
+
+func (e *ShaderEffect) Apply(input, output *rendertarget.RenderTarget) error {
+	shader.UseProgram(e.Shader.Program)
+	if e.SetUniforms != nil {
+		e.SetUniforms(e.Shader)
+	}
+	e.Shader.Draw(input, output)
+	return nil
+}
+
+// Delete frees the shader's program.
+func (e *ShaderEffect) Delete() {
+	gl.DeleteProgram(e.Shader.Program)
+}
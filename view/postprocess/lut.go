@@ -0,0 +1,124 @@
+package postprocess
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/shader"
+	"github.com/omustardo/gome/view/rendertarget"
+)
+
+const colorGradeSource = `
+uniform sampler2D uLUT;
+uniform float uLUTSize;
+uniform float uIntensity;
+
+// grade looks up a color in the LUT. Blue picks the tile, and red and green pick the pixel within it. Blue is blended
+// between the two nearest tiles, and red and green are blended by the texture's linear filtering.
+vec3 grade(vec3 color) {
+	float blue = color.b * (uLUTSize - 1.0);
+	float tile = floor(blue);
+	float nextTile = min(tile + 1.0, uLUTSize - 1.0);
+	vec2 uv = (color.rg * (uLUTSize - 1.0) + 0.5) / vec2(uLUTSize * uLUTSize, uLUTSize);
+	vec3 a = texture2D(uLUT, uv + vec2(tile / uLUTSize, 0.0)).rgb;
+	vec3 b = texture2D(uLUT, uv + vec2(nextTile / uLUTSize, 0.0)).rgb;
+	return mix(a, b, blue - tile);
+}
+
+void main() {
+	vec4 color = texture2D(uSource, vTextureCoord);
+	gl_FragColor = vec4(mix(color.rgb, grade(clamp(color.rgb, 0.0, 1.0)), uIntensity), color.a);
+}
+`
+
+// ColorGrade changes the colors of the image using a lookup table (LUT), which maps every color to a new one.
+// This can do nearly any change to colors at once, like adjusting contrast, saturation, and tint to give a scene
+// a mood.
+//
+// A LUT is an image made of Size square tiles in a row, each Size pixels across. Within a tile, red increases to
+// the right and green increases downward. Blue increases from one tile to the next. To make one, save IdentityLUT as
+// a PNG, make the same changes to it as to a screenshot in an image editor, and load it with LoadLUT.
+type ColorGrade struct {
+	Toggle
+	// LUT belongs to the caller, so it isn't deleted with the effect.
+	LUT gl.Texture
+	// Size is the number of tiles in the LUT, and the width and height of each one.
+	Size int
+	// Intensity is how much of the change is used, from 0 for none to 1 for all of it.
+	Intensity float32
+}
+
+// NewColorGrade creates a ColorGrade that fully applies the LUT.
+func NewColorGrade(lut gl.Texture, size int) *ColorGrade {
+	return &ColorGrade{
+		LUT:       lut,
+		Size:      size,
+		Intensity: 1,
+	}
+}
+
+func (e *ColorGrade) Active() bool {
+	return !e.Disabled && e.LUT.Valid()
+}
+
+func (e *ColorGrade) Apply(input, output *rendertarget.RenderTarget) error {
+	if e.Size < 2 {
+		return fmt.Errorf("LUT size must be >1. got %d", e.Size)
+	}
+	s, err := cached("postprocess/colorgrade", colorGradeSource)
+	if err != nil {
+		return err
+	}
+	shader.UseProgram(s.Program)
+	gl.Uniform1i(s.Uniform("uLUT"), 1)
+	gl.Uniform1f(s.Uniform("uLUTSize"), float32(e.Size))
+	gl.Uniform1f(s.Uniform("uIntensity"), e.Intensity)
+	s.Draw(input, output, e.LUT)
+	return nil
+}
+
+// IdentityLUT returns a LUT that leaves every color unchanged. See ColorGrade for how it's laid out.
+// A size of 16 or 32 is usually enough, since colors in between are blended.
+func IdentityLUT(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size*size, size))
+	if size < 2 {
+		return img
+	}
+	scale := 255 / float64(size-1)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size*size; x++ {
+			i := img.PixOffset(x, y)
+			img.Pix[i+0] = uint8(float64(x%size)*scale + 0.5)
+			img.Pix[i+1] = uint8(float64(y)*scale + 0.5)
+			img.Pix[i+2] = uint8(float64(x/size)*scale + 0.5)
+			img.Pix[i+3] = 255
+		}
+	}
+	return img
+}
+
+// LoadLUT puts a LUT on the GPU, and returns it along with its size. See ColorGrade for how it must be laid out.
+// Unlike other textures, it's read exactly as it is, so it doesn't need to be a power of two.
+// It's up to the caller to delete the texture using gl.DeleteTexture(texture) when it's no longer needed.
+func LoadLUT(img image.Image) (gl.Texture, int, error) {
+	bounds := img.Bounds()
+	size := bounds.Dy()
+	if size < 2 || bounds.Dx() != size*size {
+		return gl.Texture{}, 0, fmt.Errorf("LUT must be N*N by N pixels for some N >1. got %d by %d", bounds.Dx(), bounds.Dy())
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+
+	texture := gl.CreateTexture()
+	gl.BindTexture(gl.TEXTURE_2D, texture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, rgba.Rect.Dx(), rgba.Rect.Dy(), gl.RGBA, gl.UNSIGNED_BYTE, rgba.Pix)
+	// Mipmaps would blend neighboring tiles together, and aren't allowed for sizes that aren't powers of two in WebGL.
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, gl.Texture{})
+	return texture, size, nil
+}
@@ -0,0 +1,177 @@
+// postprocess applies fullscreen effects, like bloom and blur, to everything drawn in a frame.
+package postprocess
+
+import (
+	"fmt"
+
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/view/rendertarget"
+)
+
+// Effect changes how a frame looks. Effects are applied one after another, each one reading what the previous one
+// drew.
+type Effect interface {
+	// Active returns whether the effect should be applied. Inactive effects are skipped.
+	Active() bool
+	// SetActive turns the effect on or off.
+	SetActive(active bool)
+	// Apply draws the input, with the effect applied, into the output. If the output is nil, it draws into whatever
+	// is currently being drawn into, which is usually the window.
+	Apply(input, output *rendertarget.RenderTarget) error
+	// Delete frees anything the effect created on the GPU.
+	Delete()
+}
+
+// Toggle turns an effect on and off. Effects embed it to implement Active and SetActive, and to get a Delete that
+// does nothing, which effects that create anything on the GPU replace with their own.
+type Toggle struct {
+	// Disabled effects are skipped.
+	Disabled bool
+}
+
+func (t *Toggle) Active() bool {
+	return !t.Disabled
+}
+
+func (t *Toggle) SetActive(active bool) {
+	t.Disabled = !active
+}
+
+func (t *Toggle) Delete() {}
+
+// Pipeline draws a scene into a texture, and then draws it to the window through a chain of effects.
+// Effects can be added, removed, reordered, and toggled at any time by changing Effects, or the effects themselves.
+//
+// Sample usage:
+//   bloom := postprocess.NewBloom()
+//   post, err := postprocess.New(width, height, bloom, postprocess.NewVignette(), postprocess.NewFXAA())
+//   if err != nil {
+//     log.Fatal(err)
+//   }
+//   for { // game loop
+//     if keyboard.Handler.JustPressed(glfw.KeyB) {
+//       bloom.Disabled = !bloom.Disabled
+//     }
+//     post.Begin()
+//     gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+//     // Draw the scene.
+//     if err := post.End(); err != nil {
+//       log.Println(err)
+//     }
+//   }
+type Pipeline struct {
+	Effects []Effect
+
+	scene *rendertarget.RenderTarget
+	// buffers hold the output of each effect before the last. They take turns being read from and drawn into.
+	buffers [2]*rendertarget.RenderTarget
+}
+
+// New creates a Pipeline that draws scenes of the provided size, which is usually the size of the window.
+// It's up to the caller to delete it using Delete when it's no longer needed.
+func New(width, height int, effects ...Effect) (*Pipeline, error) {
+	scene, err := rendertarget.New(width, height, rendertarget.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return &Pipeline{
+		Effects: effects,
+		scene:   scene,
+	}, nil
+}
+
+// Resize changes the size of the scene. This should be called when the window is resized.
+func (p *Pipeline) Resize(width, height int) error {
+	return p.scene.Resize(width, height)
+}
+
+// Scene returns the target that the scene is drawn into between Begin and End.
+func (p *Pipeline) Scene() *rendertarget.RenderTarget {
+	return p.scene
+}
+
+// Begin makes everything drawn go into the scene, until End is called. The scene isn't cleared, so call gl.Clear as
+// usual.
+func (p *Pipeline) Begin() {
+	p.scene.Bind()
+}
+
+// End stops drawing into the scene, and draws the scene through every active effect into whatever was being drawn
+// into before Begin.
+func (p *Pipeline) End() error {
+	p.scene.Unbind()
+
+	var active []Effect
+	for _, e := range p.Effects {
+		if e != nil && e.Active() {
+			active = append(active, e)
+		}
+	}
+
+	// Effects replace every pixel, so nothing should be hidden or blended with what's already there.
+	depthTest, blend := gl.IsEnabled(gl.DEPTH_TEST), gl.IsEnabled(gl.BLEND)
+	gl.Disable(gl.DEPTH_TEST)
+	gl.Disable(gl.BLEND)
+	defer func() {
+		if depthTest {
+			gl.Enable(gl.DEPTH_TEST)
+		}
+		if blend {
+			gl.Enable(gl.BLEND)
+		}
+	}()
+
+	if len(active) == 0 {
+		s, err := cached("postprocess/copy", copySource)
+		if err != nil {
+			return err
+		}
+		s.Draw(p.scene, nil)
+		return nil
+	}
+
+	width, height := p.scene.Size()
+	input := p.scene
+	for i, e := range active {
+		var output *rendertarget.RenderTarget
+		if i < len(active)-1 {
+			if err := ensure(&p.buffers[i%2], width, height); err != nil {
+				return err
+			}
+			output = p.buffers[i%2]
+		}
+		if err := e.Apply(input, output); err != nil {
+			return fmt.Errorf("failed to apply %T: %v", e, err)
+		}
+		input = output
+	}
+	return nil
+}
+
+// Delete frees the pipeline's render targets, and its effects.
+func (p *Pipeline) Delete() {
+	p.scene.Delete()
+	for _, b := range p.buffers {
+		if b != nil {
+			b.Delete()
+		}
+	}
+	for _, e := range p.Effects {
+		if e != nil {
+			e.Delete()
+		}
+	}
+}
+
+// ensure makes sure a render target exists with the provided size. Effects don't need depth, so it has none.
+func ensure(target **rendertarget.RenderTarget, width, height int) error {
+	if *target == nil {
+		t, err := rendertarget.New(width, height, rendertarget.Options{NoDepth: true})
+		if err != nil {
+			return err
+		}
+		*target = t
+		return nil
+	}
+	return (*target).Resize(width, height)
+}
@@ -0,0 +1,63 @@
+package postprocess
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestGaussianWeights(t *testing.T) {
+	for radius := 0; radius <= MaxBlurRadius; radius++ {
+		weights := gaussianWeights(radius)
+		if len(weights) != radius+1 {
+			t.Fatalf("radius %d: got %d weights, want %d", radius, len(weights), radius+1)
+		}
+		sum := weights[0]
+		for i := 1; i < len(weights); i++ {
+			sum += 2 * weights[i]
+			if weights[i] > weights[i-1] {
+				t.Errorf("radius %d: got weights %v, want them to decrease away from the center", radius, weights)
+			}
+		}
+		if math.Abs(float64(sum-1)) > 1e-5 {
+			t.Errorf("radius %d: got weights that add up to %v, want 1", radius, sum)
+		}
+	}
+}
+
+func TestIdentityLUT(t *testing.T) {
+	const size = 4
+	img := IdentityLUT(size)
+	if got := img.Bounds(); got != image.Rect(0, 0, size*size, size) {
+		t.Fatalf("got bounds %v, want %v", got, image.Rect(0, 0, size*size, size))
+	}
+	tests := []struct {
+		x, y    int
+		r, g, b uint8
+	}{
+		{0, 0, 0, 0, 0},
+		{3, 0, 255, 0, 0},
+		{0, 3, 0, 255, 0},
+		{4, 0, 0, 0, 85},
+		{15, 3, 255, 255, 255},
+		{9, 2, 85, 170, 170},
+	}
+	for _, tt := range tests {
+		c := img.RGBAAt(tt.x, tt.y)
+		if c.R != tt.r || c.G != tt.g || c.B != tt.b || c.A != 255 {
+			t.Errorf("got %v at (%d,%d), want {%d %d %d 255}", c, tt.x, tt.y, tt.r, tt.g, tt.b)
+		}
+	}
+}
+
+func TestLoadLUTRejectsBadSizes(t *testing.T) {
+	for _, r := range []image.Rectangle{
+		image.Rect(0, 0, 16, 16),
+		image.Rect(0, 0, 1, 1),
+		image.Rect(0, 0, 60, 8),
+	} {
+		if _, _, err := LoadLUT(image.NewRGBA(r)); err == nil {
+			t.Errorf("got no error for a %v LUT", r.Size())
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package postprocess
+
+import (
+	"strconv"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/shader"
+	"github.com/omustardo/gome/util/glutil"
+	"github.com/omustardo/gome/view/rendertarget"
+)
+
+const (
+	vertexSource = `
+attribute vec2 aPosition;
+varying vec2 vTextureCoord;
+void main() {
+	vTextureCoord = aPosition * 0.5 + 0.5;
+	gl_Position = vec4(aPosition, 0.0, 1.0);
+}
+`
+	// fragmentHeader is added to the start of every effect's fragment shader.
+	fragmentHeader = `
+uniform sampler2D uSource;
+uniform vec2 uTexelSize;
+varying vec2 vTextureCoord;
+`
+	copySource = `
+void main() {
+	gl_FragColor = texture2D(uSource, vTextureCoord);
+}
+`
+)
+
+// Shader is a fragment shader that's run for every pixel of an effect's output.
+// The following are declared for it, and shouldn't be declared again:
+//   uniform sampler2D uSource; // The input.
+//   uniform vec2 uTexelSize;   // The size of a pixel of the input, in texture coordinates.
+//   varying vec2 vTextureCoord; // Where the pixel being drawn is, from (0,0) in the bottom left to (1,1).
+type Shader struct {
+	Program gl.Program
+
+	positionAttrib gl.Attrib
+	uniforms       map[string]gl.Uniform
+}
+
+var (
+	// defines are added to every effect's shader.
+	defines = map[string]string{"MAX_BLUR_RADIUS": strconv.Itoa(MaxBlurRadius)}
+	// quad is a single triangle that covers the whole screen, which is slightly faster than two that make a square.
+	quad gl.Buffer
+	// shaders holds the effects' shaders by their source, so each is compiled once.
+	shaders = make(map[string]*Shader)
+)
+
+// NewShader compiles a fragment shader for an effect. See Shader for what it can use. It's preprocessed, so it can
+// include chunks and use MAX_BLUR_RADIUS. The name identifies it in compile errors. See shader.CompileProgram.
+// It's up to the caller to delete the program using gl.DeleteProgram when it's no longer needed.
+func NewShader(name, fragmentSource string) (*Shader, error) {
+	program, err := shader.CompileProgram(name, vertexSource, fragmentHeader+fragmentSource, defines)
+	if err != nil {
+		return nil, err
+	}
+	if !quad.Valid() {
+		quad = glutil.LoadBufferVec2([]mgl32.Vec2{{-1, -1}, {3, -1}, {-1, 3}})
+	}
+	return &Shader{
+		Program:        program,
+		positionAttrib: gl.GetAttribLocation(program, "aPosition"),
+		uniforms:       make(map[string]gl.Uniform),
+	}, nil
+}
+
+// cached returns the shader for the source, compiling it the first time.
+func cached(name, fragmentSource string) (*Shader, error) {
+	if s, ok := shaders[fragmentSource]; ok {
+		return s, nil
+	}
+	s, err := NewShader(name, fragmentSource)
+	if err != nil {
+		return nil, err
+	}
+	shaders[fragmentSource] = s
+	return s, nil
+}
+
+// Uniform returns the location of a uniform in the shader. Uniforms can only be set while the program is in use, so
+// call shader.UseProgram(s.Program) first.
+func (s *Shader) Uniform(name string) gl.Uniform {
+	u, ok := s.uniforms[name]
+	if !ok {
+		u = gl.GetUniformLocation(s.Program, name)
+		s.uniforms[name] = u
+	}
+	return u
+}
+
+// Draw runs the shader over the whole output, reading from the source as uSource. If the output is nil, it draws into
+// whatever is currently being drawn into. Other textures are bound to the following texture units, so a sampler
+// that reads the first one should be set to 1 with gl.Uniform1i.
+func (s *Shader) Draw(source, output *rendertarget.RenderTarget, textures ...gl.Texture) {
+	shader.UseProgram(s.Program)
+	width, height := source.Size()
+	gl.Uniform1i(s.Uniform("uSource"), 0)
+	gl.Uniform2f(s.Uniform("uTexelSize"), 1/float32(width), 1/float32(height))
+	for i, texture := range append([]gl.Texture{source.Texture()}, textures...) {
+		gl.ActiveTexture(gl.Enum(gl.TEXTURE0 + i))
+		gl.BindTexture(gl.TEXTURE_2D, texture)
+	}
+
+	if output != nil {
+		output.Bind()
+		defer output.Unbind()
+	}
+	gl.BindBuffer(gl.ARRAY_BUFFER, quad)
+	gl.EnableVertexAttribArray(s.positionAttrib)
+	gl.VertexAttribPointer(s.positionAttrib, 2, gl.FLOAT, false, 0, 0)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	gl.DisableVertexAttribArray(s.positionAttrib)
+}
@@ -0,0 +1,252 @@
+// rendertarget draws into textures rather than the window. This is useful for minimaps, mirrors, security cameras,
+// thumbnails, and post processing.
+package rendertarget
+
+import (
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/util/glutil"
+)
+
+// Options changes what a RenderTarget contains. The zero value draws into a single texture with a depth buffer.
+type Options struct {
+	// Textures is how many color textures are drawn into at once. Zero means one. More than one needs a fragment
+	// shader that writes to gl_FragData, and is limited by glutil.MaxDrawBuffers.
+	Textures int
+	// NoDepth skips the depth buffer. Targets that only draw flat images, like post processing, don't need one.
+	NoDepth bool
+	// Nearest samples the textures without blending neighboring texels, for pixel art or reading exact values.
+	Nearest bool
+}
+
+// RenderTarget is a framebuffer that draws into textures. Everything drawn between Bind and Unbind goes into its
+// textures instead of the window. The textures can then be used like any other, for example on a mesh:
+//   minimap, err := rendertarget.New(256, 256, rendertarget.Options{})
+//   if err != nil {
+//     log.Fatal(err)
+//   }
+//   screen := &model.Model{Mesh: mesh.NewRect(nil, minimap.Texture()), ...}
+//   for { // game loop
+//     minimap.Bind()
+//     gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+//     shader.Model.SetMVPMatrix(overheadCamera.ProjectionOrthographic(256, 256), overheadCamera.ModelView())
+//     // Draw the world from above.
+//     minimap.Unbind()
+//     // Draw the world as usual, including the screen.
+//   }
+//
+// Texture coordinate (0,0) is the bottom left of what was drawn, so textures are upright on meshes like mesh.NewRect.
+// Textures don't have mipmaps, so sizes don't need to be powers of two.
+type RenderTarget struct {
+	width, height int
+	opts          Options
+
+	framebuffer gl.Framebuffer
+	textures    []gl.Texture
+	depth       gl.Renderbuffer
+}
+
+// binding is a RenderTarget that has been bound, along with the viewport to restore when it's unbound.
+type binding struct {
+	target   *RenderTarget
+	viewport [4]int32
+}
+
+// bound holds the targets that are currently bound, with the one being drawn into last. Targets can be bound while
+// others are, for example to draw a thumbnail while drawing a mirror, and unbinding goes back to the previous one.
+var bound []binding
+
+// New creates a RenderTarget with the provided size in pixels.
+// It's up to the caller to delete it using Delete when it's no longer needed.
+func New(width, height int, opts Options) (*RenderTarget, error) {
+	if opts.Textures <= 0 {
+		opts.Textures = 1
+	}
+	if opts.Textures > 1 {
+		if max := glutil.MaxDrawBuffers(); opts.Textures > max {
+			return nil, fmt.Errorf("render targets can draw into at most %d textures. got %d", max, opts.Textures)
+		}
+	}
+	t := &RenderTarget{opts: opts}
+	if err := t.Resize(width, height); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Resize changes the size of the textures, and clears them. This is usually needed when the window is resized,
+// for targets that cover the window. Textures are recreated, so ones from Texture and Textures must be fetched again.
+func (t *RenderTarget) Resize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("render target dimensions must be >0. got [%d,%d]", width, height)
+	}
+	if width == t.width && height == t.height && t.framebuffer.Valid() {
+		return nil
+	}
+	t.deleteBuffers()
+	t.width, t.height = width, height
+	if err := t.create(); err != nil {
+		t.deleteBuffers()
+		return err
+	}
+	if t.isBound() {
+		gl.Viewport(0, 0, t.width, t.height)
+	}
+	return nil
+}
+
+// create makes the framebuffer and its attachments at the current size.
+func (t *RenderTarget) create() error {
+	t.framebuffer = gl.CreateFramebuffer()
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffer)
+	defer bindCurrent()
+
+	filter := gl.LINEAR
+	if t.opts.Nearest {
+		filter = gl.NEAREST
+	}
+	for i := 0; i < t.opts.Textures; i++ {
+		texture := gl.CreateTexture()
+		gl.BindTexture(gl.TEXTURE_2D, texture)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, t.width, t.height, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		// WebGL only allows textures with sizes that aren't powers of two if they have no mipmaps and clamped edges.
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, filter)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, filter)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.Enum(gl.COLOR_ATTACHMENT0+i), gl.TEXTURE_2D, texture, 0)
+		t.textures = append(t.textures, texture)
+	}
+	gl.BindTexture(gl.TEXTURE_2D, gl.Texture{})
+	if t.opts.Textures > 1 {
+		// Which attachments are drawn into is part of the framebuffer's state, so it only needs to be set once.
+		glutil.DrawBuffers(t.opts.Textures)
+	}
+
+	if !t.opts.NoDepth {
+		t.depth = gl.CreateRenderbuffer()
+		gl.BindRenderbuffer(gl.RENDERBUFFER, t.depth)
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH_COMPONENT16, t.width, t.height)
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_ATTACHMENT, gl.RENDERBUFFER, t.depth)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, gl.Renderbuffer{})
+	}
+
+	if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+		return fmt.Errorf("render target framebuffer is incomplete: status 0x%x", int(status))
+	}
+	return nil
+}
+
+// Bind makes everything drawn go into the target's textures, until Unbind is called. The viewport is set to cover the
+// whole target. Nothing is cleared, so call gl.Clear if the previous contents aren't wanted.
+func (t *RenderTarget) Bind() {
+	b := binding{target: t}
+	gl.GetIntegerv(gl.VIEWPORT, b.viewport[:])
+	bound = append(bound, b)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffer)
+	gl.Viewport(0, 0, t.width, t.height)
+}
+
+// Unbind goes back to drawing into whatever was being drawn into before Bind was called, and restores the viewport.
+func (t *RenderTarget) Unbind() {
+	if len(bound) == 0 || bound[len(bound)-1].target != t {
+		log.Println("Attempted to unbind a render target that isn't the most recently bound one")
+		return
+	}
+	b := bound[len(bound)-1]
+	bound = bound[:len(bound)-1]
+	bindCurrent()
+	gl.Viewport(int(b.viewport[0]), int(b.viewport[1]), int(b.viewport[2]), int(b.viewport[3]))
+}
+
+// isBound returns whether the target is the one being drawn into.
+func (t *RenderTarget) isBound() bool {
+	return len(bound) > 0 && bound[len(bound)-1].target == t
+}
+
+// bindCurrent binds the framebuffer of the most recently bound target, or the window if there isn't one.
+func bindCurrent() {
+	gl.BindFramebuffer(gl.FRAMEBUFFER, Current())
+}
+
+// Current returns the framebuffer that's being drawn into: the most recently bound target's, or the window's if no
+// target is bound. Code that binds its own framebuffer outside of a RenderTarget should bind this again afterward.
+func Current() gl.Framebuffer {
+	if len(bound) == 0 {
+		return gl.Framebuffer{}
+	}
+	return bound[len(bound)-1].target.framebuffer
+}
+
+// Size returns the width and height of the target in pixels.
+func (t *RenderTarget) Size() (width, height int) {
+	return t.width, t.height
+}
+
+// Texture returns the first texture that's drawn into. It can't be drawn with while the target is bound.
+func (t *RenderTarget) Texture() gl.Texture {
+	if len(t.textures) == 0 {
+		return gl.Texture{}
+	}
+	return t.textures[0]
+}
+
+// Textures returns every texture that's drawn into, in the order of gl_FragData.
+func (t *RenderTarget) Textures() []gl.Texture {
+	return t.textures
+}
+
+// Image reads the first texture back from the GPU. This is slow, since it waits for drawing to finish, so it's meant
+// for things like tests and saving thumbnails rather than every frame.
+func (t *RenderTarget) Image() (*image.RGBA, error) {
+	if !t.framebuffer.Valid() {
+		return nil, fmt.Errorf("render target has been deleted")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, t.width, t.height))
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.framebuffer)
+	gl.ReadPixels(img.Pix, 0, 0, t.width, t.height, gl.RGBA, gl.UNSIGNED_BYTE)
+	bindCurrent()
+	flipRows(img)
+	return img, nil
+}
+
+// flipRows flips an image vertically. OpenGL reads pixels starting from the bottom row, while images start at the top.
+func flipRows(img *image.RGBA) {
+	height := img.Rect.Dy()
+	rowLength := img.Rect.Dx() * 4
+	temp := make([]uint8, rowLength)
+	for top, bottom := 0, height-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := img.Pix[top*img.Stride : top*img.Stride+rowLength]
+		b := img.Pix[bottom*img.Stride : bottom*img.Stride+rowLength]
+		copy(temp, a)
+		copy(a, b)
+		copy(b, temp)
+	}
+}
+
+// Delete frees the target's framebuffer and textures on the GPU. It can't be used afterward.
+func (t *RenderTarget) Delete() {
+	if t.isBound() {
+		t.Unbind()
+	}
+	t.deleteBuffers()
+}
+
+// deleteBuffers frees the framebuffer and its attachments, if they exist.
+func (t *RenderTarget) deleteBuffers() {
+	if t.framebuffer.Valid() {
+		gl.DeleteFramebuffer(t.framebuffer)
+	}
+	for _, texture := range t.textures {
+		gl.DeleteTexture(texture)
+	}
+	if t.depth.Valid() {
+		gl.DeleteRenderbuffer(t.depth)
+	}
+	t.framebuffer = gl.Framebuffer{}
+	t.textures = nil
+	t.depth = gl.Renderbuffer{}
+}
@@ -0,0 +1,27 @@
+package rendertarget
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFlipRows(t *testing.T) {
+	for _, height := range []int{1, 2, 3, 4} {
+		img := image.NewRGBA(image.Rect(0, 0, 2, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < 2; x++ {
+				img.Set(x, y, color.RGBA{uint8(y), uint8(x), 0, 255})
+			}
+		}
+		flipRows(img)
+		for y := 0; y < height; y++ {
+			for x := 0; x < 2; x++ {
+				want := color.RGBA{uint8(height - 1 - y), uint8(x), 0, 255}
+				if got := img.RGBAAt(x, y); got != want {
+					t.Errorf("height %d: got %v at (%d,%d), want %v", height, got, x, y, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package viewport
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/camera"
+)
+
+// Layout is a list of viewports, rendered in order. Later viewports are drawn on top of earlier ones, so overlays
+// like minimaps should come last.
+type Layout []*Viewport
+
+// SplitScreen creates a layout that divides the window evenly between the cameras, with one viewport per camera.
+// Two cameras are placed side by side, and more are arranged in a grid that fills rows from left to right.
+// The viewports are named "player1", "player2", and so on.
+func SplitScreen(cameras ...camera.CameraI) Layout {
+	if len(cameras) == 0 {
+		return nil
+	}
+	cols := int(math.Ceil(math.Sqrt(float64(len(cameras)))))
+	rows := (len(cameras) + cols - 1) / cols
+	width, height := 1/float32(cols), 1/float32(rows)
+
+	layout := make(Layout, 0, len(cameras))
+	for i, cam := range cameras {
+		bounds := Rect{
+			X:      float32(i%cols) * width,
+			Y:      float32(i/cols) * height,
+			Width:  width,
+			Height: height,
+		}
+		layout = append(layout, New(fmt.Sprintf("player%d", i+1), cam, bounds, Normalized))
+	}
+	return layout
+}
+
+// Get returns the viewport with the provided name, or nil if there isn't one.
+func (l Layout) Get(name string) *Viewport {
+	for _, v := range l {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// At returns the visible viewport that is on top at a position in window coordinates, like the mouse position.
+// It returns nil if the position isn't in any viewport.
+func (l Layout) At(pos mgl32.Vec2, window camera.WindowSize) *Viewport {
+	for i := len(l) - 1; i >= 0; i-- {
+		if !l[i].Hidden && l[i].Contains(pos, window) {
+			return l[i]
+		}
+	}
+	return nil
+}
+
+// Render draws each visible viewport in order. Before each call to draw, rendering is limited to the viewport's area,
+// the area is cleared, and the matrices for the viewport's camera are provided. The draw function should pass them
+// to the shaders before rendering.
+// Afterward, the viewport and scissor test are restored to what they were before, so anything drawn next covers the
+// same area as it would have without the layout.
+//
+// For example:
+//   layout.Render(camera.CurrentWindowSize(), func(v *viewport.Viewport, pMatrix, mvMatrix mgl32.Mat4) {
+//     shader.Model.SetMVPMatrix(pMatrix, mvMatrix)
+//     world.Render()
+//   })
+func (l Layout) Render(window camera.WindowSize, draw func(v *Viewport, pMatrix, mvMatrix mgl32.Mat4)) {
+	// Keep the state that's changed, so it can be restored. It may be a render target's rather than the window's.
+	var viewport, scissorBox [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, viewport[:])
+	gl.GetIntegerv(gl.SCISSOR_BOX, scissorBox[:])
+	scissor := gl.IsEnabled(gl.SCISSOR_TEST)
+	clearColor := make([]float32, 4)
+	gl.GetFloatv(clearColor, gl.COLOR_CLEAR_VALUE)
+
+	for _, v := range l {
+		if v.Hidden || v.Camera == nil {
+			continue
+		}
+		v.begin(window)
+		draw(v, v.ProjectionMatrix(window), v.Camera.ModelView())
+	}
+
+	if scissor {
+		gl.Enable(gl.SCISSOR_TEST)
+	} else {
+		gl.Disable(gl.SCISSOR_TEST)
+	}
+	gl.Scissor(scissorBox[0], scissorBox[1], scissorBox[2], scissorBox[3])
+	gl.Viewport(int(viewport[0]), int(viewport[1]), int(viewport[2]), int(viewport[3]))
+	gl.ClearColor(clearColor[0], clearColor[1], clearColor[2], clearColor[3])
+}
@@ -0,0 +1,151 @@
+// viewport divides the window into areas that are each rendered from the point of view of a different camera.
+// This is useful for split screen multiplayer, and for picture in picture views like minimaps.
+package viewport
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/goxjs/gl"
+	"github.com/omustardo/gome/camera"
+)
+
+// Units determines how a Viewport's Bounds are measured.
+type Units int
+
+const (
+	// Normalized bounds are fractions of the window size, from 0 to 1. They keep the same proportions of the window
+	// when it's resized.
+	Normalized Units = iota
+	// Pixels bounds are in window coordinates, the same as mouse positions. They keep the same size when the window is
+	// resized. On HiDPI displays, one unit may cover multiple actual pixels.
+	Pixels
+)
+
+// Rect is an axis aligned rectangle. X and Y are its top left corner, with Y increasing downward to match mouse positions.
+type Rect struct {
+	X, Y, Width, Height float32
+}
+
+// Contains returns whether the point is within the rectangle.
+func (r Rect) Contains(p mgl32.Vec2) bool {
+	return p.X() >= r.X && p.X() < r.X+r.Width && p.Y() >= r.Y && p.Y() < r.Y+r.Height
+}
+
+// Viewport is an area of the window that is rendered from the point of view of a single camera.
+// Create one using New unless you know what you're doing.
+type Viewport struct {
+	// Name identifies the viewport within a Layout.
+	Name string
+	// Camera determines what is visible in the viewport.
+	Camera camera.CameraI
+	// Projection is which of the camera's projection matrices to use.
+	Projection camera.ProjectionType
+
+	// Bounds is the area of the window that the viewport covers, measured in Units.
+	Bounds Rect
+	Units  Units
+
+	// ClearColor is the background color of the viewport.
+	ClearColor mgl32.Vec4
+	// Transparent skips clearing the background color, so the viewport is drawn on top of whatever is already in its
+	// area. The depth buffer is still cleared so nothing from earlier viewports hides what's drawn in this one.
+	Transparent bool
+	// Hidden viewports aren't rendered.
+	Hidden bool
+}
+
+// New creates a viewport with an opaque black background that renders with a perspective projection.
+func New(name string, cam camera.CameraI, bounds Rect, units Units) *Viewport {
+	return &Viewport{
+		Name:       name,
+		Camera:     cam,
+		Projection: camera.Perspective,
+		Bounds:     bounds,
+		Units:      units,
+		ClearColor: mgl32.Vec4{0, 0, 0, 1},
+	}
+}
+
+// Area returns the part of the window that the viewport covers, in window coordinates.
+func (v *Viewport) Area(window camera.WindowSize) Rect {
+	if v.Units == Pixels {
+		return v.Bounds
+	}
+	return Rect{
+		X:      v.Bounds.X * window.Width,
+		Y:      v.Bounds.Y * window.Height,
+		Width:  v.Bounds.Width * window.Width,
+		Height: v.Bounds.Height * window.Height,
+	}
+}
+
+// Size returns the size of the viewport, with a framebuffer size that matches the window's pixel density.
+// Use it in place of the window size when calling camera.Project and camera.Unproject for this viewport,
+// along with positions converted using ToLocal.
+func (v *Viewport) Size(window camera.WindowSize) camera.WindowSize {
+	area := v.Area(window)
+	size := camera.WindowSize{Width: area.Width, Height: area.Height}
+	if window.Width > 0 && window.Height > 0 {
+		size.FramebufferWidth = area.Width * window.FramebufferWidth / window.Width
+		size.FramebufferHeight = area.Height * window.FramebufferHeight / window.Height
+	}
+	return size
+}
+
+// Aspect returns the ratio of the viewport's width to its height.
+func (v *Viewport) Aspect(window camera.WindowSize) float32 {
+	area := v.Area(window)
+	if area.Height == 0 {
+		return 1
+	}
+	return area.Width / area.Height
+}
+
+// ProjectionMatrix returns the camera's projection matrix, sized to fit the viewport rather than the whole window.
+func (v *Viewport) ProjectionMatrix(window camera.WindowSize) mgl32.Mat4 {
+	area := v.Area(window)
+	return camera.Projection(v.Camera, v.Projection, area.Width, area.Height)
+}
+
+// Contains returns whether a position in window coordinates, like the mouse position, is within the viewport.
+func (v *Viewport) Contains(pos mgl32.Vec2, window camera.WindowSize) bool {
+	return v.Area(window).Contains(pos)
+}
+
+// ToLocal converts a position in window coordinates into coordinates relative to the top left of the viewport.
+// For example, to find where the mouse is pointing in the world:
+//   local := v.ToLocal(mouse.Handler.Position(), window)
+//   ground, ok := camera.UnprojectToPlane(v.Camera, v.Projection, local, plane, v.Size(window))
+func (v *Viewport) ToLocal(pos mgl32.Vec2, window camera.WindowSize) mgl32.Vec2 {
+	area := v.Area(window)
+	return mgl32.Vec2{pos.X() - area.X, pos.Y() - area.Y}
+}
+
+// framebufferArea returns the viewport's area in framebuffer pixels, with (0,0) at the bottom left as gl.Viewport
+// and gl.Scissor expect. Edges are rounded so that viewports which share an edge don't overlap or leave a gap.
+func (v *Viewport) framebufferArea(window camera.WindowSize) (x, y, width, height int) {
+	area := v.Area(window)
+	// The top of the area is the larger framebuffer Y, since framebuffer Y increases upward.
+	bottomLeft := window.ToFramebuffer(mgl32.Vec2{area.X, area.Y + area.Height})
+	topRight := window.ToFramebuffer(mgl32.Vec2{area.X + area.Width, area.Y})
+	round := func(f float32) int { return int(math.Floor(float64(f) + 0.5)) }
+	x, y = round(bottomLeft.X()), round(bottomLeft.Y())
+	return x, y, round(topRight.X()) - x, round(topRight.Y()) - y
+}
+
+// begin limits rendering to the viewport's area and clears it.
+func (v *Viewport) begin(window camera.WindowSize) {
+	x, y, width, height := v.framebufferArea(window)
+	gl.Viewport(x, y, width, height)
+	// gl.Viewport only maps coordinates. Without a scissor, gl.Clear would clear the entire window.
+	gl.Enable(gl.SCISSOR_TEST)
+	gl.Scissor(int32(x), int32(y), int32(width), int32(height))
+
+	mask := gl.Enum(gl.DEPTH_BUFFER_BIT)
+	if !v.Transparent {
+		gl.ClearColor(v.ClearColor[0], v.ClearColor[1], v.ClearColor[2], v.ClearColor[3])
+		mask |= gl.COLOR_BUFFER_BIT
+	}
+	gl.Clear(mask)
+}
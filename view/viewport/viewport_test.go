@@ -0,0 +1,134 @@
+package viewport
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/omustardo/gome/camera"
+)
+
+// hiDPI is a window with two framebuffer pixels per screen coordinate.
+var hiDPI = camera.WindowSize{Width: 800, Height: 600, FramebufferWidth: 1600, FramebufferHeight: 1200}
+
+func TestArea(t *testing.T) {
+	tests := []struct {
+		v    *Viewport
+		want Rect
+	}{
+		{New("", nil, Rect{0, 0, 1, 1}, Normalized), Rect{0, 0, 800, 600}},
+		{New("", nil, Rect{0.5, 0.25, 0.5, 0.5}, Normalized), Rect{400, 150, 400, 300}},
+		{New("", nil, Rect{10, 20, 200, 100}, Pixels), Rect{10, 20, 200, 100}},
+	}
+	for _, tt := range tests {
+		if got := tt.v.Area(hiDPI); got != tt.want {
+			t.Errorf("%v in %v: got area %v, want %v", tt.v.Bounds, tt.v.Units, got, tt.want)
+		}
+	}
+}
+
+func TestFramebufferArea(t *testing.T) {
+	// The top right quarter of the window. Framebuffer coordinates start at the bottom left, and are doubled.
+	v := New("", nil, Rect{0.5, 0, 0.5, 0.5}, Normalized)
+	x, y, w, h := v.framebufferArea(hiDPI)
+	if x != 800 || y != 600 || w != 800 || h != 600 {
+		t.Errorf("got framebuffer area (%d, %d, %d, %d), want (800, 600, 800, 600)", x, y, w, h)
+	}
+
+	// Thirds don't divide the window evenly, but neighboring viewports still share an edge.
+	window := camera.WindowSize{Width: 100, Height: 100, FramebufferWidth: 100, FramebufferHeight: 100}
+	left := New("", nil, Rect{0, 0, 1.0 / 3, 1}, Normalized)
+	right := New("", nil, Rect{1.0 / 3, 0, 2.0 / 3, 1}, Normalized)
+	lx, _, lw, _ := left.framebufferArea(window)
+	rx, _, rw, _ := right.framebufferArea(window)
+	if lx+lw != rx || rx+rw != 100 {
+		t.Errorf("got left [%d, %d) and right [%d, %d), want them to exactly cover [0, 100)", lx, lx+lw, rx, rx+rw)
+	}
+}
+
+func TestSizeAndAspect(t *testing.T) {
+	v := New("", nil, Rect{0, 0, 0.5, 1}, Normalized)
+	want := camera.WindowSize{Width: 400, Height: 600, FramebufferWidth: 800, FramebufferHeight: 1200}
+	if got := v.Size(hiDPI); got != want {
+		t.Errorf("got size %v, want %v", got, want)
+	}
+	if got, want := v.Aspect(hiDPI), float32(400.0/600); got != want {
+		t.Errorf("got aspect %v, want %v", got, want)
+	}
+}
+
+func TestProjectionUsesViewportAspect(t *testing.T) {
+	// A point at the right edge of a camera's view should be at the right edge of its viewport, even though the
+	// viewport is narrower than the window.
+	cam := camera.NewCamera()
+	cam.Position = mgl32.Vec3{0, 0, 100}
+	v := New("", cam, Rect{0, 0, 0.5, 1}, Normalized)
+	mvp := v.ProjectionMatrix(hiDPI).Mul4(cam.ModelView())
+
+	halfHeight := 100 * float32(0.41421356) // tan(FOV/2) at a distance of 100.
+	edge := mgl32.Vec3{halfHeight * v.Aspect(hiDPI), 0, 0}
+	clip := mvp.Mul4x1(edge.Vec4(1))
+	if ndcX := clip.X() / clip.W(); ndcX < 0.999 || ndcX > 1.001 {
+		t.Errorf("got normalized x=%v for the edge of the view, want 1", ndcX)
+	}
+}
+
+func TestToLocal(t *testing.T) {
+	v := New("", nil, Rect{0.5, 0.5, 0.5, 0.5}, Normalized)
+	if got, want := v.ToLocal(mgl32.Vec2{500, 400}, hiDPI), (mgl32.Vec2{100, 100}); got != want {
+		t.Errorf("got local position %v, want %v", got, want)
+	}
+	if v.Contains(mgl32.Vec2{100, 100}, hiDPI) {
+		t.Errorf("viewport in the bottom right contains a point in the top left")
+	}
+}
+
+func TestSplitScreen(t *testing.T) {
+	cams := []camera.CameraI{camera.NewCamera(), camera.NewCamera(), camera.NewCamera()}
+
+	two := SplitScreen(cams[:2]...)
+	if got, want := two[0].Bounds, (Rect{0, 0, 0.5, 1}); got != want {
+		t.Errorf("got first of two at %v, want %v", got, want)
+	}
+	if got, want := two[1].Bounds, (Rect{0.5, 0, 0.5, 1}); got != want {
+		t.Errorf("got second of two at %v, want %v", got, want)
+	}
+
+	three := SplitScreen(cams...)
+	if got, want := three.Get("player3").Bounds, (Rect{0, 0.5, 0.5, 0.5}); got != want {
+		t.Errorf("got third of three at %v, want %v", got, want)
+	}
+	if three.Get("player3").Camera != cams[2] {
+		t.Errorf("player3 doesn't use the third camera")
+	}
+	if three.Get("player4") != nil {
+		t.Errorf("got a viewport for a fourth player that doesn't exist")
+	}
+}
+
+func TestAt(t *testing.T) {
+	layout := SplitScreen(camera.NewCamera(), camera.NewCamera())
+	minimap := New("minimap", camera.NewCamera(), Rect{300, 0, 200, 200}, Pixels)
+	layout = append(layout, minimap)
+
+	tests := []struct {
+		pos  mgl32.Vec2
+		want *Viewport
+	}{
+		{mgl32.Vec2{100, 300}, layout.Get("player1")},
+		{mgl32.Vec2{700, 300}, layout.Get("player2")},
+		// The minimap is drawn last, so it's on top of both players.
+		{mgl32.Vec2{350, 100}, minimap},
+		{mgl32.Vec2{450, 100}, minimap},
+		{mgl32.Vec2{900, 100}, nil},
+	}
+	for _, tt := range tests {
+		if got := layout.At(tt.pos, hiDPI); got != tt.want {
+			t.Errorf("At(%v): got %v, want %v", tt.pos, got, tt.want)
+		}
+	}
+
+	minimap.Hidden = true
+	if got := layout.At(mgl32.Vec2{350, 100}, hiDPI); got != layout.Get("player1") {
+		t.Errorf("got %v under a hidden minimap, want player1", got)
+	}
+}